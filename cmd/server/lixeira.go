@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"api-filmes/internal/config"
+	"api-filmes/internal/database"
+)
+
+// expurgarLixeiraPeriodicamente apaga, a cada intervalo, os filmes que estão
+// na lixeira há mais tempo que a retenção. Roda até ctx ser cancelado; várias
+// instâncias podem executá-lo ao mesmo tempo, pois o DELETE é idempotente.
+func expurgarLixeiraPeriodicamente(ctx context.Context, repositorio database.FilmeRepositorio, cfg *config.ConfiguracaoLixeira) {
+	if cfg.Retencao == 0 {
+		slog.Info("expurgo automático da lixeira desativado")
+		return
+	}
+
+	slog.Info("expurgo automático da lixeira ativo",
+		"retencao", cfg.Retencao.String(), "intervalo", cfg.IntervaloExpurgo.String())
+
+	ticker := time.NewTicker(cfg.IntervaloExpurgo)
+	defer ticker.Stop()
+
+	for {
+		expurgarLixeira(ctx, repositorio, cfg.Retencao)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// expurgarLixeira executa uma rodada do expurgo
+func expurgarLixeira(ctx context.Context, repositorio database.FilmeRepositorio, retencao time.Duration) {
+	ctx, cancelar := context.WithTimeout(ctx, time.Minute)
+	defer cancelar()
+
+	removidos, err := repositorio.ExpurgarLixeira(ctx, time.Now().Add(-retencao))
+	if err != nil {
+		if ctx.Err() == nil {
+			slog.Warn("erro no expurgo da lixeira", "erro", err)
+		}
+		return
+	}
+	if removidos > 0 {
+		slog.Info("lixeira expurgada", "removidos", removidos)
+	}
+}
@@ -1,87 +1,408 @@
 package main
 
 import (
+	"cmp"
+	"context"
+	"crypto/rand"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
+	"net"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
+	"api-filmes/internal/autenticacao"
+	"api-filmes/internal/config"
 	"api-filmes/internal/database"
 	"api-filmes/internal/handlers"
+	"api-filmes/internal/logs"
+	"api-filmes/internal/metricas"
+	"api-filmes/internal/migracoes"
+	"api-filmes/internal/rastreamento"
+	"api-filmes/internal/roteador"
+	"api-filmes/internal/versao"
 )
 
 func main() {
-	fmt.Println("🎬 Servidor da API de Filmes iniciando...")
+	configLog, err := config.ObterConfiguracaoLog()
+	if err != nil {
+		slog.Error("erro de configuração", "erro", err)
+		os.Exit(1)
+	}
+	logs.Configurar(configLog, os.Stdout)
+
+	// Subcomando de migrações: main migrate up|down|status|create
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := executarMigrate(os.Args[2:]); err != nil {
+			slog.Error("falha na migração", "erro", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if err := executarServidor(); err != nil {
+		slog.Error("falha no servidor", "erro", err)
+		os.Exit(1)
+	}
+}
+
+// executarServidor sobe a API e bloqueia até receber SIGINT/SIGTERM.
+// No desligamento, para de aceitar conexões, aguarda as requisições em
+// andamento (até o timeout configurado) e só então fecha o banco.
+func executarServidor() error {
+	slog.Info("servidor da API de Filmes iniciando", "versao", versao.Versao, "commit", versao.Commit)
+
+	configServidor, err := config.ObterConfiguracaoServidor()
+	if err != nil {
+		return fmt.Errorf("erro de configuração: %w", err)
+	}
+
+	timeouts, err := config.ObterConfiguracaoTimeouts()
+	if err != nil {
+		return fmt.Errorf("erro de configuração: %w", err)
+	}
 
-	// Conectar ao banco
-	bancoDados, err := database.NovaConexao()
+	versionamento, err := config.ObterConfiguracaoVersionamento()
 	if err != nil {
-		log.Fatal("❌ Erro ao conectar com banco:", err)
+		return fmt.Errorf("erro de configuração: %w", err)
+	}
+
+	configLixeira, err := config.ObterConfiguracaoLixeira()
+	if err != nil {
+		return fmt.Errorf("erro de configuração: %w", err)
+	}
+
+	configAutenticacao, err := config.ObterConfiguracaoAutenticacao()
+	if err != nil {
+		return fmt.Errorf("erro de configuração: %w", err)
+	}
+
+	configAplicacao := config.ObterConfiguracaoAplicacao()
+
+	// Rastreamento distribuído (spans exportados para stdout ou coletor OTLP)
+	rastreador, err := criarRastreador()
+	if err != nil {
+		return fmt.Errorf("erro de configuração: %w", err)
+	}
+	rastreamento.DefinirPadrao(rastreador)
+	defer func() {
+		ctx, cancelar := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancelar()
+		if err := rastreador.Encerrar(ctx); err != nil {
+			slog.Warn("erro ao exportar spans pendentes", "erro", err)
+		}
+	}()
+
+	// Escolher a implementação do repositório
+	repositorio, err := criarRepositorio(configAplicacao)
+	if err != nil {
+		return fmt.Errorf("erro ao conectar com banco: %w", err)
 	}
 
 	// Garantir que a conexão seja fechada ao final
 	defer func() {
-		if err := bancoDados.Fechar(); err != nil {
-			log.Printf("⚠️ Erro ao fechar conexão: %v", err)
+		if err := repositorio.Fechar(); err != nil {
+			slog.Warn("erro ao fechar conexão", "erro", err)
 		} else {
-			fmt.Println("🔌 Conexão com banco fechada")
+			slog.Info("conexão com banco fechada")
+		}
+	}()
+
+	// Estatísticas do pool de conexões em /metrics
+	if verificavel, ok := repositorio.(database.Verificavel); ok {
+		database.RegistrarMetricasPool(verificavel)
+	}
+
+	// Criar handler de filmes com medição de tempo por operação
+	repositorioInstrumentado := database.Instrumentar(repositorio)
+	filmeHandler := handlers.NovoFilmeHandler(repositorioInstrumentado, timeouts)
+	pessoaHandler := handlers.NovoPessoaHandler(repositorioInstrumentado, timeouts)
+
+	autenticacao.DefinirHashesSimultaneos(configAutenticacao.HashesSimultaneos)
+
+	// Chaves dos tokens de acesso; com JWKS, o arquivo é relido periodicamente
+	chaveiro, err := criarChaveiro(configAutenticacao)
+	if err != nil {
+		return fmt.Errorf("erro ao carregar chaves JWT: %w", err)
+	}
+	tokens := autenticacao.NovoGerenciadorTokens(chaveiro, configAutenticacao.Emissor,
+		configAutenticacao.Audiencia, configAutenticacao.DuracaoAcesso)
+	autenticacaoHandler := handlers.NovoAutenticacaoHandler(repositorioInstrumentado, timeouts, configAutenticacao, tokens)
+
+	// Expurgo dos filmes que passaram do prazo de retenção na lixeira e das
+	// tentativas de login vencidas
+	ctxTarefas, pararTarefas := context.WithCancel(context.Background())
+	defer pararTarefas()
+	go expurgarLixeiraPeriodicamente(ctxTarefas, repositorioInstrumentado, configLixeira)
+	go expurgarTentativasLoginPeriodicamente(ctxTarefas, repositorioInstrumentado, configAutenticacao.DuracaoBloqueio)
+	go chaveiro.Monitorar(ctxTarefas, configAutenticacao.IntervaloJWKS)
+
+	// Configurar rotas: 404/405 em JSON, com cabeçalho Allow
+	rotas := roteador.Novo()
+	rotas.NaoEncontrado = http.HandlerFunc(handlers.RotaNaoEncontrada)
+	rotas.MetodoNaoPermitido = http.HandlerFunc(handlers.MetodoNaoPermitido)
+
+	rotas.Get("/", paginaInicial)
+
+	// Versão estável em /v1; as rotas sem prefixo continuam como aliases
+	// depreciados até a data de sunset. Escritas exigem token de acesso.
+	filmeHandler.RegistrarRotas(rotas.Grupo(handlers.VersaoAtual))
+	filmeHandler.RegistrarRotasEscrita(rotas.Grupo(handlers.VersaoAtual, handlers.ExigirUsuarioMiddleware))
+	pessoaHandler.RegistrarRotas(rotas.Grupo(handlers.VersaoAtual))
+	pessoaHandler.RegistrarRotasEscrita(rotas.Grupo(handlers.VersaoAtual, handlers.ExigirUsuarioMiddleware))
+	autenticacaoHandler.RegistrarRotas(rotas.Grupo(handlers.VersaoAtual))
+	filmeHandler.RegistrarRotasAdmin(rotas.Grupo(handlers.VersaoAtual, handlers.AdminMiddleware(configAplicacao.TokenAdmin)))
+
+	depreciacao := handlers.DepreciacaoMiddleware(
+		versionamento.LegadoDepreciadoEm, versionamento.LegadoSunset, handlers.VersaoAtual)
+	filmeHandler.RegistrarRotas(rotas.Grupo("", depreciacao))
+	filmeHandler.RegistrarRotasEscrita(rotas.Grupo("", depreciacao, handlers.ExigirUsuarioMiddleware))
+
+	// Sondas de saúde: /health/live (processo) e /health/ready (dependências)
+	saudeHandler := handlers.NovoSaudeHandler(repositorio, criarVerificadorMigracoes(repositorio), timeouts.Timeout("saude"))
+	rotas.Get("/health", saudeHandler.Pronto)
+	rotas.Get("/health/live", saudeHandler.Vivo)
+	rotas.Get("/health/ready", saudeHandler.Pronto)
+
+	// Métricas no formato de texto do Prometheus
+	rotas.Get("/metrics", metricas.Padrao.Handler())
+
+	// Middlewares, de fora para dentro: ID da requisição, span, métricas,
+	// log e autenticação
+	var handler http.Handler = handlers.LogMiddleware(handlers.AutenticacaoMiddleware(tokens)(rotas).ServeHTTP)
+	handler = handlers.MetricasMiddleware(handler)
+	handler = handlers.RastreamentoMiddleware(handler)
+	handler = handlers.IDRequisicaoMiddleware(handler)
+
+	servidor := &http.Server{
+		Addr:              configServidor.EnderecoCompleto(),
+		Handler:           handler,
+		ErrorLog:          slog.NewLogLogger(slog.Default().Handler(), slog.LevelWarn),
+		ReadTimeout:       configServidor.TimeoutLeitura,
+		ReadHeaderTimeout: configServidor.TimeoutLeituraCabecalho,
+		WriteTimeout:      configServidor.TimeoutEscrita,
+		IdleTimeout:       configServidor.TimeoutOcioso,
+		MaxHeaderBytes:    configServidor.MaxBytesCabecalho,
+	}
+
+	// Iniciar servidor em segundo plano
+	erroServidor := make(chan error, 1)
+	go func() {
+		if err := servidor.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			erroServidor <- err
 		}
+		close(erroServidor)
 	}()
 
-	// Criar handler de filmes
-	filmeHandler := handlers.NovoFilmeHandler(bancoDados)
+	host := configServidor.Endereco
+	if host == "" {
+		host = "localhost"
+	}
+	// Endpoints disponíveis estão listados na página inicial (GET /)
+	slog.Info("servidor rodando", "url", "http://"+net.JoinHostPort(host, configServidor.Porta))
+
+	// Aguardar sinal de término ou falha ao escutar na porta
+	sinais, pararSinais := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer pararSinais()
+
+	select {
+	case err := <-erroServidor:
+		return fmt.Errorf("erro ao iniciar servidor: %w", err)
+	case <-sinais.Done():
+	}
 
-	// Configurar rotas com middleware de log
-	http.HandleFunc("/", handlers.LogMiddleware(paginaInicial))
-	http.HandleFunc("/filmes", handlers.LogMiddleware(filmeHandler.ManipularFilmes))
-	http.HandleFunc("/filmes/", handlers.LogMiddleware(filmeHandler.ManipularFilmeIndividual))
+	slog.Info("sinal recebido, aguardando requisições em andamento",
+		"timeout", configServidor.TimeoutDesligamento.String())
+
+	ctx, cancelar := context.WithTimeout(context.Background(), configServidor.TimeoutDesligamento)
+	defer cancelar()
+
+	if err := servidor.Shutdown(ctx); err != nil {
+		return fmt.Errorf("erro ao desligar servidor: %w", err)
+	}
+
+	slog.Info("servidor encerrado")
+	return nil
+}
+
+// criarRepositorio instancia o repositório definido em REPOSITORIO
+func criarRepositorio(cfg *config.ConfiguracaoAplicacao) (database.Repositorio, error) {
+	switch cfg.Repositorio {
+	case config.RepositorioMemoria:
+		slog.Info("usando repositório em memória (sem banco de dados)")
+		repositorio := database.NovoRepositorioMemoria()
+		if err := repositorio.CarregarExemplos(context.Background()); err != nil {
+			return nil, err
+		}
+		return repositorio, nil
+	case config.RepositorioPostgres:
+		bancoDados, err := database.NovaConexao()
+		if err != nil {
+			return nil, err
+		}
+		if cfg.MigrarAoIniciar {
+			if err := migrarAoIniciar(bancoDados); err != nil {
+				bancoDados.Fechar()
+				return nil, err
+			}
+		}
+		return bancoDados, nil
+	default:
+		return nil, fmt.Errorf("repositório desconhecido: %q (use %q ou %q)",
+			cfg.Repositorio, config.RepositorioPostgres, config.RepositorioMemoria)
+	}
+}
 
-	// Adicionar rota para health check
-	http.HandleFunc("/health", handlers.LogMiddleware(healthCheck))
+// criarChaveiro monta as chaves dos tokens de acesso a partir do arquivo JWKS
+// ou de JWT_SEGREDO. Sem nenhum dos dois, só sobe com JWT_SEGREDO_TEMPORARIO,
+// que gera um segredo aleatório: os tokens deixam de valer a cada reinício e
+// não são aceitos por outras instâncias.
+func criarChaveiro(cfg *config.ConfiguracaoAutenticacao) (*autenticacao.Chaveiro, error) {
+	if cfg.ArquivoJWKS != "" {
+		chaveiro, err := autenticacao.NovoChaveiroArquivo(cfg.ArquivoJWKS, cfg.KIDAtivo)
+		if err != nil {
+			return nil, err
+		}
+		slog.Info("chaves JWT carregadas", "arquivo", cfg.ArquivoJWKS, "kid_ativo", chaveiro.Assinante().KID)
+		return chaveiro, nil
+	}
 
-	// Iniciar servidor
-	porta := ":8080"
-	fmt.Printf("🚀 Servidor rodando em http://localhost%s\n", porta)
-	fmt.Println("📋 Endpoints disponíveis:")
-	fmt.Println("   GET    /              - Informações da API")
-	fmt.Println("   GET    /health        - Status do sistema")
-	fmt.Println("   GET    /filmes        - Listar todos os filmes")
-	fmt.Println("   POST   /filmes        - Criar novo filme")
-	fmt.Println("   GET    /filmes/{id}   - Buscar filme por ID")
-	fmt.Println("   PUT    /filmes/{id}   - Atualizar filme")
-	fmt.Println("   DELETE /filmes/{id}   - Deletar filme")
+	segredo := []byte(cfg.Segredo)
+	if len(segredo) == 0 {
+		if !cfg.SegredoTemporario {
+			return nil, errors.New("defina JWT_SEGREDO ou JWT_JWKS_ARQUIVO (ou JWT_SEGREDO_TEMPORARIO=true em desenvolvimento)")
+		}
+		segredo = make([]byte, 32)
+		if _, err := rand.Read(segredo); err != nil {
+			return nil, err
+		}
+		slog.Warn("JWT_SEGREDO_TEMPORARIO ativo; usando segredo aleatório")
+	}
 
-	if err := http.ListenAndServe(porta, nil); err != nil {
-		log.Fatal("❌ Erro ao iniciar servidor:", err)
+	chave, err := autenticacao.NovaChaveSegredo(cmp.Or(cfg.KIDAtivo, "padrao"), segredo)
+	if err != nil {
+		return nil, err
+	}
+	conjunto, err := autenticacao.NovoConjuntoChaves(chave)
+	if err != nil {
+		return nil, err
 	}
+	return autenticacao.NovoChaveiro(conjunto, chave.KID)
+}
+
+// criarRastreador monta o rastreador com o exportador definido em TRACE_EXPORTADOR
+func criarRastreador() (*rastreamento.Rastreador, error) {
+	cfg, err := config.ObterConfiguracaoRastreamento()
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.Exportador == config.TraceExportadorNenhum {
+		slog.Info("rastreamento configurado", "exportador", cfg.Exportador, "amostragem", 0)
+		return rastreamento.NovoRastreadorNulo(cfg.Servico), nil
+	}
+
+	var exportador rastreamento.Exportador
+	switch cfg.Exportador {
+	case config.TraceExportadorStdout:
+		exportador = rastreamento.NovoExportadorStdout(os.Stdout)
+	case config.TraceExportadorOTLP:
+		exportador = rastreamento.NovoExportadorOTLP(cfg.Endpoint, 10*time.Second)
+	}
+
+	slog.Info("rastreamento configurado", "exportador", cfg.Exportador, "amostragem", cfg.Amostragem)
+	return rastreamento.NovoRastreador(cfg.Servico, cfg.Amostragem, exportador), nil
+}
+
+// criarVerificadorMigracoes retorna o migrador usado pela sonda de prontidão
+// (nil para o repositório em memória, que não tem schema)
+func criarVerificadorMigracoes(repositorio database.FilmeRepositorio) handlers.VerificadorMigracoes {
+	bancoDados, ok := repositorio.(*database.BancoDados)
+	if !ok {
+		return nil
+	}
+
+	migrador, err := migracoes.NovoMigrador(bancoDados.Conexao())
+	if err != nil {
+		slog.Warn("verificação de migrações desativada", "erro", err)
+		return nil
+	}
+	return migrador
 }
 
 // Página inicial com informações da API
 func paginaInicial(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json; charset=utf-8")
 
+	v1 := handlers.VersaoAtual
 	resposta := map[string]interface{}{
 		"mensagem": "🎬 Bem-vindo à API de Filmes!",
-		"versao":   "2.0.0",
+		"versao":   versao.Versao,
+		"versoes_api": map[string]string{
+			"v1": v1,
+		},
 		"recursos": map[string][]string{
 			"filmes": {
-				"GET /filmes - Lista todos os filmes",
-				"POST /filmes - Cria novo filme",
-				"GET /filmes/{id} - Busca filme por ID",
-				"PUT /filmes/{id} - Atualiza filme",
-				"DELETE /filmes/{id} - Remove filme",
+				"GET " + v1 + "/filmes?pagina=1&limite=20&genero=drama,crime&genero_modo=qualquer&sort=-avaliacao - Lista filmes paginados",
+				"GET " + v1 + "/filmes/busca?q=termo - Busca textual por título, descrição e diretor",
+				"POST " + v1 + "/filmes - Cria novo filme (requer token)",
+				"GET " + v1 + "/filmes/{id} - Busca filme por ID",
+				"PUT " + v1 + "/filmes/{id} - Substitui filme",
+				"PATCH " + v1 + "/filmes/{id} - Atualiza campos (merge-patch ou json-patch)",
+				"DELETE " + v1 + "/filmes/{id} - Move filme para a lixeira",
+				"GET " + v1 + "/filmes/lixeira - Lista filmes excluídos (requer token)",
+				"POST " + v1 + "/filmes/{id}/restaurar - Restaura filme da lixeira",
+				"DELETE " + v1 + "/filmes/lixeira/{id} - Remove definitivamente (admin)",
+				"DELETE " + v1 + "/filmes/lixeira - Esvazia a lixeira (admin)",
+				"GET " + v1 + "/filmes/{id}/elenco?funcao=ator - Elenco e equipe",
+				"POST " + v1 + "/filmes/{id}/elenco - Adiciona crédito",
+				"DELETE " + v1 + "/filmes/{id}/elenco/{credito} - Remove crédito",
+				"GET " + v1 + "/filmes/{id}/historico - Alterações de um filme (admin)",
+				"GET " + v1 + "/filmes/{id}/versoes - Revisões de um filme",
+				"GET " + v1 + "/filmes/{id}/versoes/{n} - Snapshot de uma revisão",
+				"GET " + v1 + "/filmes/{id}/versoes/diferenca?de=1&para=2 - Diferença entre revisões",
+				"POST " + v1 + "/filmes/{id}/versoes/{n}/reverter - Reverte para uma revisão",
+				"GET " + v1 + "/auditoria?ator=&operacao=&desde=&ate= - Feed de auditoria (admin)",
+			},
+			"pessoas": {
+				"GET " + v1 + "/pessoas?nome=&pagina=1&limite=20 - Lista pessoas",
+				"GET " + v1 + "/diretores?nome=&pagina=1&limite=20 - Lista pessoas que dirigem filmes",
+				"POST " + v1 + "/pessoas - Cadastra pessoa",
+				"GET " + v1 + "/pessoas/{id} - Busca pessoa por ID",
+				"PUT " + v1 + "/pessoas/{id} - Renomeia pessoa",
+				"DELETE " + v1 + "/pessoas/{id} - Remove pessoa sem filmes",
+				"GET " + v1 + "/pessoas/{id}/filmografia - Participações em filmes",
+				"GET " + v1 + "/diretores/{id}/filmes - Filmes dirigidos",
+			},
+			"generos": {
+				"GET " + v1 + "/generos - Taxonomia de gêneros com total de filmes",
+				"POST " + v1 + "/generos - Cadastra gênero (admin)",
+			},
+			"auth": {
+				"POST " + v1 + "/auth/registrar - Cria conta (nome, email, senha)",
+				"POST " + v1 + "/auth/login - Troca email e senha por tokens de acesso e de renovação",
+				"POST " + v1 + "/auth/renovar - Troca o token de renovação por um novo par",
+				"POST " + v1 + "/auth/logout - Encerra a sessão do token de renovação",
+				"GET " + v1 + "/auth/eu - Usuário do token de acesso",
 			},
 			"sistema": {
-				"GET /health - Status do sistema",
+				"GET /health/live - Liveness (processo ativo)",
+				"GET /health/ready - Readiness (banco, migrações e pool de conexões)",
+				"GET /metrics - Métricas no formato Prometheus",
 			},
 		},
+		"autenticacao": "Escritas em filmes e pessoas exigem Authorization: Bearer <token_acesso>",
+		"aviso_legado": "As rotas sem prefixo de versão (ex.: /filmes) estão depreciadas; veja os cabeçalhos Deprecation e Sunset",
 		"exemplo_criacao": map[string]interface{}{
 			"titulo":          "Nome do Filme",
 			"descricao":       "Descrição do filme",
 			"ano_lancamento":  2024,
 			"duracao_minutos": 120,
-			"genero":          "Drama",
+			"generos":         []string{"drama", "crime"},
 			"diretor":         "Nome do Diretor",
 			"avaliacao":       8.5,
 		},
@@ -90,18 +411,3 @@ func paginaInicial(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(resposta)
 }
-
-// Health check para monitoramento
-func healthCheck(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json; charset=utf-8")
-
-	resposta := map[string]interface{}{
-		"status":    "OK",
-		"timestamp": time.Now().Format(time.RFC3339),
-		"servico":   "API de Filmes",
-		"versao":    "2.0.0",
-	}
-
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(resposta)
-}
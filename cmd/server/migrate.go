@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+
+	"api-filmes/internal/database"
+	"api-filmes/internal/migracoes"
+)
+
+const usoMigrate = `Uso: main migrate <comando>
+
+Comandos:
+  up             Aplica todas as migrações pendentes
+  down [passos]  Reverte as últimas migrações (padrão: 1)
+  status         Lista as migrações e se já foram aplicadas
+  create <nome>  Cria um novo par de arquivos up/down em ` + migracoes.DiretorioPadrao
+
+// executarMigrate implementa o subcomando "migrate"
+func executarMigrate(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("comando de migração ausente\n\n%s", usoMigrate)
+	}
+
+	// "create" só mexe em arquivos; não precisa de banco
+	if args[0] == "create" {
+		if len(args) < 2 {
+			return fmt.Errorf("informe o nome da migração\n\n%s", usoMigrate)
+		}
+		criados, err := migracoes.Criar(migracoes.DiretorioPadrao, args[1])
+		if err != nil {
+			return err
+		}
+		for _, arquivo := range criados {
+			fmt.Printf("📝 Criado %s\n", arquivo)
+		}
+		return nil
+	}
+
+	bancoDados, err := database.NovaConexao()
+	if err != nil {
+		return err
+	}
+	defer bancoDados.Fechar()
+
+	migrador, err := migracoes.NovoMigrador(bancoDados.Conexao())
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+
+	switch args[0] {
+	case "up":
+		aplicadas, err := migrador.Subir(ctx)
+		for _, migracao := range aplicadas {
+			fmt.Printf("⬆️  Aplicada %04d_%s\n", migracao.Versao, migracao.Nome)
+		}
+		if err == nil && len(aplicadas) == 0 {
+			fmt.Println("✅ Nenhuma migração pendente")
+		}
+		return err
+
+	case "down":
+		passos := 1
+		if len(args) > 1 {
+			passos, err = strconv.Atoi(args[1])
+			if err != nil || passos < 1 {
+				return fmt.Errorf("passos deve ser um número inteiro maior que 0")
+			}
+		}
+		revertidas, err := migrador.Descer(ctx, passos)
+		for _, migracao := range revertidas {
+			fmt.Printf("⬇️  Revertida %04d_%s\n", migracao.Versao, migracao.Nome)
+		}
+		return err
+
+	case "status":
+		status, err := migrador.Status(ctx)
+		if err != nil {
+			return err
+		}
+		for _, item := range status {
+			if item.Aplicada {
+				fmt.Printf("✅ %04d_%s (aplicada em %s)\n", item.Versao, item.Nome, item.AplicadaEm.Format("2006-01-02 15:04:05"))
+			} else {
+				fmt.Printf("⏳ %04d_%s (pendente)\n", item.Versao, item.Nome)
+			}
+		}
+		return nil
+
+	default:
+		return fmt.Errorf("comando de migração desconhecido: %q\n\n%s", args[0], usoMigrate)
+	}
+}
+
+// migrarAoIniciar aplica as migrações pendentes antes de servir requisições
+func migrarAoIniciar(bancoDados *database.BancoDados) error {
+	migrador, err := migracoes.NovoMigrador(bancoDados.Conexao())
+	if err != nil {
+		return err
+	}
+
+	aplicadas, err := migrador.Subir(context.Background())
+	for _, migracao := range aplicadas {
+		slog.Info("migração aplicada", "versao", migracao.Versao, "nome", migracao.Nome)
+	}
+	if err != nil {
+		return err
+	}
+
+	if len(aplicadas) == 0 {
+		slog.Info("schema do banco atualizado")
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"api-filmes/internal/database"
+)
+
+// expurgarTentativasLoginPeriodicamente apaga, a cada janela de bloqueio, as
+// tentativas de login cujas falhas já foram esquecidas e cujo bloqueio venceu.
+// Sem isso, qualquer email digitado deixaria uma linha para sempre.
+func expurgarTentativasLoginPeriodicamente(ctx context.Context, repositorio database.UsuarioRepositorio, janela time.Duration) {
+	ticker := time.NewTicker(janela)
+	defer ticker.Stop()
+
+	for {
+		expurgarTentativasLogin(ctx, repositorio, janela)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// expurgarTentativasLogin executa uma rodada do expurgo
+func expurgarTentativasLogin(ctx context.Context, repositorio database.UsuarioRepositorio, janela time.Duration) {
+	ctx, cancelar := context.WithTimeout(ctx, time.Minute)
+	defer cancelar()
+
+	removidos, err := repositorio.ExpurgarTentativasLogin(ctx, time.Now().Add(-janela))
+	if err != nil {
+		if ctx.Err() == nil {
+			slog.Warn("erro no expurgo das tentativas de login", "erro", err)
+		}
+		return
+	}
+	if removidos > 0 {
+		slog.Info("tentativas de login expurgadas", "removidas", removidos)
+	}
+}
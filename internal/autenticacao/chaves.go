@@ -0,0 +1,374 @@
+package autenticacao
+
+import (
+	"context"
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"os"
+	"sync"
+	"time"
+)
+
+// Algoritmos de assinatura aceitos nos tokens de acesso
+const (
+	AlgoritmoHS256 = "HS256"
+	AlgoritmoRS256 = "RS256"
+)
+
+// Tamanhos mínimos das chaves: 256 bits para HMAC (RFC 7518, seção 3.2) e
+// 2048 bits para RSA
+const (
+	tamanhoMinimoSegredo = 32
+	tamanhoMinimoRSA     = 2048
+)
+
+// ErrAssinaturaInvalida indica assinatura que não confere com a chave
+var ErrAssinaturaInvalida = errors.New("assinatura inválida")
+
+// Chave é uma chave de assinatura identificada por kid. Chaves HS256 sempre
+// assinam; chaves RS256 só assinam se o JWKS trouxer a parte privada.
+type Chave struct {
+	KID       string
+	Algoritmo string
+	segredo   []byte
+	publica   *rsa.PublicKey
+	privada   *rsa.PrivateKey
+}
+
+// PodeAssinar indica se a chave tem o material necessário para emitir tokens
+func (c *Chave) PodeAssinar() bool {
+	return c.segredo != nil || c.privada != nil
+}
+
+// assinar calcula a assinatura do trecho "cabecalho.claims" do JWT
+func (c *Chave) assinar(conteudo []byte) ([]byte, error) {
+	switch {
+	case c.segredo != nil:
+		mac := hmac.New(sha256.New, c.segredo)
+		mac.Write(conteudo)
+		return mac.Sum(nil), nil
+	case c.privada != nil:
+		resumo := sha256.Sum256(conteudo)
+		return rsa.SignPKCS1v15(rand.Reader, c.privada, crypto.SHA256, resumo[:])
+	default:
+		return nil, fmt.Errorf("chave %q não tem material privado para assinar", c.KID)
+	}
+}
+
+// verificar confere a assinatura em tempo constante (HMAC) ou pela chave pública (RSA)
+func (c *Chave) verificar(conteudo, assinatura []byte) error {
+	switch {
+	case c.segredo != nil:
+		mac := hmac.New(sha256.New, c.segredo)
+		mac.Write(conteudo)
+		if !hmac.Equal(mac.Sum(nil), assinatura) {
+			return ErrAssinaturaInvalida
+		}
+		return nil
+	default:
+		resumo := sha256.Sum256(conteudo)
+		if err := rsa.VerifyPKCS1v15(c.publica, crypto.SHA256, resumo[:], assinatura); err != nil {
+			return ErrAssinaturaInvalida
+		}
+		return nil
+	}
+}
+
+// NovaChaveSegredo cria uma chave HS256 a partir de um segredo compartilhado
+func NovaChaveSegredo(kid string, segredo []byte) (*Chave, error) {
+	if len(segredo) < tamanhoMinimoSegredo {
+		return nil, fmt.Errorf("segredo da chave %q deve ter pelo menos %d bytes", kid, tamanhoMinimoSegredo)
+	}
+	return &Chave{KID: kid, Algoritmo: AlgoritmoHS256, segredo: segredo}, nil
+}
+
+// ConjuntoChaves é o conteúdo de um JWKS, na ordem do arquivo
+type ConjuntoChaves struct {
+	chaves []*Chave
+}
+
+// NovoConjuntoChaves agrupa chaves já construídas; kids não podem se repetir
+func NovoConjuntoChaves(chaves ...*Chave) (*ConjuntoChaves, error) {
+	vistos := make(map[string]bool, len(chaves))
+	for _, chave := range chaves {
+		if vistos[chave.KID] {
+			return nil, fmt.Errorf("kid %q repetido", chave.KID)
+		}
+		vistos[chave.KID] = true
+	}
+	return &ConjuntoChaves{chaves: chaves}, nil
+}
+
+// Buscar encontra a chave pelo kid. Sem kid, só há resposta se o conjunto
+// tiver uma única chave.
+func (cc *ConjuntoChaves) Buscar(kid string) *Chave {
+	if kid == "" {
+		if len(cc.chaves) == 1 {
+			return cc.chaves[0]
+		}
+		return nil
+	}
+	for _, chave := range cc.chaves {
+		if chave.KID == kid {
+			return chave
+		}
+	}
+	return nil
+}
+
+// Assinante escolhe a chave de emissão: a de kid informado ou, com kid
+// vazio, a primeira do conjunto capaz de assinar
+func (cc *ConjuntoChaves) Assinante(kid string) (*Chave, error) {
+	for _, chave := range cc.chaves {
+		if (kid == "" || chave.KID == kid) && chave.PodeAssinar() {
+			return chave, nil
+		}
+	}
+	if kid != "" {
+		return nil, fmt.Errorf("chave ativa %q não existe ou não tem material privado", kid)
+	}
+	return nil, errors.New("nenhuma chave do conjunto pode assinar")
+}
+
+// jwk segue a RFC 7517; só os membros usados por oct e RSA
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	K   string `json:"k"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	D   string `json:"d"`
+	P   string `json:"p"`
+	Q   string `json:"q"`
+}
+
+// LerJWKS interpreta um documento {"keys": [...]} com chaves "oct" (HS256)
+// e "RSA" (RS256)
+func LerJWKS(dados []byte) (*ConjuntoChaves, error) {
+	var documento struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(dados, &documento); err != nil {
+		return nil, fmt.Errorf("JWKS inválido: %w", err)
+	}
+	if len(documento.Keys) == 0 {
+		return nil, errors.New("JWKS sem chaves")
+	}
+
+	chaves := make([]*Chave, 0, len(documento.Keys))
+	for i, item := range documento.Keys {
+		chave, err := converterJWK(item)
+		if err != nil {
+			return nil, fmt.Errorf("chave %d do JWKS: %w", i, err)
+		}
+		chaves = append(chaves, chave)
+	}
+	return NovoConjuntoChaves(chaves...)
+}
+
+func converterJWK(item jwk) (*Chave, error) {
+	if item.Kid == "" {
+		return nil, errors.New("kid é obrigatório")
+	}
+	if item.Use != "" && item.Use != "sig" {
+		return nil, fmt.Errorf("use %q não é de assinatura", item.Use)
+	}
+
+	switch item.Kty {
+	case "oct":
+		if item.Alg != "" && item.Alg != AlgoritmoHS256 {
+			return nil, fmt.Errorf("alg %q não suportado para oct", item.Alg)
+		}
+		segredo, err := base64.RawURLEncoding.DecodeString(item.K)
+		if err != nil {
+			return nil, fmt.Errorf("k inválido: %w", err)
+		}
+		return NovaChaveSegredo(item.Kid, segredo)
+
+	case "RSA":
+		if item.Alg != "" && item.Alg != AlgoritmoRS256 {
+			return nil, fmt.Errorf("alg %q não suportado para RSA", item.Alg)
+		}
+		n, errN := inteiroBase64(item.N)
+		e, errE := inteiroBase64(item.E)
+		if errN != nil || errE != nil || !e.IsInt64() {
+			return nil, errors.New("n ou e inválidos")
+		}
+		publica := &rsa.PublicKey{N: n, E: int(e.Int64())}
+		if publica.N.BitLen() < tamanhoMinimoRSA {
+			return nil, fmt.Errorf("chave RSA deve ter pelo menos %d bits", tamanhoMinimoRSA)
+		}
+
+		chave := &Chave{KID: item.Kid, Algoritmo: AlgoritmoRS256, publica: publica}
+		if item.D != "" {
+			privada, err := chavePrivadaRSA(publica, item)
+			if err != nil {
+				return nil, err
+			}
+			chave.privada = privada
+		}
+		return chave, nil
+
+	default:
+		return nil, fmt.Errorf("kty %q não suportado (use oct ou RSA)", item.Kty)
+	}
+}
+
+// chavePrivadaRSA monta a chave privada a partir de d, p e q
+func chavePrivadaRSA(publica *rsa.PublicKey, item jwk) (*rsa.PrivateKey, error) {
+	d, errD := inteiroBase64(item.D)
+	p, errP := inteiroBase64(item.P)
+	q, errQ := inteiroBase64(item.Q)
+	if errD != nil || errP != nil || errQ != nil {
+		return nil, errors.New("d, p ou q inválidos")
+	}
+
+	privada := &rsa.PrivateKey{PublicKey: *publica, D: d, Primes: []*big.Int{p, q}}
+	if err := privada.Validate(); err != nil {
+		return nil, fmt.Errorf("chave privada RSA inconsistente: %w", err)
+	}
+	privada.Precompute()
+	return privada, nil
+}
+
+func inteiroBase64(valor string) (*big.Int, error) {
+	bytes, err := base64.RawURLEncoding.DecodeString(valor)
+	if err != nil {
+		return nil, err
+	}
+	if len(bytes) == 0 {
+		return nil, errors.New("valor vazio")
+	}
+	return new(big.Int).SetBytes(bytes), nil
+}
+
+// Chaveiro guarda o conjunto de chaves em uso. Com um arquivo JWKS, o
+// conjunto é relido quando o arquivo muda, o que permite rotacionar chaves
+// sem reiniciar: publique a nova chave, troque a ativa e remova a antiga
+// depois que os tokens assinados por ela expirarem.
+type Chaveiro struct {
+	caminho  string
+	kidAtivo string
+
+	mu         sync.RWMutex
+	conjunto   *ConjuntoChaves
+	assinante  *Chave
+	modificado time.Time
+}
+
+// NovoChaveiro usa um conjunto fixo de chaves (ex.: um segredo HS256 único)
+func NovoChaveiro(conjunto *ConjuntoChaves, kidAtivo string) (*Chaveiro, error) {
+	assinante, err := conjunto.Assinante(kidAtivo)
+	if err != nil {
+		return nil, err
+	}
+	return &Chaveiro{kidAtivo: kidAtivo, conjunto: conjunto, assinante: assinante}, nil
+}
+
+// NovoChaveiroArquivo carrega o JWKS do caminho informado
+func NovoChaveiroArquivo(caminho, kidAtivo string) (*Chaveiro, error) {
+	chaveiro := &Chaveiro{caminho: caminho, kidAtivo: kidAtivo}
+	if _, err := chaveiro.Recarregar(); err != nil {
+		return nil, err
+	}
+	return chaveiro, nil
+}
+
+// Buscar retorna a chave de verificação pelo kid do token
+func (c *Chaveiro) Buscar(kid string) *Chave {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.conjunto.Buscar(kid)
+}
+
+// Assinante retorna a chave usada para emitir novos tokens
+func (c *Chaveiro) Assinante() *Chave {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.assinante
+}
+
+// Recarregar relê o arquivo JWKS se ele mudou desde a última leitura.
+// Em caso de erro, as chaves anteriores continuam valendo.
+func (c *Chaveiro) Recarregar() (bool, error) {
+	if c.caminho == "" {
+		return false, nil
+	}
+
+	info, err := os.Stat(c.caminho)
+	if err != nil {
+		return false, fmt.Errorf("erro ao ler JWKS: %w", err)
+	}
+
+	c.mu.RLock()
+	inalterado := c.conjunto != nil && info.ModTime().Equal(c.modificado)
+	c.mu.RUnlock()
+	if inalterado {
+		return false, nil
+	}
+
+	conjunto, assinante, err := c.lerArquivo()
+	if err != nil {
+		// Marca a versão como lida para não repetir o aviso a cada intervalo
+		c.mu.Lock()
+		c.modificado = info.ModTime()
+		c.mu.Unlock()
+		return false, err
+	}
+
+	c.mu.Lock()
+	c.conjunto, c.assinante, c.modificado = conjunto, assinante, info.ModTime()
+	c.mu.Unlock()
+	return true, nil
+}
+
+func (c *Chaveiro) lerArquivo() (*ConjuntoChaves, *Chave, error) {
+	dados, err := os.ReadFile(c.caminho)
+	if err != nil {
+		return nil, nil, fmt.Errorf("erro ao ler JWKS: %w", err)
+	}
+	conjunto, err := LerJWKS(dados)
+	if err != nil {
+		return nil, nil, err
+	}
+	assinante, err := conjunto.Assinante(c.kidAtivo)
+	if err != nil {
+		return nil, nil, err
+	}
+	return conjunto, assinante, nil
+}
+
+// Monitorar verifica o arquivo JWKS a cada intervalo até o contexto acabar
+func (c *Chaveiro) Monitorar(ctx context.Context, intervalo time.Duration) {
+	if c.caminho == "" {
+		return
+	}
+
+	ticker := time.NewTicker(intervalo)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			recarregado, err := c.Recarregar()
+			if err != nil {
+				slog.Warn("falha ao recarregar JWKS; mantendo as chaves atuais", "arquivo", c.caminho, "erro", err)
+			} else if recarregado {
+				slog.Info("JWKS recarregado", "arquivo", c.caminho, "kid_ativo", c.Assinante().KID)
+			}
+		}
+	}
+}
@@ -0,0 +1,164 @@
+// Package autenticacao reúne as primitivas de segurança das contas de
+// usuário: hash de senhas com argon2id, tokens opacos de sessão e tokens
+// de acesso JWT (HS256 e RS256) com chaves rotacionáveis via JWKS.
+package autenticacao
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// ErrHashInvalido indica um hash armazenado em formato desconhecido
+var ErrHashInvalido = errors.New("hash de senha em formato inválido")
+
+// ParametrosArgon2 define o custo do argon2id. Memoria é dada em KiB.
+type ParametrosArgon2 struct {
+	Memoria     uint32
+	Iteracoes   uint32
+	Paralelismo uint8
+	TamanhoSal  uint32
+	TamanhoHash uint32
+}
+
+// ParametrosPadrao segue a recomendação da RFC 9106 para ambientes com
+// memória limitada (64 MiB, 3 passadas)
+var ParametrosPadrao = ParametrosArgon2{
+	Memoria:     64 * 1024,
+	Iteracoes:   3,
+	Paralelismo: 2,
+	TamanhoSal:  16,
+	TamanhoHash: 32,
+}
+
+// codificacao é o base64 sem padding usado pelo formato PHC
+var codificacao = base64.RawStdEncoding
+
+// vagasHash limita os cálculos de argon2id simultâneos: cada um reserva
+// ParametrosPadrao.Memoria, e uma rajada de logins sem limite esgotaria a
+// memória do processo
+var vagasHash = make(chan struct{}, runtime.NumCPU())
+
+// DefinirHashesSimultaneos troca o limite de cálculos de argon2id ao mesmo
+// tempo; chamar na inicialização, antes de atender requisições
+func DefinirHashesSimultaneos(maximo int) {
+	vagasHash = make(chan struct{}, maximo)
+}
+
+// calcularHash executa argon2id ocupando uma vaga; desiste se o contexto
+// terminar durante a espera
+func calcularHash(ctx context.Context, senha string, sal []byte, p ParametrosArgon2, tamanho uint32) ([]byte, error) {
+	select {
+	case vagasHash <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	defer func() { <-vagasHash }()
+
+	return argon2.IDKey([]byte(senha), sal, p.Iteracoes, p.Memoria, p.Paralelismo, tamanho), nil
+}
+
+// GerarHash calcula o hash da senha com um sal aleatório e o devolve no
+// formato PHC, que guarda junto os parâmetros usados
+func GerarHash(ctx context.Context, senha string) (string, error) {
+	p := ParametrosPadrao
+	sal := make([]byte, p.TamanhoSal)
+	if _, err := rand.Read(sal); err != nil {
+		return "", fmt.Errorf("erro ao gerar sal: %w", err)
+	}
+
+	hash, err := calcularHash(ctx, senha, sal, p, p.TamanhoHash)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s", argon2.Version,
+		p.Memoria, p.Iteracoes, p.Paralelismo, codificacao.EncodeToString(sal), codificacao.EncodeToString(hash)), nil
+}
+
+// VerificarSenha recalcula o hash com os parâmetros e o sal armazenados e
+// compara em tempo constante
+func VerificarSenha(ctx context.Context, senha, armazenado string) (bool, error) {
+	p, sal, hash, err := decodificarHash(armazenado)
+	if err != nil {
+		return false, err
+	}
+
+	calculado, err := calcularHash(ctx, senha, sal, p, uint32(len(hash)))
+	if err != nil {
+		return false, err
+	}
+	return subtle.ConstantTimeCompare(calculado, hash) == 1, nil
+}
+
+// decodificarHash separa parâmetros, sal e hash do formato PHC
+func decodificarHash(armazenado string) (ParametrosArgon2, []byte, []byte, error) {
+	var p ParametrosArgon2
+
+	partes := strings.Split(armazenado, "$")
+	if len(partes) != 6 || partes[1] != "argon2id" {
+		return p, nil, nil, ErrHashInvalido
+	}
+
+	var versao int
+	if _, err := fmt.Sscanf(partes[2], "v=%d", &versao); err != nil || versao != argon2.Version {
+		return p, nil, nil, ErrHashInvalido
+	}
+	if _, err := fmt.Sscanf(partes[3], "m=%d,t=%d,p=%d", &p.Memoria, &p.Iteracoes, &p.Paralelismo); err != nil {
+		return p, nil, nil, ErrHashInvalido
+	}
+	if p.Memoria == 0 || p.Iteracoes == 0 || p.Paralelismo == 0 {
+		return p, nil, nil, ErrHashInvalido
+	}
+
+	sal, err := codificacao.DecodeString(partes[4])
+	if err != nil {
+		return p, nil, nil, ErrHashInvalido
+	}
+	hash, err := codificacao.DecodeString(partes[5])
+	if err != nil || len(hash) == 0 {
+		return p, nil, nil, ErrHashInvalido
+	}
+	return p, sal, hash, nil
+}
+
+var (
+	hashFicticio     string
+	hashFicticioOnce sync.Once
+)
+
+// SimularVerificacao gasta o mesmo tempo de VerificarSenha quando o
+// resultado não será usado (email sem conta ou bloqueado), para que o tempo
+// de resposta não revele contas cadastradas
+func SimularVerificacao(ctx context.Context, senha string) {
+	hashFicticioOnce.Do(func() {
+		hashFicticio, _ = GerarHash(context.Background(), "senha-ficticia")
+	})
+	VerificarSenha(ctx, senha, hashFicticio)
+}
+
+// NovoToken gera um token opaco de 256 bits para o cliente e o hash
+// SHA-256 que o servidor guarda no lugar dele
+func NovoToken() (token, hash string, err error) {
+	bytes := make([]byte, 32)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", "", fmt.Errorf("erro ao gerar token: %w", err)
+	}
+	token = base64.RawURLEncoding.EncodeToString(bytes)
+	return token, HashToken(token), nil
+}
+
+// HashToken calcula o identificador armazenado de um token de sessão
+func HashToken(token string) string {
+	soma := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(soma[:])
+}
@@ -0,0 +1,213 @@
+package autenticacao
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Erros da verificação de tokens de acesso
+var (
+	ErrTokenInvalido = errors.New("token de acesso inválido")
+	ErrTokenExpirado = errors.New("token de acesso expirado")
+)
+
+// folgaRelogio tolera pequenas diferenças de relógio entre servidores
+const folgaRelogio = 30 * time.Second
+
+// Principal é o usuário autenticado de uma requisição
+type Principal struct {
+	UsuarioID int    `json:"id"`
+	Email     string `json:"email"`
+	Nome      string `json:"nome"`
+}
+
+// Ator identifica o principal na auditoria sem expor o email
+func (p *Principal) Ator() string {
+	return "usuario:" + strconv.Itoa(p.UsuarioID)
+}
+
+type chaveContexto int
+
+const chavePrincipal chaveContexto = iota
+
+// ComPrincipal devolve um contexto que carrega o usuário autenticado
+func ComPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, chavePrincipal, principal)
+}
+
+// PrincipalDe retorna o usuário autenticado ou nil em requisições anônimas
+func PrincipalDe(ctx context.Context) *Principal {
+	principal, _ := ctx.Value(chavePrincipal).(*Principal)
+	return principal
+}
+
+// cabecalhoJWT é o cabeçalho JOSE dos tokens
+type cabecalhoJWT struct {
+	Alg string `json:"alg"`
+	Typ string `json:"typ,omitempty"`
+	Kid string `json:"kid,omitempty"`
+}
+
+// claimsAcesso são as claims registradas (RFC 7519) mais os dados do usuário
+type claimsAcesso struct {
+	Emissor   string    `json:"iss"`
+	Sujeito   string    `json:"sub"`
+	Audiencia audiencia `json:"aud"`
+	ExpiraEm  float64   `json:"exp"`
+	EmitidoEm float64   `json:"iat"`
+	NaoAntes  float64   `json:"nbf,omitempty"`
+	ID        string    `json:"jti,omitempty"`
+	Email     string    `json:"email"`
+	Nome      string    `json:"nome"`
+}
+
+// audiencia aceita "aud" como texto ou lista, como permite a RFC 7519
+type audiencia []string
+
+func (a *audiencia) UnmarshalJSON(dados []byte) error {
+	var unica string
+	if err := json.Unmarshal(dados, &unica); err == nil {
+		*a = audiencia{unica}
+		return nil
+	}
+	var lista []string
+	if err := json.Unmarshal(dados, &lista); err != nil {
+		return err
+	}
+	*a = lista
+	return nil
+}
+
+// GerenciadorTokens emite e verifica os tokens de acesso (JWT de curta
+// duração). A renovação usa tokens opacos de sessão, guardados no banco.
+type GerenciadorTokens struct {
+	chaveiro  *Chaveiro
+	emissor   string
+	audiencia string
+	duracao   time.Duration
+}
+
+// NovoGerenciadorTokens cria o gerenciador; emissor e audiencia são
+// gravados em "iss" e "aud" e exigidos na verificação
+func NovoGerenciadorTokens(chaveiro *Chaveiro, emissor, audiencia string, duracao time.Duration) *GerenciadorTokens {
+	return &GerenciadorTokens{
+		chaveiro:  chaveiro,
+		emissor:   emissor,
+		audiencia: audiencia,
+		duracao:   duracao,
+	}
+}
+
+// Emitir assina um token de acesso para o principal com a chave ativa
+func (g *GerenciadorTokens) Emitir(principal *Principal) (string, time.Time, error) {
+	chave := g.chaveiro.Assinante()
+	agora := time.Now()
+	expiraEm := agora.Add(g.duracao)
+
+	cabecalho, err := json.Marshal(cabecalhoJWT{Alg: chave.Algoritmo, Typ: "JWT", Kid: chave.KID})
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	jti, _, err := NovoToken()
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	claims, err := json.Marshal(claimsAcesso{
+		Emissor:   g.emissor,
+		Sujeito:   strconv.Itoa(principal.UsuarioID),
+		Audiencia: audiencia{g.audiencia},
+		ExpiraEm:  float64(expiraEm.Unix()),
+		EmitidoEm: float64(agora.Unix()),
+		ID:        jti,
+		Email:     principal.Email,
+		Nome:      principal.Nome,
+	})
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	conteudo := codificarSegmento(cabecalho) + "." + codificarSegmento(claims)
+	assinatura, err := chave.assinar([]byte(conteudo))
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	return conteudo + "." + codificarSegmento(assinatura), expiraEm, nil
+}
+
+// Verificar confere assinatura, algoritmo, emissor, audiência e validade do
+// token. O algoritmo do cabeçalho precisa ser o da chave indicada pelo kid,
+// o que impede trocar RS256 por HS256 ou usar "none".
+func (g *GerenciadorTokens) Verificar(token string) (*Principal, error) {
+	partes := strings.Split(token, ".")
+	if len(partes) != 3 {
+		return nil, fmt.Errorf("%w: formato compacto esperado", ErrTokenInvalido)
+	}
+
+	var cabecalho cabecalhoJWT
+	if err := decodificarSegmento(partes[0], &cabecalho); err != nil {
+		return nil, fmt.Errorf("%w: cabeçalho ilegível", ErrTokenInvalido)
+	}
+
+	chave := g.chaveiro.Buscar(cabecalho.Kid)
+	if chave == nil {
+		return nil, fmt.Errorf("%w: chave %q desconhecida", ErrTokenInvalido, cabecalho.Kid)
+	}
+	if cabecalho.Alg != chave.Algoritmo {
+		return nil, fmt.Errorf("%w: algoritmo %q não corresponde à chave", ErrTokenInvalido, cabecalho.Alg)
+	}
+
+	assinatura, err := base64.RawURLEncoding.DecodeString(partes[2])
+	if err != nil {
+		return nil, fmt.Errorf("%w: assinatura ilegível", ErrTokenInvalido)
+	}
+	if err := chave.verificar([]byte(partes[0]+"."+partes[1]), assinatura); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrTokenInvalido, err)
+	}
+
+	var claims claimsAcesso
+	if err := decodificarSegmento(partes[1], &claims); err != nil {
+		return nil, fmt.Errorf("%w: claims ilegíveis", ErrTokenInvalido)
+	}
+
+	agora := time.Now()
+	switch {
+	case claims.ExpiraEm == 0:
+		return nil, fmt.Errorf("%w: exp ausente", ErrTokenInvalido)
+	case agora.After(time.Unix(int64(claims.ExpiraEm), 0).Add(folgaRelogio)):
+		return nil, ErrTokenExpirado
+	case claims.NaoAntes != 0 && agora.Add(folgaRelogio).Before(time.Unix(int64(claims.NaoAntes), 0)):
+		return nil, fmt.Errorf("%w: token ainda não é válido", ErrTokenInvalido)
+	case claims.Emissor != g.emissor:
+		return nil, fmt.Errorf("%w: emissor %q não aceito", ErrTokenInvalido, claims.Emissor)
+	case !slices.Contains(claims.Audiencia, g.audiencia):
+		return nil, fmt.Errorf("%w: audiência não aceita", ErrTokenInvalido)
+	}
+
+	id, err := strconv.Atoi(claims.Sujeito)
+	if err != nil || id < 1 {
+		return nil, fmt.Errorf("%w: sub inválido", ErrTokenInvalido)
+	}
+
+	return &Principal{UsuarioID: id, Email: claims.Email, Nome: claims.Nome}, nil
+}
+
+func codificarSegmento(dados []byte) string {
+	return base64.RawURLEncoding.EncodeToString(dados)
+}
+
+func decodificarSegmento(segmento string, destino any) error {
+	dados, err := base64.RawURLEncoding.DecodeString(segmento)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(dados, destino)
+}
@@ -0,0 +1,320 @@
+package autenticacao
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const (
+	emissorTeste   = "api-filmes"
+	audienciaTeste = "api-filmes"
+)
+
+var (
+	rsaTeste     *rsa.PrivateKey
+	rsaTesteOnce sync.Once
+)
+
+// chaveRSATeste gera uma única chave RSA de 2048 bits para todos os testes
+func chaveRSATeste(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+	rsaTesteOnce.Do(func() {
+		chave, err := rsa.GenerateKey(rand.Reader, tamanhoMinimoRSA)
+		if err != nil {
+			t.Fatalf("erro ao gerar chave RSA: %v", err)
+		}
+		rsaTeste = chave
+	})
+	return rsaTeste
+}
+
+func segredoTeste(t *testing.T, kid, segredo string) *Chave {
+	t.Helper()
+	chave, err := NovaChaveSegredo(kid, []byte(segredo))
+	if err != nil {
+		t.Fatalf("NovaChaveSegredo(%q): %v", kid, err)
+	}
+	return chave
+}
+
+// claimsValidas são as claims de um token emitido agora para o usuário 7
+func claimsValidas() claimsAcesso {
+	agora := time.Now()
+	return claimsAcesso{
+		Emissor:   emissorTeste,
+		Sujeito:   "7",
+		Audiencia: audiencia{audienciaTeste},
+		ExpiraEm:  float64(agora.Add(time.Minute).Unix()),
+		EmitidoEm: float64(agora.Unix()),
+		Email:     "ana@ex.com",
+		Nome:      "Ana",
+	}
+}
+
+// montarToken serializa cabeçalho e claims e assina com a função recebida,
+// o que permite montar tokens que o Emitir nunca produziria
+func montarToken(t *testing.T, cabecalho cabecalhoJWT, claims claimsAcesso, assinar func([]byte) []byte) string {
+	t.Helper()
+	cabecalhoJSON, err := json.Marshal(cabecalho)
+	if err != nil {
+		t.Fatal(err)
+	}
+	claimsJSON, err := json.Marshal(claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conteudo := codificarSegmento(cabecalhoJSON) + "." + codificarSegmento(claimsJSON)
+	return conteudo + "." + codificarSegmento(assinar([]byte(conteudo)))
+}
+
+// assinadoPor assina com a chave do conjunto, como o próprio servidor
+func assinadoPor(t *testing.T, chave *Chave) func([]byte) []byte {
+	return func(conteudo []byte) []byte {
+		assinatura, err := chave.assinar(conteudo)
+		if err != nil {
+			t.Fatalf("assinar com %q: %v", chave.KID, err)
+		}
+		return assinatura
+	}
+}
+
+// hmacCom assina HS256 com bytes arbitrários, como um atacante faria
+func hmacCom(segredo []byte) func([]byte) []byte {
+	return func(conteudo []byte) []byte {
+		mac := hmac.New(sha256.New, segredo)
+		mac.Write(conteudo)
+		return mac.Sum(nil)
+	}
+}
+
+func TestVerificar(t *testing.T) {
+	privada := chaveRSATeste(t)
+	chaveRSA := &Chave{KID: "rsa-1", Algoritmo: AlgoritmoRS256, publica: &privada.PublicKey, privada: privada}
+	chaveHS := segredoTeste(t, "hs-1", "0123456789abcdef0123456789abcdef")
+
+	conjunto, err := NovoConjuntoChaves(chaveRSA, chaveHS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chaveiro, err := NovoChaveiro(conjunto, chaveRSA.KID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gerenciador := NovoGerenciadorTokens(chaveiro, emissorTeste, audienciaTeste, time.Minute)
+
+	publicaDER, err := x509.MarshalPKIXPublicKey(&privada.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rs256 := cabecalhoJWT{Alg: AlgoritmoRS256, Typ: "JWT", Kid: chaveRSA.KID}
+	alterar := func(mudar func(*claimsAcesso)) claimsAcesso {
+		claims := claimsValidas()
+		mudar(&claims)
+		return claims
+	}
+
+	valido := montarToken(t, rs256, claimsValidas(), assinadoPor(t, chaveRSA))
+	partes := strings.Split(valido, ".")
+	outroSujeito := montarToken(t, rs256, alterar(func(c *claimsAcesso) { c.Sujeito = "1" }), assinadoPor(t, chaveRSA))
+	assinaturaTrocada := []byte(partes[2])
+	assinaturaTrocada[10] ^= 1
+
+	casos := []struct {
+		nome  string
+		token string
+		erro  error
+	}{
+		{nome: "token válido", token: valido},
+		{nome: "emitido pelo gerenciador", token: emitirTeste(t, gerenciador)},
+		{nome: "token HS256 válido", token: montarToken(t, cabecalhoJWT{Alg: AlgoritmoHS256, Kid: chaveHS.KID}, claimsValidas(), assinadoPor(t, chaveHS))},
+		{nome: "expirado dentro da folga do relógio", token: montarToken(t, rs256, alterar(func(c *claimsAcesso) {
+			c.ExpiraEm = float64(time.Now().Add(-folgaRelogio / 2).Unix())
+		}), assinadoPor(t, chaveRSA))},
+		{nome: "audiência em lista", token: montarToken(t, rs256, alterar(func(c *claimsAcesso) {
+			c.Audiencia = audiencia{"outra-api", audienciaTeste}
+		}), assinadoPor(t, chaveRSA))},
+
+		{nome: "assinatura adulterada", token: partes[0] + "." + partes[1] + "." + string(assinaturaTrocada), erro: ErrTokenInvalido},
+		{nome: "claims adulteradas", token: partes[0] + "." + strings.Split(outroSujeito, ".")[1] + "." + partes[2], erro: ErrTokenInvalido},
+		{nome: "sem assinatura", token: partes[0] + "." + partes[1] + ".", erro: ErrTokenInvalido},
+		{nome: "formato não compacto", token: partes[0] + "." + partes[1], erro: ErrTokenInvalido},
+		{nome: "RS256 trocado por HS256 com a chave pública", token: montarToken(t, cabecalhoJWT{Alg: AlgoritmoHS256, Typ: "JWT", Kid: chaveRSA.KID}, claimsValidas(), hmacCom(publicaDER)), erro: ErrTokenInvalido},
+		{nome: "alg none", token: montarToken(t, cabecalhoJWT{Alg: "none", Typ: "JWT", Kid: chaveRSA.KID}, claimsValidas(), func([]byte) []byte { return nil }), erro: ErrTokenInvalido},
+		{nome: "kid desconhecido", token: montarToken(t, cabecalhoJWT{Alg: AlgoritmoRS256, Kid: "rsa-2"}, claimsValidas(), assinadoPor(t, chaveRSA)), erro: ErrTokenInvalido},
+		{nome: "sem kid com várias chaves", token: montarToken(t, cabecalhoJWT{Alg: AlgoritmoRS256}, claimsValidas(), assinadoPor(t, chaveRSA)), erro: ErrTokenInvalido},
+		{nome: "expirado", token: montarToken(t, rs256, alterar(func(c *claimsAcesso) {
+			c.ExpiraEm = float64(time.Now().Add(-2 * folgaRelogio).Unix())
+		}), assinadoPor(t, chaveRSA)), erro: ErrTokenExpirado},
+		{nome: "sem exp", token: montarToken(t, rs256, alterar(func(c *claimsAcesso) { c.ExpiraEm = 0 }), assinadoPor(t, chaveRSA)), erro: ErrTokenInvalido},
+		{nome: "nbf no futuro", token: montarToken(t, rs256, alterar(func(c *claimsAcesso) {
+			c.NaoAntes = float64(time.Now().Add(time.Hour).Unix())
+		}), assinadoPor(t, chaveRSA)), erro: ErrTokenInvalido},
+		{nome: "audiência errada", token: montarToken(t, rs256, alterar(func(c *claimsAcesso) { c.Audiencia = audiencia{"outra-api"} }), assinadoPor(t, chaveRSA)), erro: ErrTokenInvalido},
+		{nome: "emissor errado", token: montarToken(t, rs256, alterar(func(c *claimsAcesso) { c.Emissor = "outro" }), assinadoPor(t, chaveRSA)), erro: ErrTokenInvalido},
+		{nome: "sub inválido", token: montarToken(t, rs256, alterar(func(c *claimsAcesso) { c.Sujeito = "ana" }), assinadoPor(t, chaveRSA)), erro: ErrTokenInvalido},
+	}
+
+	for _, caso := range casos {
+		t.Run(caso.nome, func(t *testing.T) {
+			principal, err := gerenciador.Verificar(caso.token)
+			if caso.erro != nil {
+				if !errors.Is(err, caso.erro) {
+					t.Fatalf("erro = %v, esperado %v", err, caso.erro)
+				}
+				if principal != nil {
+					t.Errorf("principal = %+v, esperado nil", principal)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("erro inesperado: %v", err)
+			}
+			if principal.UsuarioID != 7 || principal.Email != "ana@ex.com" {
+				t.Errorf("principal = %+v, esperado usuário 7 ana@ex.com", principal)
+			}
+		})
+	}
+}
+
+func emitirTeste(t *testing.T, gerenciador *GerenciadorTokens) string {
+	t.Helper()
+	token, _, err := gerenciador.Emitir(&Principal{UsuarioID: 7, Email: "ana@ex.com", Nome: "Ana"})
+	if err != nil {
+		t.Fatalf("Emitir: %v", err)
+	}
+	return token
+}
+
+// kidDoToken lê o kid do cabeçalho sem verificar o token
+func kidDoToken(t *testing.T, token string) string {
+	t.Helper()
+	var cabecalho cabecalhoJWT
+	if err := decodificarSegmento(strings.Split(token, ".")[0], &cabecalho); err != nil {
+		t.Fatalf("cabeçalho ilegível: %v", err)
+	}
+	return cabecalho.Kid
+}
+
+// jwkRSA serializa a chave RSA; sem privada, só a parte pública
+func jwkRSA(kid string, chave *rsa.PrivateKey, privada bool) jwk {
+	inteiro := func(valor *big.Int) string { return codificarSegmento(valor.Bytes()) }
+	item := jwk{
+		Kty: "RSA",
+		Kid: kid,
+		Alg: AlgoritmoRS256,
+		Use: "sig",
+		N:   inteiro(chave.N),
+		E:   inteiro(big.NewInt(int64(chave.E))),
+	}
+	if privada {
+		item.D = inteiro(chave.D)
+		item.P = inteiro(chave.Primes[0])
+		item.Q = inteiro(chave.Primes[1])
+	}
+	return item
+}
+
+func jwkSegredo(kid, segredo string) jwk {
+	return jwk{Kty: "oct", Kid: kid, Alg: AlgoritmoHS256, K: codificarSegmento([]byte(segredo))}
+}
+
+// gravarJWKS reescreve o arquivo e avança o mtime, que é o que o Chaveiro
+// compara para decidir se relê
+func gravarJWKS(t *testing.T, caminho string, modificado time.Time, chaves ...jwk) {
+	t.Helper()
+	dados, err := json.Marshal(map[string][]jwk{"keys": chaves})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(caminho, dados, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(caminho, modificado, modificado); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRecarregarTrocaChaveAtiva(t *testing.T) {
+	privada := chaveRSATeste(t)
+	caminho := filepath.Join(t.TempDir(), "jwks.json")
+	inicio := time.Now().Add(-time.Hour)
+
+	gravarJWKS(t, caminho, inicio, jwkRSA("rsa-1", privada, true))
+	chaveiro, err := NovoChaveiroArquivo(caminho, "")
+	if err != nil {
+		t.Fatalf("NovoChaveiroArquivo: %v", err)
+	}
+	gerenciador := NovoGerenciadorTokens(chaveiro, emissorTeste, audienciaTeste, time.Minute)
+
+	antigo := emitirTeste(t, gerenciador)
+	if kid := kidDoToken(t, antigo); kid != "rsa-1" {
+		t.Fatalf("kid = %q, esperado %q", kid, "rsa-1")
+	}
+
+	if recarregou, err := chaveiro.Recarregar(); err != nil || recarregou {
+		t.Fatalf("Recarregar sem mudança = %v, %v; esperado false, nil", recarregou, err)
+	}
+
+	// Rotação: a nova chave passa a ser a primeira capaz de assinar e a
+	// antiga fica só com a parte pública, para validar tokens já emitidos
+	gravarJWKS(t, caminho, inicio.Add(time.Minute),
+		jwkSegredo("hs-2", "fedcba9876543210fedcba9876543210"),
+		jwkRSA("rsa-1", privada, false),
+	)
+	if recarregou, err := chaveiro.Recarregar(); err != nil || !recarregou {
+		t.Fatalf("Recarregar = %v, %v; esperado true, nil", recarregou, err)
+	}
+	if kid := chaveiro.Assinante().KID; kid != "hs-2" {
+		t.Fatalf("chave ativa = %q, esperado %q", kid, "hs-2")
+	}
+
+	novo := emitirTeste(t, gerenciador)
+	if kid := kidDoToken(t, novo); kid != "hs-2" {
+		t.Errorf("kid do novo token = %q, esperado %q", kid, "hs-2")
+	}
+	if _, err := gerenciador.Verificar(novo); err != nil {
+		t.Errorf("novo token recusado: %v", err)
+	}
+	if _, err := gerenciador.Verificar(antigo); err != nil {
+		t.Errorf("token antigo recusado enquanto a chave está publicada: %v", err)
+	}
+
+	// Um arquivo inválido mantém as chaves anteriores
+	if err := os.WriteFile(caminho, []byte("{"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(caminho, inicio.Add(2*time.Minute), inicio.Add(2*time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := chaveiro.Recarregar(); err == nil {
+		t.Fatal("Recarregar com JWKS inválido deveria falhar")
+	}
+	if kid := chaveiro.Assinante().KID; kid != "hs-2" {
+		t.Errorf("chave ativa após erro = %q, esperado %q", kid, "hs-2")
+	}
+
+	// Removida do JWKS, a chave antiga deixa de validar seus tokens
+	gravarJWKS(t, caminho, inicio.Add(3*time.Minute), jwkSegredo("hs-2", "fedcba9876543210fedcba9876543210"))
+	if recarregou, err := chaveiro.Recarregar(); err != nil || !recarregou {
+		t.Fatalf("Recarregar = %v, %v; esperado true, nil", recarregou, err)
+	}
+	if _, err := gerenciador.Verificar(antigo); !errors.Is(err, ErrTokenInvalido) {
+		t.Errorf("erro = %v, esperado %v", err, ErrTokenInvalido)
+	}
+	if _, err := gerenciador.Verificar(novo); err != nil {
+		t.Errorf("novo token recusado: %v", err)
+	}
+}
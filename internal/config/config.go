@@ -2,7 +2,13 @@ package config
 
 import (
 	"fmt"
+	"log/slog"
+	"net"
 	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
 )
 
 // ConfiguracaoBanco contém as informações de conexão com o banco
@@ -27,6 +33,338 @@ func ObterConfiguracaoBanco() *ConfiguracaoBanco {
 	}
 }
 
+// Repositórios disponíveis para persistência dos filmes
+const (
+	RepositorioPostgres = "postgres"
+	RepositorioMemoria  = "memoria"
+)
+
+// ConfiguracaoAplicacao contém opções gerais da aplicação
+type ConfiguracaoAplicacao struct {
+	Repositorio     string
+	MigrarAoIniciar bool
+	// TokenAdmin libera as rotas administrativas; vazio as desativa
+	TokenAdmin string
+}
+
+// ObterConfiguracaoAplicacao retorna a configuração geral da aplicação
+func ObterConfiguracaoAplicacao() *ConfiguracaoAplicacao {
+	return &ConfiguracaoAplicacao{
+		Repositorio:     obterVariavelOuPadrao("REPOSITORIO", RepositorioPostgres),
+		MigrarAoIniciar: obterVariavelOuPadrao("MIGRAR_AO_INICIAR", "true") == "true",
+		TokenAdmin:      os.Getenv("ADMIN_TOKEN"),
+	}
+}
+
+// ConfiguracaoTimeouts define o tempo máximo de cada rota no acesso a dados
+type ConfiguracaoTimeouts struct {
+	Padrao  time.Duration
+	PorRota map[string]time.Duration
+}
+
+// ObterConfiguracaoTimeouts lê TIMEOUT_PADRAO (ex.: "5s") e TIMEOUT_ROTAS
+// (ex.: "busca=2s,listar=3s") para permitir limites específicos por rota
+func ObterConfiguracaoTimeouts() (*ConfiguracaoTimeouts, error) {
+	padrao, err := time.ParseDuration(obterVariavelOuPadrao("TIMEOUT_PADRAO", "5s"))
+	if err != nil {
+		return nil, fmt.Errorf("TIMEOUT_PADRAO inválido: %w", err)
+	}
+
+	configuracao := &ConfiguracaoTimeouts{
+		Padrao: padrao,
+		PorRota: map[string]time.Duration{
+			// A sonda de prontidão precisa responder antes do orquestrador desistir
+			"saude": 2 * time.Second,
+		},
+	}
+
+	for _, item := range strings.Split(os.Getenv("TIMEOUT_ROTAS"), ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		rota, valor, ok := strings.Cut(item, "=")
+		if !ok {
+			return nil, fmt.Errorf("TIMEOUT_ROTAS inválido: %q (use rota=duração)", item)
+		}
+
+		duracao, err := time.ParseDuration(strings.TrimSpace(valor))
+		if err != nil {
+			return nil, fmt.Errorf("TIMEOUT_ROTAS inválido para %q: %w", rota, err)
+		}
+		configuracao.PorRota[strings.TrimSpace(rota)] = duracao
+	}
+
+	return configuracao, nil
+}
+
+// Timeout retorna o limite configurado para a rota (ou o padrão)
+func (c *ConfiguracaoTimeouts) Timeout(rota string) time.Duration {
+	if duracao, ok := c.PorRota[rota]; ok {
+		return duracao
+	}
+	return c.Padrao
+}
+
+// ConfiguracaoServidor contém os parâmetros do servidor HTTP
+type ConfiguracaoServidor struct {
+	Endereco                string
+	Porta                   string
+	TimeoutLeitura          time.Duration
+	TimeoutLeituraCabecalho time.Duration
+	TimeoutEscrita          time.Duration
+	TimeoutOcioso           time.Duration
+	TimeoutDesligamento     time.Duration
+	MaxBytesCabecalho       int
+}
+
+// ObterConfiguracaoServidor retorna a configuração do servidor HTTP
+func ObterConfiguracaoServidor() (*ConfiguracaoServidor, error) {
+	configuracao := &ConfiguracaoServidor{
+		Endereco: obterVariavelOuPadrao("SERVIDOR_ENDERECO", ""),
+		Porta:    obterVariavelOuPadrao("PORTA", "8080"),
+	}
+
+	duracoes := []struct {
+		destino *time.Duration
+		chave   string
+		padrao  string
+	}{
+		{&configuracao.TimeoutLeitura, "SERVIDOR_TIMEOUT_LEITURA", "15s"},
+		{&configuracao.TimeoutLeituraCabecalho, "SERVIDOR_TIMEOUT_CABECALHO", "5s"},
+		{&configuracao.TimeoutEscrita, "SERVIDOR_TIMEOUT_ESCRITA", "30s"},
+		{&configuracao.TimeoutOcioso, "SERVIDOR_TIMEOUT_OCIOSO", "60s"},
+		{&configuracao.TimeoutDesligamento, "SERVIDOR_TIMEOUT_DESLIGAMENTO", "20s"},
+	}
+	for _, item := range duracoes {
+		duracao, err := time.ParseDuration(obterVariavelOuPadrao(item.chave, item.padrao))
+		if err != nil {
+			return nil, fmt.Errorf("%s inválido: %w", item.chave, err)
+		}
+		*item.destino = duracao
+	}
+
+	maxBytes, err := strconv.Atoi(obterVariavelOuPadrao("SERVIDOR_MAX_BYTES_CABECALHO", "1048576"))
+	if err != nil || maxBytes <= 0 {
+		return nil, fmt.Errorf("SERVIDOR_MAX_BYTES_CABECALHO inválido: deve ser um inteiro positivo")
+	}
+	configuracao.MaxBytesCabecalho = maxBytes
+
+	return configuracao, nil
+}
+
+// EnderecoCompleto retorna o endereço no formato host:porta
+func (c *ConfiguracaoServidor) EnderecoCompleto() string {
+	return net.JoinHostPort(c.Endereco, c.Porta)
+}
+
+// ConfiguracaoVersionamento define o calendário de desativação das rotas
+// legadas sem prefixo de versão
+type ConfiguracaoVersionamento struct {
+	LegadoDepreciadoEm time.Time
+	LegadoSunset       time.Time
+}
+
+// ObterConfiguracaoVersionamento lê LEGADO_DEPRECIADO_EM e LEGADO_SUNSET
+// no formato AAAA-MM-DD
+func ObterConfiguracaoVersionamento() (*ConfiguracaoVersionamento, error) {
+	depreciadoEm, err := time.Parse(time.DateOnly, obterVariavelOuPadrao("LEGADO_DEPRECIADO_EM", "2026-11-01"))
+	if err != nil {
+		return nil, fmt.Errorf("LEGADO_DEPRECIADO_EM inválido: %w", err)
+	}
+
+	sunset, err := time.Parse(time.DateOnly, obterVariavelOuPadrao("LEGADO_SUNSET", "2027-05-01"))
+	if err != nil {
+		return nil, fmt.Errorf("LEGADO_SUNSET inválido: %w", err)
+	}
+
+	if sunset.Before(depreciadoEm) {
+		return nil, fmt.Errorf("LEGADO_SUNSET deve ser posterior a LEGADO_DEPRECIADO_EM")
+	}
+
+	return &ConfiguracaoVersionamento{LegadoDepreciadoEm: depreciadoEm, LegadoSunset: sunset}, nil
+}
+
+// ConfiguracaoLixeira define por quanto tempo filmes excluídos são mantidos
+type ConfiguracaoLixeira struct {
+	// Retencao é a idade a partir da qual o expurgo apaga o filme (0 desativa)
+	Retencao         time.Duration
+	IntervaloExpurgo time.Duration
+}
+
+// ObterConfiguracaoLixeira lê LIXEIRA_RETENCAO (ex.: "720h") e
+// LIXEIRA_INTERVALO_EXPURGO (ex.: "1h")
+func ObterConfiguracaoLixeira() (*ConfiguracaoLixeira, error) {
+	retencao, err := time.ParseDuration(obterVariavelOuPadrao("LIXEIRA_RETENCAO", "720h"))
+	if err != nil || retencao < 0 {
+		return nil, fmt.Errorf("LIXEIRA_RETENCAO inválido: use uma duração não negativa (ex.: 720h)")
+	}
+
+	intervalo, err := time.ParseDuration(obterVariavelOuPadrao("LIXEIRA_INTERVALO_EXPURGO", "1h"))
+	if err != nil || intervalo <= 0 {
+		return nil, fmt.Errorf("LIXEIRA_INTERVALO_EXPURGO inválido: use uma duração positiva (ex.: 1h)")
+	}
+
+	return &ConfiguracaoLixeira{Retencao: retencao, IntervaloExpurgo: intervalo}, nil
+}
+
+// ConfiguracaoAutenticacao define a duração dos tokens, as chaves de
+// assinatura dos tokens de acesso e a política de bloqueio de contas após
+// falhas de login
+type ConfiguracaoAutenticacao struct {
+	// DuracaoSessao é a validade do token de renovação (a sessão de login)
+	DuracaoSessao time.Duration
+	// DuracaoAcesso é a validade do token de acesso JWT
+	DuracaoAcesso time.Duration
+	// MaximoTentativas é o número de falhas consecutivas que bloqueia o email
+	MaximoTentativas int
+	// DuracaoBloqueio é também a janela em que as falhas contam
+	DuracaoBloqueio time.Duration
+	// HashesSimultaneos limita os cálculos de argon2id ao mesmo tempo
+	HashesSimultaneos int
+
+	// ArquivoJWKS aponta para as chaves HS256/RS256, relidas a cada
+	// IntervaloJWKS; Segredo é a alternativa com uma única chave HS256
+	ArquivoJWKS   string
+	IntervaloJWKS time.Duration
+	Segredo       string
+	// SegredoTemporario permite subir sem chaves, com um segredo aleatório
+	// que muda a cada reinício; só para desenvolvimento
+	SegredoTemporario bool
+	// KIDAtivo escolhe a chave que assina; vazio usa a primeira do JWKS
+	KIDAtivo  string
+	Emissor   string
+	Audiencia string
+}
+
+// ObterConfiguracaoAutenticacao lê AUTH_DURACAO_SESSAO (ex.: "24h"),
+// AUTH_MAX_TENTATIVAS, AUTH_DURACAO_BLOQUEIO (ex.: "15m"),
+// AUTH_HASHES_SIMULTANEOS (padrão: número de CPUs) e as variáveis
+// JWT_*: JWT_DURACAO, JWT_JWKS_ARQUIVO, JWT_JWKS_INTERVALO, JWT_SEGREDO,
+// JWT_SEGREDO_TEMPORARIO, JWT_KID_ATIVO, JWT_EMISSOR e JWT_AUDIENCIA
+func ObterConfiguracaoAutenticacao() (*ConfiguracaoAutenticacao, error) {
+	configuracao := &ConfiguracaoAutenticacao{
+		ArquivoJWKS:       os.Getenv("JWT_JWKS_ARQUIVO"),
+		Segredo:           os.Getenv("JWT_SEGREDO"),
+		SegredoTemporario: os.Getenv("JWT_SEGREDO_TEMPORARIO") == "true",
+		KIDAtivo:          os.Getenv("JWT_KID_ATIVO"),
+		Emissor:           obterVariavelOuPadrao("JWT_EMISSOR", "api-filmes"),
+		Audiencia:         obterVariavelOuPadrao("JWT_AUDIENCIA", "api-filmes"),
+	}
+
+	if configuracao.ArquivoJWKS != "" && configuracao.Segredo != "" {
+		return nil, fmt.Errorf("defina apenas um entre JWT_JWKS_ARQUIVO e JWT_SEGREDO")
+	}
+
+	duracoes := []struct {
+		destino *time.Duration
+		chave   string
+		padrao  string
+	}{
+		{&configuracao.DuracaoSessao, "AUTH_DURACAO_SESSAO", "24h"},
+		{&configuracao.DuracaoBloqueio, "AUTH_DURACAO_BLOQUEIO", "15m"},
+		{&configuracao.DuracaoAcesso, "JWT_DURACAO", "15m"},
+		{&configuracao.IntervaloJWKS, "JWT_JWKS_INTERVALO", "30s"},
+	}
+	for _, item := range duracoes {
+		duracao, err := time.ParseDuration(obterVariavelOuPadrao(item.chave, item.padrao))
+		if err != nil || duracao <= 0 {
+			return nil, fmt.Errorf("%s inválido: use uma duração positiva (ex.: %s)", item.chave, item.padrao)
+		}
+		*item.destino = duracao
+	}
+
+	if configuracao.DuracaoAcesso > configuracao.DuracaoSessao {
+		return nil, fmt.Errorf("JWT_DURACAO não pode ser maior que AUTH_DURACAO_SESSAO")
+	}
+
+	tentativas, err := strconv.Atoi(obterVariavelOuPadrao("AUTH_MAX_TENTATIVAS", "5"))
+	if err != nil || tentativas < 1 {
+		return nil, fmt.Errorf("AUTH_MAX_TENTATIVAS inválido: deve ser um inteiro positivo")
+	}
+	configuracao.MaximoTentativas = tentativas
+
+	hashes, err := strconv.Atoi(obterVariavelOuPadrao("AUTH_HASHES_SIMULTANEOS", strconv.Itoa(runtime.NumCPU())))
+	if err != nil || hashes < 1 {
+		return nil, fmt.Errorf("AUTH_HASHES_SIMULTANEOS inválido: deve ser um inteiro positivo")
+	}
+	configuracao.HashesSimultaneos = hashes
+
+	return configuracao, nil
+}
+
+// Formatos de log aceitos em LOG_FORMATO
+const (
+	LogFormatoJSON  = "json"
+	LogFormatoTexto = "texto"
+)
+
+// ConfiguracaoLog define formato e nível mínimo dos logs
+type ConfiguracaoLog struct {
+	Formato string
+	Nivel   slog.Level
+}
+
+// ObterConfiguracaoLog lê LOG_FORMATO (json ou texto) e LOG_NIVEL
+// (debug, info, warn ou error)
+func ObterConfiguracaoLog() (*ConfiguracaoLog, error) {
+	configuracao := &ConfiguracaoLog{
+		Formato: strings.ToLower(obterVariavelOuPadrao("LOG_FORMATO", LogFormatoJSON)),
+	}
+
+	if configuracao.Formato != LogFormatoJSON && configuracao.Formato != LogFormatoTexto {
+		return nil, fmt.Errorf("LOG_FORMATO inválido: %q (use %q ou %q)",
+			configuracao.Formato, LogFormatoJSON, LogFormatoTexto)
+	}
+
+	if err := configuracao.Nivel.UnmarshalText([]byte(obterVariavelOuPadrao("LOG_NIVEL", "info"))); err != nil {
+		return nil, fmt.Errorf("LOG_NIVEL inválido: %w", err)
+	}
+
+	return configuracao, nil
+}
+
+// Exportadores de spans aceitos em TRACE_EXPORTADOR
+const (
+	TraceExportadorNenhum = "nenhum"
+	TraceExportadorStdout = "stdout"
+	TraceExportadorOTLP   = "otlp"
+)
+
+// ConfiguracaoRastreamento define para onde e quanto rastrear
+type ConfiguracaoRastreamento struct {
+	Exportador string
+	Endpoint   string
+	Servico    string
+	Amostragem float64
+}
+
+// ObterConfiguracaoRastreamento lê TRACE_EXPORTADOR (nenhum, stdout ou otlp),
+// OTEL_EXPORTER_OTLP_ENDPOINT, OTEL_SERVICE_NAME e TRACE_AMOSTRAGEM (0 a 1)
+func ObterConfiguracaoRastreamento() (*ConfiguracaoRastreamento, error) {
+	configuracao := &ConfiguracaoRastreamento{
+		Exportador: strings.ToLower(obterVariavelOuPadrao("TRACE_EXPORTADOR", TraceExportadorNenhum)),
+		Endpoint:   obterVariavelOuPadrao("OTEL_EXPORTER_OTLP_ENDPOINT", "http://localhost:4318"),
+		Servico:    obterVariavelOuPadrao("OTEL_SERVICE_NAME", "api-filmes"),
+	}
+
+	switch configuracao.Exportador {
+	case TraceExportadorNenhum, TraceExportadorStdout, TraceExportadorOTLP:
+	default:
+		return nil, fmt.Errorf("TRACE_EXPORTADOR inválido: %q (use %q, %q ou %q)", configuracao.Exportador,
+			TraceExportadorNenhum, TraceExportadorStdout, TraceExportadorOTLP)
+	}
+
+	amostragem, err := strconv.ParseFloat(obterVariavelOuPadrao("TRACE_AMOSTRAGEM", "1"), 64)
+	if err != nil || amostragem < 0 || amostragem > 1 {
+		return nil, fmt.Errorf("TRACE_AMOSTRAGEM inválido: deve ser um número entre 0 e 1")
+	}
+	configuracao.Amostragem = amostragem
+
+	return configuracao, nil
+}
+
 // StringConexao gera a string de conexão para o PostgreSQL
 func (c *ConfiguracaoBanco) StringConexao() string {
 	return fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
@@ -0,0 +1,172 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"api-filmes/internal/models"
+	"api-filmes/internal/requisicao"
+)
+
+// emTransacao executa fn numa transação, confirmando se fn não falhar.
+// Os erros de fn são devolvidos sem tradução para que o chamador
+// reconheça sql.ErrNoRows.
+func (bd *BancoDados) emTransacao(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := bd.conexao.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// registrarAlteracao grava, na transação da escrita, o registro de
+// auditoria e, se o filme continua existindo, o snapshot da nova revisão
+func registrarAlteracao(ctx context.Context, tx *sql.Tx, operacao string, antes, depois *models.Filme) error {
+	if err := registrarAuditoria(ctx, tx, operacao, antes, depois); err != nil {
+		return err
+	}
+	if depois == nil {
+		return nil
+	}
+	return registrarVersao(ctx, tx, operacao, depois)
+}
+
+// registrarAuditoria grava a alteração do filme com o ator e o ID da
+// requisição presentes no contexto. antes é nil na criação e depois é nil
+// no expurgo.
+func registrarAuditoria(ctx context.Context, tx *sql.Tx, operacao string, antes, depois *models.Filme) error {
+	filmeID := 0
+	if depois != nil {
+		filmeID = depois.ID
+	} else if antes != nil {
+		filmeID = antes.ID
+	}
+
+	alteracoes, err := json.Marshal(models.DiferencaFilmes(antes, depois))
+	if err != nil {
+		return err
+	}
+
+	var idRequisicao *string
+	if id := requisicao.ID(ctx); id != "" {
+		idRequisicao = &id
+	}
+
+	query := `
+        INSERT INTO auditoria_filmes (filme_id, operacao, ator, id_requisicao, antes, depois, alteracoes)
+        VALUES ($1, $2, $3, $4, $5::jsonb, $6::jsonb, $7::jsonb)
+    `
+	_, err = tx.ExecContext(ctx, query,
+		filmeID,
+		operacao,
+		requisicao.Ator(ctx),
+		idRequisicao,
+		jsonOuNulo(antes),
+		jsonOuNulo(depois),
+		string(alteracoes),
+	)
+	return err
+}
+
+// jsonOuNulo serializa o filme para uma coluna JSONB (NULL se nil)
+func jsonOuNulo(filme *models.Filme) any {
+	if filme == nil {
+		return nil
+	}
+	dados, err := json.Marshal(filme)
+	if err != nil {
+		return nil
+	}
+	return string(dados)
+}
+
+// BuscarAuditoria lista os registros de auditoria do mais recente ao mais antigo
+func (bd *BancoDados) BuscarAuditoria(ctx context.Context, filtro *models.FiltroAuditoria) ([]models.RegistroAuditoria, error) {
+	consulta := &consultaFiltrada{}
+	if filtro.FilmeID != nil {
+		consulta.adicionar("filme_id = ?", *filtro.FilmeID)
+	}
+	if filtro.Ator != "" {
+		consulta.adicionar("ator = ?", filtro.Ator)
+	}
+	if filtro.Operacao != "" {
+		consulta.adicionar("operacao = ?", filtro.Operacao)
+	}
+	if filtro.Desde != nil {
+		consulta.adicionar("momento >= ?", *filtro.Desde)
+	}
+	if filtro.Ate != nil {
+		consulta.adicionar("momento <= ?", *filtro.Ate)
+	}
+
+	consulta.args = append(consulta.args, filtro.Limite, filtro.Deslocamento)
+	query := fmt.Sprintf(`
+        SELECT id, filme_id, operacao, ator, COALESCE(id_requisicao, ''), momento, antes, depois, alteracoes
+        FROM auditoria_filmes
+        %s
+        ORDER BY momento DESC, id DESC
+        LIMIT $%d OFFSET $%d
+    `, consulta.where(), len(consulta.args)-1, len(consulta.args))
+
+	linhas, err := bd.conexao.QueryContext(ctx, query, consulta.args...)
+	if err != nil {
+		return nil, traduzirErro(err, "erro ao buscar auditoria")
+	}
+	defer linhas.Close()
+
+	registros := []models.RegistroAuditoria{}
+	for linhas.Next() {
+		var registro models.RegistroAuditoria
+		var antes, depois, alteracoes []byte
+
+		err := linhas.Scan(
+			&registro.ID,
+			&registro.FilmeID,
+			&registro.Operacao,
+			&registro.Ator,
+			&registro.IDRequisicao,
+			&registro.Momento,
+			&antes,
+			&depois,
+			&alteracoes,
+		)
+		if err != nil {
+			return nil, traduzirErro(err, "erro ao ler registro de auditoria")
+		}
+
+		if err := lerJSONAuditoria(&registro, antes, depois, alteracoes); err != nil {
+			return nil, traduzirErro(err, "erro ao ler registro de auditoria")
+		}
+		registros = append(registros, registro)
+	}
+	if err := linhas.Err(); err != nil {
+		return nil, traduzirErro(err, "erro durante leitura dos resultados")
+	}
+
+	return registros, nil
+}
+
+// lerJSONAuditoria decodifica as colunas JSONB de um registro
+func lerJSONAuditoria(registro *models.RegistroAuditoria, antes, depois, alteracoes []byte) error {
+	if antes != nil {
+		registro.Antes = &models.Filme{}
+		if err := json.Unmarshal(antes, registro.Antes); err != nil {
+			return err
+		}
+	}
+	if depois != nil {
+		registro.Depois = &models.Filme{}
+		if err := json.Unmarshal(depois, registro.Depois); err != nil {
+			return err
+		}
+	}
+	return json.Unmarshal(alteracoes, &registro.Alteracoes)
+}
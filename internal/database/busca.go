@@ -0,0 +1,130 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"html"
+	"strings"
+
+	"api-filmes/internal/models"
+	"api-filmes/internal/rastreamento"
+)
+
+// Tipos de correspondência devolvidos na busca textual
+const (
+	CorrespondenciaExata      = "exata"
+	CorrespondenciaAproximada = "aproximada"
+)
+
+// Marcadores que o ts_headline põe em volta dos termos encontrados: caracteres
+// de uso privado, removidos do texto antes do destaque para que só os do
+// ts_headline existam. Viram <mark> depois que o texto é escapado.
+const (
+	inicioDestaque = "\uE000"
+	fimDestaque    = "\uE001"
+)
+
+// substitutoDestaque troca os marcadores pelas tags de destaque
+var substitutoDestaque = strings.NewReplacer(inicioDestaque, "<mark>", fimDestaque, "</mark>")
+
+// FormatarDestaque escapa o texto como HTML e troca os marcadores por
+// <mark>: a única marcação no resultado é a do destaque, e o texto vindo do
+// usuário nunca é interpretado como HTML
+func FormatarDestaque(texto string) string {
+	return substitutoDestaque.Replace(html.EscapeString(texto))
+}
+
+// BuscarFilmesPorTexto procura filmes por título, descrição e diretor.
+// Usa full-text search em português; se nada for encontrado, recorre à
+// similaridade por trigramas para tolerar erros de digitação.
+func (bd *BancoDados) BuscarFilmesPorTexto(ctx context.Context, termo string, limite int) ([]models.ResultadoBusca, error) {
+	queryTextual := `
+        SELECT id, titulo, ano_lancamento, COALESCE(genero, ''), COALESCE(diretor, ''), avaliacao,
+               ts_rank_cd(busca, consulta) AS relevancia,
+               ts_headline('pt_unaccent',
+                   translate(titulo || ' — ' || COALESCE(diretor, '') || ' — ' || COALESCE(descricao, ''),
+                             '` + inicioDestaque + fimDestaque + `', ''),
+                   consulta,
+                   'StartSel=` + inicioDestaque + `, StopSel=` + fimDestaque + `, MaxFragments=2, MinWords=5, MaxWords=20')
+        FROM filmes, websearch_to_tsquery('pt_unaccent', $1) AS consulta
+        WHERE busca @@ consulta AND deletado_em IS NULL
+        ORDER BY relevancia DESC, id ASC
+        LIMIT $2
+    `
+
+	resultados, err := bd.executarBusca(ctx, queryTextual, CorrespondenciaExata, termo, limite)
+	if err != nil || len(resultados) > 0 {
+		return resultados, err
+	}
+
+	queryAproximada := `
+        SELECT id, titulo, ano_lancamento, COALESCE(genero, ''), COALESCE(diretor, ''), avaliacao,
+               GREATEST(
+                   word_similarity(f_unaccent($1), f_unaccent(titulo)),
+                   word_similarity(f_unaccent($1), f_unaccent(COALESCE(diretor, '')))
+               ) AS relevancia,
+               titulo
+        FROM filmes
+        WHERE deletado_em IS NULL
+          AND (f_unaccent($1) <% f_unaccent(titulo)
+           OR f_unaccent($1) <% f_unaccent(COALESCE(diretor, '')))
+        ORDER BY relevancia DESC, id ASC
+        LIMIT $2
+    `
+
+	return bd.executarBusca(ctx, queryAproximada, CorrespondenciaAproximada, termo, limite)
+}
+
+// executarBusca roda uma das queries de busca e lê os resultados.
+// Cada query ganha seu próprio span para distinguir a busca exata do fallback.
+func (bd *BancoDados) executarBusca(ctx context.Context, query, correspondencia, termo string, limite int) ([]models.ResultadoBusca, error) {
+	ctx, span := rastreamento.Iniciar(ctx, "busca_"+correspondencia, rastreamento.TipoCliente,
+		rastreamento.Atributo{Chave: "db.system", Valor: "postgresql"},
+		rastreamento.Atributo{Chave: "db.statement.nome", Valor: "busca_" + correspondencia},
+	)
+	defer span.Finalizar()
+
+	linhas, err := bd.conexao.QueryContext(ctx, query, termo, limite)
+	if err != nil {
+		span.RegistrarErro(err)
+		return nil, traduzirErro(err, "erro ao executar busca")
+	}
+	defer linhas.Close()
+
+	resultados, err := lerResultadosBusca(linhas, correspondencia)
+	span.RegistrarErro(err)
+	span.DefinirAtributo("db.linhas", len(resultados))
+	return resultados, err
+}
+
+// lerResultadosBusca converte as linhas da busca em ResultadoBusca
+func lerResultadosBusca(linhas *sql.Rows, correspondencia string) ([]models.ResultadoBusca, error) {
+	resultados := []models.ResultadoBusca{}
+
+	for linhas.Next() {
+		resultado := models.ResultadoBusca{Correspondencia: correspondencia}
+
+		err := linhas.Scan(
+			&resultado.ID,
+			&resultado.Titulo,
+			&resultado.AnoLancamento,
+			&resultado.Genero,
+			&resultado.Diretor,
+			&resultado.Avaliacao,
+			&resultado.Relevancia,
+			&resultado.Destaque,
+		)
+		if err != nil {
+			return nil, traduzirErro(err, "erro ao ler resultado da busca")
+		}
+
+		resultado.Destaque = FormatarDestaque(resultado.Destaque)
+		resultados = append(resultados, resultado)
+	}
+
+	if err := linhas.Err(); err != nil {
+		return nil, traduzirErro(err, "erro durante leitura da busca")
+	}
+
+	return resultados, nil
+}
@@ -0,0 +1,25 @@
+package database
+
+import "testing"
+
+func TestFormatarDestaque(t *testing.T) {
+	casos := []struct {
+		nome     string
+		texto    string
+		esperado string
+	}{
+		{nome: "texto simples", texto: "Matrix", esperado: "Matrix"},
+		{nome: "termo destacado", texto: inicioDestaque + "Matrix" + fimDestaque + " Reloaded", esperado: "<mark>Matrix</mark> Reloaded"},
+		{nome: "HTML do usuário é escapado", texto: `<script>alert("x")</script>`, esperado: "&lt;script&gt;alert(&#34;x&#34;)&lt;/script&gt;"},
+		{nome: "tag mark do usuário não vira destaque", texto: "<mark>falso</mark> " + inicioDestaque + "real" + fimDestaque, esperado: "&lt;mark&gt;falso&lt;/mark&gt; <mark>real</mark>"},
+		{nome: "e comercial", texto: "Velozes & Furiosos", esperado: "Velozes &amp; Furiosos"},
+	}
+
+	for _, caso := range casos {
+		t.Run(caso.nome, func(t *testing.T) {
+			if resultado := FormatarDestaque(caso.texto); resultado != caso.esperado {
+				t.Errorf("FormatarDestaque(%q) = %q, esperado %q", caso.texto, resultado, caso.esperado)
+			}
+		})
+	}
+}
@@ -1,9 +1,12 @@
 package database
 
 import (
+	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
-	"strings"
+	"log/slog"
 	"time"
 
 	"api-filmes/internal/config"
@@ -19,23 +22,27 @@ type BancoDados struct {
 func NovaConexao() (*BancoDados, error) {
 	configuracao := config.ObterConfiguracaoBanco()
 
-	fmt.Println("🔌 Conectando ao banco de dados...")
-	fmt.Printf("📍 Host: %s:%s | Banco: %s\n",
-		configuracao.Host, configuracao.Porta, configuracao.NomeBanco)
+	slog.Info("conectando ao banco de dados",
+		"host", configuracao.Host, "porta", configuracao.Porta, "banco", configuracao.NomeBanco)
 
 	conexao, err := sql.Open("postgres", configuracao.StringConexao())
 	if err != nil {
-		return nil, fmt.Errorf("erro ao abrir conexão: %v", err)
+		return nil, traduzirErro(err, "erro ao abrir conexão")
 	}
 
 	if err := conexao.Ping(); err != nil {
-		return nil, fmt.Errorf("erro ao conectar com banco: %v", err)
+		return nil, traduzirErro(err, "erro ao conectar com banco")
 	}
 
-	fmt.Println("✅ Conexão com banco estabelecida com sucesso!")
+	slog.Info("conexão com banco estabelecida")
 	return &BancoDados{conexao: conexao}, nil
 }
 
+// Conexao expõe o pool de conexões para componentes como o migrador
+func (bd *BancoDados) Conexao() *sql.DB {
+	return bd.conexao
+}
+
 func (bd *BancoDados) Fechar() error {
 	if bd.conexao != nil {
 		return bd.conexao.Close()
@@ -44,20 +51,33 @@ func (bd *BancoDados) Fechar() error {
 }
 
 // Operações de leitura (já existentes)
-func (bd *BancoDados) BuscarTodosFilmes() ([]models.FilmeResumo, error) {
-	query := `
-        SELECT id, titulo, ano_lancamento, genero, diretor, avaliacao 
+
+// BuscarTodosFilmes retorna uma página de filmes respeitando filtros e ordenação
+func (bd *BancoDados) BuscarTodosFilmes(ctx context.Context, filtro *models.FiltroFilmes) ([]models.FilmeResumo, error) {
+	consulta := montarFiltroFilmes(filtro)
+	aplicarCursor(consulta, filtro)
+
+	paginacao := ""
+	if filtro != nil && filtro.Limite > 0 {
+		consulta.args = append(consulta.args, filtro.Limite, filtro.Deslocamento)
+		paginacao = fmt.Sprintf("LIMIT $%d OFFSET $%d", len(consulta.args)-1, len(consulta.args))
+	}
+
+	query := fmt.Sprintf(`
+        SELECT id, titulo, ano_lancamento, COALESCE(genero, ''), COALESCE(diretor, ''), avaliacao
         FROM filmes 
-        ORDER BY id ASC
-    `
+        %s
+        %s
+        %s
+    `, consulta.where(), ordenacaoFilmes(filtro), paginacao)
 
-	linhas, err := bd.conexao.Query(query)
+	linhas, err := bd.conexao.QueryContext(ctx, query, consulta.args...)
 	if err != nil {
-		return nil, fmt.Errorf("erro ao executar query: %v", err)
+		return nil, traduzirErro(err, "erro ao executar query")
 	}
 	defer linhas.Close()
 
-	var filmes []models.FilmeResumo
+	filmes := []models.FilmeResumo{}
 
 	for linhas.Next() {
 		var filme models.FilmeResumo
@@ -72,59 +92,125 @@ func (bd *BancoDados) BuscarTodosFilmes() ([]models.FilmeResumo, error) {
 		)
 
 		if err != nil {
-			return nil, fmt.Errorf("erro ao ler dados do filme: %v", err)
+			return nil, traduzirErro(err, "erro ao ler dados do filme")
 		}
 
 		filmes = append(filmes, filme)
 	}
 
 	if err := linhas.Err(); err != nil {
-		return nil, fmt.Errorf("erro durante leitura dos resultados: %v", err)
+		return nil, traduzirErro(err, "erro durante leitura dos resultados")
 	}
 
 	return filmes, nil
 }
 
-func (bd *BancoDados) BuscarFilmePorID(id int) (*models.Filme, error) {
-	query := `
-        SELECT id, titulo, descricao, ano_lancamento, duracao_minutos, 
-               genero, diretor, avaliacao, data_criacao, data_atualizacao
-        FROM filmes 
-        WHERE id = $1
-    `
+func (bd *BancoDados) BuscarFilmePorID(ctx context.Context, id int) (*models.Filme, error) {
+	query := "SELECT " + colunasFilme + " FROM filmes WHERE id = $1 AND deletado_em IS NULL"
 
-	var filme models.Filme
+	filme, err := escanearFilme(bd.conexao.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, filmeNaoEncontrado(id)
+		}
+		return nil, traduzirErro(err, "erro ao buscar filme")
+	}
 
-	err := bd.conexao.QueryRow(query, id).Scan(
+	return filme, nil
+}
+
+// colunasFilme lista, na ordem lida por escanearFilme, as colunas de um
+// models.Filme; usada em SELECT e em RETURNING sobre a tabela filmes
+var colunasFilme = colunasFilmeDe("filmes.")
+
+// colunasFilmeDe qualifica as colunas de colunasFilme com o alias da
+// tabela (ex.: "antigo."), para consultas que leem duas versões da linha.
+// Gêneros e diretores vêm de subconsultas agregadas em JSON, na ordem do filme.
+func colunasFilmeDe(alias string) string {
+	return fmt.Sprintf(`%[1]sid, %[1]stitulo, COALESCE(%[1]sdescricao, ''), %[1]sano_lancamento,
+        COALESCE(%[1]sduracao_minutos, 0), COALESCE(%[1]sgenero, ''),
+        (SELECT COALESCE(json_agg(json_build_object('slug', g.slug, 'nome', g.nome) ORDER BY fg.posicao), '[]')
+            FROM filme_generos fg JOIN generos g ON g.id = fg.genero_id
+            WHERE fg.filme_id = %[1]sid),
+        COALESCE(%[1]sdiretor, ''),
+        (SELECT COALESCE(json_agg(json_build_object('id', p.id, 'nome', p.nome) ORDER BY fd.posicao), '[]')
+            FROM filme_diretores fd JOIN pessoas p ON p.id = fd.pessoa_id
+            WHERE fd.filme_id = %[1]sid),
+        %[1]savaliacao, %[1]sdata_criacao, %[1]sdata_atualizacao, %[1]sversao, %[1]sdeletado_em`, alias)
+}
+
+// linhaEscaneavel é satisfeita por *sql.Row e *sql.Rows
+type linhaEscaneavel interface {
+	Scan(destino ...any) error
+}
+
+// destinosFilme aponta para os campos do filme na ordem de colunasFilme
+func destinosFilme(filme *models.Filme) []any {
+	return []any{
 		&filme.ID,
 		&filme.Titulo,
 		&filme.Descricao,
 		&filme.AnoLancamento,
 		&filme.DuracaoMinutos,
 		&filme.Genero,
+		colunaJSON{&filme.Generos},
 		&filme.Diretor,
+		colunaJSON{&filme.Diretores},
 		&filme.Avaliacao,
 		&filme.DataCriacao,
 		&filme.DataAtualizacao,
-	)
+		&filme.Versao,
+		&filme.DeletadoEm,
+	}
+}
 
-	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("filme com ID %d não encontrado", id)
-		}
-		return nil, fmt.Errorf("erro ao buscar filme: %v", err)
+// colunaJSON lê uma coluna json (as listas agregadas de colunasFilme)
+type colunaJSON struct {
+	destino any
+}
+
+// Scan implementa sql.Scanner
+func (c colunaJSON) Scan(valor any) error {
+	switch v := valor.(type) {
+	case nil:
+		return nil
+	case []byte:
+		return json.Unmarshal(v, c.destino)
+	case string:
+		return json.Unmarshal([]byte(v), c.destino)
+	default:
+		return fmt.Errorf("tipo inesperado para coluna json: %T", valor)
 	}
+}
 
+// escanearFilme lê uma linha com as colunas de colunasFilme
+func escanearFilme(linha linhaEscaneavel) (*models.Filme, error) {
+	var filme models.Filme
+	if err := linha.Scan(destinosFilme(&filme)...); err != nil {
+		return nil, err
+	}
 	return &filme, nil
 }
 
-func (bd *BancoDados) ContarFilmes() (int, error) {
+// escanearAntesDepois lê uma linha com as colunas da versão anterior
+// seguidas das colunas da versão nova do filme
+func escanearAntesDepois(linha linhaEscaneavel) (antes, depois *models.Filme, err error) {
+	antes, depois = &models.Filme{}, &models.Filme{}
+	if err := linha.Scan(append(destinosFilme(antes), destinosFilme(depois)...)...); err != nil {
+		return nil, nil, err
+	}
+	return antes, depois, nil
+}
+
+// ContarFilmes conta os filmes que atendem ao filtro (ignorando paginação)
+func (bd *BancoDados) ContarFilmes(ctx context.Context, filtro *models.FiltroFilmes) (int, error) {
 	var total int
-	query := "SELECT COUNT(*) FROM filmes"
-	err := bd.conexao.QueryRow(query).Scan(&total)
+	consulta := montarFiltroFilmes(filtro)
+	query := "SELECT COUNT(*) FROM filmes " + consulta.where()
+	err := bd.conexao.QueryRowContext(ctx, query, consulta.args...).Scan(&total)
 
 	if err != nil {
-		return 0, fmt.Errorf("erro ao contar filmes: %v", err)
+		return 0, traduzirErro(err, "erro ao contar filmes")
 	}
 
 	return total, nil
@@ -132,172 +218,297 @@ func (bd *BancoDados) ContarFilmes() (int, error) {
 
 // ✨ NOVAS OPERAÇÕES DE ESCRITA
 
-// CriarFilme insere um novo filme no banco
-func (bd *BancoDados) CriarFilme(filme *models.FilmeParaCriar) (*models.Filme, error) {
+// CriarFilme insere um novo filme no banco e registra a criação na auditoria
+func (bd *BancoDados) CriarFilme(ctx context.Context, filme *models.FilmeParaCriar) (*models.Filme, error) {
 	query := `
         INSERT INTO filmes (titulo, descricao, ano_lancamento, duracao_minutos, genero, diretor, avaliacao)
         VALUES ($1, $2, $3, $4, $5, $6, $7)
-        RETURNING id, data_criacao, data_atualizacao
-    `
-
-	var novoFilme models.Filme
+        RETURNING ` + colunasFilme
 
-	// Mapear dados de entrada para filme completo
-	novoFilme.Titulo = filme.Titulo
-	novoFilme.AnoLancamento = filme.AnoLancamento
+	var novoFilme *models.Filme
+	err := bd.emTransacao(ctx, func(tx *sql.Tx) error {
+		generos, genero, err := resolverGeneros(ctx, tx, filme)
+		if err != nil {
+			return err
+		}
+		diretores, diretor, err := resolverDiretores(ctx, tx, filme)
+		if err != nil {
+			return err
+		}
 
-	// Tratar campos opcionais
-	var descricao, genero, diretor *string
-	var duracaoMinutos *int
-	var avaliacao *float64
+		novoFilme, err = escanearFilme(tx.QueryRowContext(ctx, query,
+			filme.Titulo,
+			filme.Descricao,
+			filme.AnoLancamento,
+			filme.DuracaoMinutos,
+			genero,
+			diretor,
+			filme.Avaliacao,
+		))
+		if err != nil {
+			return err
+		}
 
-	if filme.Descricao != nil {
-		descricao = filme.Descricao
-		novoFilme.Descricao = *filme.Descricao
-	}
-	if filme.Genero != nil {
-		genero = filme.Genero
-		novoFilme.Genero = *filme.Genero
-	}
-	if filme.Diretor != nil {
-		diretor = filme.Diretor
-		novoFilme.Diretor = *filme.Diretor
-	}
-	if filme.DuracaoMinutos != nil {
-		duracaoMinutos = filme.DuracaoMinutos
-		novoFilme.DuracaoMinutos = *filme.DuracaoMinutos
-	}
-	if filme.Avaliacao != nil {
-		avaliacao = filme.Avaliacao
-		novoFilme.Avaliacao = *filme.Avaliacao
+		if err := vincularGeneros(ctx, tx, novoFilme, generos); err != nil {
+			return err
+		}
+		if err := vincularDiretores(ctx, tx, novoFilme, diretores); err != nil {
+			return err
+		}
+		return registrarAlteracao(ctx, tx, models.OperacaoCriar, nil, novoFilme)
+	})
+	if err != nil {
+		return nil, traduzirErro(err, "erro ao criar filme")
 	}
 
-	// Executar inserção
-	err := bd.conexao.QueryRow(
-		query,
-		filme.Titulo,
-		descricao,
-		filme.AnoLancamento,
-		duracaoMinutos,
-		genero,
-		diretor,
-		avaliacao,
-	).Scan(&novoFilme.ID, &novoFilme.DataCriacao, &novoFilme.DataAtualizacao)
+	return novoFilme, nil
+}
+
+// alterarFilme executa alterarFilmeTx numa transação própria e registra a
+// auditoria na mesma transação
+func (bd *BancoDados) alterarFilme(ctx context.Context, operacao, condicao, set string, args ...any) (*models.Filme, error) {
+	var depois *models.Filme
+	err := bd.emTransacao(ctx, func(tx *sql.Tx) error {
+		antes, novo, err := alterarFilmeTx(ctx, tx, condicao, set, args...)
+		if err != nil {
+			return err
+		}
+		depois = novo
+		return registrarAlteracao(ctx, tx, operacao, antes, depois)
+	})
+	return depois, err
+}
+
+// alterarFilmeTx executa um UPDATE que lê a versão anterior da linha (travada
+// com FOR UPDATE) e a nova no mesmo comando. condicao filtra a linha antiga;
+// set usa os argumentos a partir do primeiro que condicao não consome.
+func alterarFilmeTx(ctx context.Context, tx *sql.Tx, condicao, set string, args ...any) (antes, depois *models.Filme, err error) {
+	query := `
+        UPDATE filmes AS f
+        SET ` + set + `
+        FROM (SELECT * FROM filmes WHERE ` + condicao + ` FOR UPDATE) AS antigo
+        WHERE f.id = antigo.id
+        RETURNING ` + colunasFilmeDe("antigo.") + `, ` + colunasFilmeDe("f.")
+
+	return escanearAntesDepois(tx.QueryRowContext(ctx, query, args...))
+}
+
+// AtualizarFilme substitui todos os campos editáveis de um filme existente.
+// Campos opcionais nil são gravados como NULL. Verificação de versão, escrita
+// e leitura do resultado acontecem num único UPDATE ... RETURNING.
+func (bd *BancoDados) AtualizarFilme(ctx context.Context, id int, filme *models.FilmeParaCriar, versao int) (*models.Filme, error) {
+	return bd.substituirFilme(ctx, models.OperacaoAtualizar, id, filme, versao)
+}
+
+// substituirFilme grava todos os campos editáveis, os gêneros e os diretores,
+// registrando a operação informada (atualizar ou reverter) na auditoria e no
+// histórico de versões
+func (bd *BancoDados) substituirFilme(ctx context.Context, operacao string, id int, filme *models.FilmeParaCriar, versao int) (*models.Filme, error) {
+	var atualizado *models.Filme
+	err := bd.emTransacao(ctx, func(tx *sql.Tx) error {
+		generos, genero, err := resolverGeneros(ctx, tx, filme)
+		if err != nil {
+			return err
+		}
+		diretores, diretor, err := resolverDiretores(ctx, tx, filme)
+		if err != nil {
+			return err
+		}
 
+		antes, depois, err := alterarFilmeTx(ctx, tx,
+			"id = $1 AND deletado_em IS NULL AND ($2::int = 0 OR versao = $2)",
+			`titulo = $3, descricao = $4, ano_lancamento = $5, duracao_minutos = $6,
+                genero = $7, diretor = $8, avaliacao = $9, data_atualizacao = $10,
+                versao = f.versao + 1`,
+			id,
+			versao,
+			filme.Titulo,
+			filme.Descricao,
+			filme.AnoLancamento,
+			filme.DuracaoMinutos,
+			genero,
+			diretor,
+			filme.Avaliacao,
+			time.Now(),
+		)
+		if err != nil {
+			return err
+		}
+
+		if err := vincularGeneros(ctx, tx, depois, generos); err != nil {
+			return err
+		}
+		if err := vincularDiretores(ctx, tx, depois, diretores); err != nil {
+			return err
+		}
+		atualizado = depois
+		return registrarAlteracao(ctx, tx, operacao, antes, depois)
+	})
 	if err != nil {
-		return nil, fmt.Errorf("erro ao criar filme: %v", err)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, bd.nenhumaLinhaAfetada(ctx, id, versao)
+		}
+		return nil, traduzirErro(err, "erro ao atualizar filme")
 	}
 
-	return &novoFilme, nil
+	return atualizado, nil
 }
 
-// AtualizarFilme atualiza um filme existente
-func (bd *BancoDados) AtualizarFilme(id int, filme *models.FilmeParaAtualizar) (*models.Filme, error) {
-	// Primeiro, verificar se filme existe
-	filmeExistente, err := bd.BuscarFilmePorID(id)
+// DeletarFilme move um filme para a lixeira, opcionalmente só se estiver na
+// versão informada. A linha permanece até ser restaurada ou expurgada.
+func (bd *BancoDados) DeletarFilme(ctx context.Context, id int, versao int) error {
+	_, err := bd.alterarFilme(ctx, models.OperacaoDeletar,
+		"id = $1 AND deletado_em IS NULL AND ($2::int = 0 OR versao = $2)",
+		"deletado_em = $3, versao = f.versao + 1",
+		id,
+		versao,
+		time.Now(),
+	)
 	if err != nil {
-		return nil, err
+		if errors.Is(err, sql.ErrNoRows) {
+			return bd.nenhumaLinhaAfetada(ctx, id, versao)
+		}
+		return traduzirErro(err, "erro ao deletar filme")
 	}
 
-	// Construir query dinâmica baseada nos campos fornecidos
-	setParts := []string{}
-	args := []interface{}{}
-	argIndex := 1
+	return nil
+}
 
-	if filme.Titulo != nil {
-		setParts = append(setParts, fmt.Sprintf("titulo = $%d", argIndex))
-		args = append(args, *filme.Titulo)
-		argIndex++
+// nenhumaLinhaAfetada explica por que um UPDATE/DELETE condicional não
+// alterou nada. Sem versão esperada só pode ser ausência do filme; com ela,
+// uma consulta extra (apenas neste caminho de falha) separa 404 de 412.
+func (bd *BancoDados) nenhumaLinhaAfetada(ctx context.Context, id, versao int) error {
+	if versao == QualquerVersao {
+		return filmeNaoEncontrado(id)
 	}
 
-	if filme.Descricao != nil {
-		setParts = append(setParts, fmt.Sprintf("descricao = $%d", argIndex))
-		args = append(args, *filme.Descricao)
-		argIndex++
+	var existe bool
+	query := "SELECT EXISTS (SELECT 1 FROM filmes WHERE id = $1 AND deletado_em IS NULL)"
+	if err := bd.conexao.QueryRowContext(ctx, query, id).Scan(&existe); err != nil {
+		return traduzirErro(err, "erro ao verificar filme")
 	}
-
-	if filme.AnoLancamento != nil {
-		setParts = append(setParts, fmt.Sprintf("ano_lancamento = $%d", argIndex))
-		args = append(args, *filme.AnoLancamento)
-		argIndex++
+	if !existe {
+		return filmeNaoEncontrado(id)
 	}
+	return versaoDivergente(id, versao)
+}
 
-	if filme.DuracaoMinutos != nil {
-		setParts = append(setParts, fmt.Sprintf("duracao_minutos = $%d", argIndex))
-		args = append(args, *filme.DuracaoMinutos)
-		argIndex++
-	}
+// ListarLixeira retorna os filmes excluídos, dos mais recentes aos mais antigos
+func (bd *BancoDados) ListarLixeira(ctx context.Context, limite, deslocamento int) ([]models.Filme, error) {
+	query := "SELECT " + colunasFilme + `
+        FROM filmes
+        WHERE deletado_em IS NOT NULL
+        ORDER BY deletado_em DESC, id DESC
+        LIMIT $1 OFFSET $2`
 
-	if filme.Genero != nil {
-		setParts = append(setParts, fmt.Sprintf("genero = $%d", argIndex))
-		args = append(args, *filme.Genero)
-		argIndex++
+	linhas, err := bd.conexao.QueryContext(ctx, query, limite, deslocamento)
+	if err != nil {
+		return nil, traduzirErro(err, "erro ao listar lixeira")
 	}
+	defer linhas.Close()
 
-	if filme.Diretor != nil {
-		setParts = append(setParts, fmt.Sprintf("diretor = $%d", argIndex))
-		args = append(args, *filme.Diretor)
-		argIndex++
+	filmes := []models.Filme{}
+	for linhas.Next() {
+		filme, err := escanearFilme(linhas)
+		if err != nil {
+			return nil, traduzirErro(err, "erro ao ler dados do filme")
+		}
+		filmes = append(filmes, *filme)
 	}
-
-	if filme.Avaliacao != nil {
-		setParts = append(setParts, fmt.Sprintf("avaliacao = $%d", argIndex))
-		args = append(args, *filme.Avaliacao)
-		argIndex++
+	if err := linhas.Err(); err != nil {
+		return nil, traduzirErro(err, "erro durante leitura dos resultados")
 	}
 
-	// Se nenhum campo foi fornecido, retornar filme existente
-	if len(setParts) == 0 {
-		return filmeExistente, nil
-	}
+	return filmes, nil
+}
 
-	// Adicionar atualização automática do timestamp
-	setParts = append(setParts, fmt.Sprintf("data_atualizacao = $%d", argIndex))
-	args = append(args, time.Now())
-	argIndex++
+// RestaurarFilme tira o filme da lixeira
+func (bd *BancoDados) RestaurarFilme(ctx context.Context, id int) (*models.Filme, error) {
+	filme, err := bd.alterarFilme(ctx, models.OperacaoRestaurar,
+		"id = $1 AND deletado_em IS NOT NULL",
+		"deletado_em = NULL, versao = f.versao + 1, data_atualizacao = $2",
+		id,
+		time.Now(),
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, filmeForaDaLixeira(id)
+		}
+		return nil, traduzirErro(err, "erro ao restaurar filme")
+	}
 
-	// Adicionar WHERE clause
-	args = append(args, id)
+	return filme, nil
+}
 
-	query := fmt.Sprintf(`
-        UPDATE filmes 
-        SET %s 
-        WHERE id = $%d
-    `, strings.Join(setParts, ", "), argIndex)
+// ExpurgarFilme apaga definitivamente um filme que está na lixeira
+func (bd *BancoDados) ExpurgarFilme(ctx context.Context, id int) error {
+	query := "DELETE FROM filmes WHERE id = $1 AND deletado_em IS NOT NULL RETURNING " + colunasFilme
 
-	_, err = bd.conexao.Exec(query, args...)
+	err := bd.emTransacao(ctx, func(tx *sql.Tx) error {
+		removido, err := escanearFilme(tx.QueryRowContext(ctx, query, id))
+		if err != nil {
+			return err
+		}
+		return registrarAlteracao(ctx, tx, models.OperacaoExpurgar, removido, nil)
+	})
 	if err != nil {
-		return nil, fmt.Errorf("erro ao atualizar filme: %v", err)
+		if errors.Is(err, sql.ErrNoRows) {
+			return filmeForaDaLixeira(id)
+		}
+		return traduzirErro(err, "erro ao expurgar filme")
 	}
 
-	// Retornar filme atualizado
-	return bd.BuscarFilmePorID(id)
+	return nil
 }
 
-// DeletarFilme remove um filme do banco
-func (bd *BancoDados) DeletarFilme(id int) error {
-	// Primeiro verificar se filme existe
-	_, err := bd.BuscarFilmePorID(id)
-	if err != nil {
-		return err // Já retorna erro adequado (não encontrado ou erro de banco)
-	}
+// ExpurgarLixeira apaga definitivamente os filmes excluídos antes de antesDe,
+// registrando cada um na auditoria
+func (bd *BancoDados) ExpurgarLixeira(ctx context.Context, antesDe time.Time) (int, error) {
+	query := "DELETE FROM filmes WHERE deletado_em < $1 RETURNING " + colunasFilme
 
-	query := "DELETE FROM filmes WHERE id = $1"
+	var removidos []*models.Filme
+	err := bd.emTransacao(ctx, func(tx *sql.Tx) error {
+		linhas, err := tx.QueryContext(ctx, query, antesDe)
+		if err != nil {
+			return err
+		}
+		defer linhas.Close()
+
+		for linhas.Next() {
+			filme, err := escanearFilme(linhas)
+			if err != nil {
+				return err
+			}
+			removidos = append(removidos, filme)
+		}
+		if err := linhas.Err(); err != nil {
+			return err
+		}
+		linhas.Close()
 
-	result, err := bd.conexao.Exec(query, id)
+		for _, filme := range removidos {
+			if err := registrarAlteracao(ctx, tx, models.OperacaoExpurgar, filme, nil); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
 	if err != nil {
-		return fmt.Errorf("erro ao deletar filme: %v", err)
+		return 0, traduzirErro(err, "erro ao expurgar lixeira")
 	}
 
-	// Verificar se alguma linha foi afetada
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		return fmt.Errorf("erro ao verificar deleção: %v", err)
-	}
+	return len(removidos), nil
+}
 
-	if rowsAffected == 0 {
-		return fmt.Errorf("filme com ID %d não foi deletado", id)
+// Ping verifica se o banco responde dentro do prazo do contexto
+func (bd *BancoDados) Ping(ctx context.Context) error {
+	if err := bd.conexao.PingContext(ctx); err != nil {
+		return traduzirErro(err, "erro ao verificar banco")
 	}
-
 	return nil
 }
+
+// Estatisticas retorna o estado atual do pool de conexões
+func (bd *BancoDados) Estatisticas() sql.DBStats {
+	return bd.conexao.Stats()
+}
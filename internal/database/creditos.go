@@ -0,0 +1,177 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"api-filmes/internal/models"
+)
+
+// garantirFilmeAtivo retorna filmeNaoEncontrado para filmes inexistentes ou
+// na lixeira; com FOR SHARE, impede que o filme seja apagado até o fim da transação
+func garantirFilmeAtivo(ctx context.Context, tx *sql.Tx, id int) error {
+	var existe int
+	err := tx.QueryRowContext(ctx, "SELECT 1 FROM filmes WHERE id = $1 AND deletado_em IS NULL FOR SHARE", id).Scan(&existe)
+	if errors.Is(err, sql.ErrNoRows) {
+		return filmeNaoEncontrado(id)
+	}
+	return err
+}
+
+// ListarCreditos retorna os créditos do filme na ordem de exibição,
+// opcionalmente só os de uma função
+func (bd *BancoDados) ListarCreditos(ctx context.Context, filmeID int, funcao string) ([]models.Credito, error) {
+	query := `
+        SELECT c.id, p.id, p.nome, c.funcao, COALESCE(c.personagem, ''), c.posicao
+        FROM creditos c
+        JOIN pessoas p ON p.id = c.pessoa_id
+        JOIN filmes f ON f.id = c.filme_id
+        WHERE c.filme_id = $1 AND f.deletado_em IS NULL AND ($2 = '' OR c.funcao = $2)
+        ORDER BY c.posicao, c.id
+    `
+
+	linhas, err := bd.conexao.QueryContext(ctx, query, filmeID, funcao)
+	if err != nil {
+		return nil, traduzirErro(err, "erro ao listar créditos")
+	}
+	defer linhas.Close()
+
+	creditos := []models.Credito{}
+	for linhas.Next() {
+		var credito models.Credito
+		err := linhas.Scan(&credito.ID, &credito.Pessoa.ID, &credito.Pessoa.Nome,
+			&credito.Funcao, &credito.Personagem, &credito.Posicao)
+		if err != nil {
+			return nil, traduzirErro(err, "erro ao ler dados do crédito")
+		}
+		creditos = append(creditos, credito)
+	}
+	if err := linhas.Err(); err != nil {
+		return nil, traduzirErro(err, "erro durante leitura dos resultados")
+	}
+
+	// Lista vazia: só neste caso é preciso distinguir filme sem créditos de filme inexistente
+	if len(creditos) == 0 {
+		if _, err := bd.BuscarFilmePorID(ctx, filmeID); err != nil {
+			return nil, err
+		}
+	}
+
+	return creditos, nil
+}
+
+// CriarCredito adiciona um crédito ao filme. Sem posição informada, o
+// crédito entra depois do último.
+func (bd *BancoDados) CriarCredito(ctx context.Context, filmeID int, credito *models.CreditoParaCriar) (*models.Credito, error) {
+	query := `
+        INSERT INTO creditos (filme_id, pessoa_id, funcao, personagem, posicao)
+        VALUES ($1, $2, $3, $4,
+            COALESCE($5::int, (SELECT COALESCE(MAX(posicao), 0) + 1 FROM creditos WHERE filme_id = $1)))
+        RETURNING id, posicao
+    `
+
+	novo := &models.Credito{Funcao: credito.Funcao}
+	if credito.Personagem != nil {
+		novo.Personagem = *credito.Personagem
+	}
+
+	err := bd.emTransacao(ctx, func(tx *sql.Tx) error {
+		if err := garantirFilmeAtivo(ctx, tx, filmeID); err != nil {
+			return err
+		}
+
+		var err error
+		if credito.PessoaID != nil {
+			novo.Pessoa, err = pessoaDoCredito(ctx, tx, *credito.PessoaID)
+		} else {
+			novo.Pessoa, err = acharOuCriarPessoa(ctx, tx, credito.Nome)
+		}
+		if err != nil {
+			return err
+		}
+
+		return tx.QueryRowContext(ctx, query,
+			filmeID,
+			novo.Pessoa.ID,
+			credito.Funcao,
+			credito.Personagem,
+			credito.Posicao,
+		).Scan(&novo.ID, &novo.Posicao)
+	})
+	if err != nil {
+		return nil, traduzirErro(err, "erro ao criar crédito")
+	}
+
+	return novo, nil
+}
+
+// pessoaDoCredito busca a pessoa referenciada por pessoa_id
+func pessoaDoCredito(ctx context.Context, tx *sql.Tx, id int) (models.PessoaResumo, error) {
+	pessoa := models.PessoaResumo{ID: id}
+	err := tx.QueryRowContext(ctx, "SELECT nome FROM pessoas WHERE id = $1", id).Scan(&pessoa.Nome)
+	if errors.Is(err, sql.ErrNoRows) {
+		return pessoa, pessoaInexistente(id)
+	}
+	return pessoa, err
+}
+
+// DeletarCredito remove um crédito do filme
+func (bd *BancoDados) DeletarCredito(ctx context.Context, filmeID, id int) error {
+	resultado, err := bd.conexao.ExecContext(ctx, "DELETE FROM creditos WHERE id = $1 AND filme_id = $2", id, filmeID)
+	if err != nil {
+		return traduzirErro(err, "erro ao deletar crédito")
+	}
+
+	linhas, err := resultado.RowsAffected()
+	if err != nil {
+		return traduzirErro(err, "erro ao deletar crédito")
+	}
+	if linhas == 0 {
+		return creditoNaoEncontrado(filmeID, id)
+	}
+	return nil
+}
+
+// Filmografia lista as participações da pessoa em filmes ativos, incluindo
+// a direção, do filme mais antigo ao mais recente
+func (bd *BancoDados) Filmografia(ctx context.Context, pessoaID int) ([]models.ItemFilmografia, error) {
+	if _, err := bd.BuscarPessoaPorID(ctx, pessoaID); err != nil {
+		return nil, err
+	}
+
+	query := `
+        SELECT f.id, f.titulo, f.ano_lancamento, 'diretor', '', 0
+        FROM filme_diretores fd
+        JOIN filmes f ON f.id = fd.filme_id
+        WHERE fd.pessoa_id = $1 AND f.deletado_em IS NULL
+        UNION ALL
+        SELECT f.id, f.titulo, f.ano_lancamento, c.funcao, COALESCE(c.personagem, ''), c.posicao
+        FROM creditos c
+        JOIN filmes f ON f.id = c.filme_id
+        WHERE c.pessoa_id = $1 AND f.deletado_em IS NULL
+        ORDER BY 3, 1, 6
+    `
+
+	linhas, err := bd.conexao.QueryContext(ctx, query, pessoaID)
+	if err != nil {
+		return nil, traduzirErro(err, "erro ao buscar filmografia")
+	}
+	defer linhas.Close()
+
+	filmografia := []models.ItemFilmografia{}
+	for linhas.Next() {
+		var item models.ItemFilmografia
+		var posicao int
+		err := linhas.Scan(&item.FilmeID, &item.Titulo, &item.AnoLancamento, &item.Funcao, &item.Personagem, &posicao)
+		if err != nil {
+			return nil, traduzirErro(err, "erro ao ler filmografia")
+		}
+		filmografia = append(filmografia, item)
+	}
+	if err := linhas.Err(); err != nil {
+		return nil, traduzirErro(err, "erro durante leitura dos resultados")
+	}
+
+	return filmografia, nil
+}
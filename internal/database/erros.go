@@ -0,0 +1,221 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// Erros sentinela do domínio. Use errors.Is para identificar o tipo de falha
+// sem depender do texto da mensagem.
+var (
+	ErrNaoEncontrado = errors.New("registro não encontrado")
+	ErrConflito      = errors.New("conflito com registro existente")
+	ErrRestricao     = errors.New("violação de restrição do banco")
+	ErrValidacao     = errors.New("dados inválidos para o banco")
+	ErrIndisponivel  = errors.New("banco de dados indisponível")
+	ErrPrecondicao   = errors.New("versão do registro não confere")
+)
+
+// ErroBanco descreve uma falha do repositório com seu tipo (um dos erros
+// sentinela), uma mensagem segura para o cliente e a causa original
+type ErroBanco struct {
+	Tipo      error
+	Mensagem  string
+	Restricao string
+	Causa     error
+}
+
+func (e *ErroBanco) Error() string {
+	if e.Causa != nil {
+		return fmt.Sprintf("%s: %v", e.Mensagem, e.Causa)
+	}
+	return e.Mensagem
+}
+
+// Unwrap permite que errors.Is encontre tanto o tipo quanto a causa
+func (e *ErroBanco) Unwrap() []error {
+	if e.Causa != nil {
+		return []error{e.Tipo, e.Causa}
+	}
+	return []error{e.Tipo}
+}
+
+// filmeNaoEncontrado cria o erro padrão para IDs inexistentes
+func filmeNaoEncontrado(id int) error {
+	return &ErroBanco{
+		Tipo:     ErrNaoEncontrado,
+		Mensagem: fmt.Sprintf("filme com ID %d não encontrado", id),
+	}
+}
+
+// filmeForaDaLixeira é o erro de restauração/expurgo de um ID que não está na lixeira
+func filmeForaDaLixeira(id int) error {
+	return &ErroBanco{
+		Tipo:     ErrNaoEncontrado,
+		Mensagem: fmt.Sprintf("filme com ID %d não está na lixeira", id),
+	}
+}
+
+// versaoDivergente indica que o filme foi alterado depois da versão esperada
+func versaoDivergente(id, versao int) error {
+	return &ErroBanco{
+		Tipo:     ErrPrecondicao,
+		Mensagem: fmt.Sprintf("filme com ID %d não está mais na versão %d", id, versao),
+	}
+}
+
+// Códigos SQLSTATE do PostgreSQL tratados explicitamente
+const (
+	codigoViolacaoUnica            = "23505"
+	codigoViolacaoCheck            = "23514"
+	codigoViolacaoChaveEstrangeira = "23503"
+	codigoViolacaoNotNull          = "23502"
+)
+
+// traduzirErro converte erros do driver em ErroBanco com o tipo adequado.
+// contexto descreve a operação ("erro ao criar filme") e prefixa a mensagem.
+func traduzirErro(err error, contexto string) error {
+	if err == nil {
+		return nil
+	}
+
+	// Cancelamentos e timeouts seguem como estão para o handler tratar
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("%s: %w", contexto, err)
+	}
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return &ErroBanco{Tipo: ErrNaoEncontrado, Mensagem: "registro não encontrado", Causa: err}
+	}
+
+	var erroPq *pq.Error
+	if errors.As(err, &erroPq) {
+		return traduzirErroPostgres(erroPq, contexto)
+	}
+
+	var erroRede net.Error
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, sql.ErrConnDone) || errors.As(err, &erroRede) {
+		return &ErroBanco{Tipo: ErrIndisponivel, Mensagem: contexto, Causa: err}
+	}
+
+	return fmt.Errorf("%s: %w", contexto, err)
+}
+
+// traduzirErroPostgres classifica um *pq.Error pelo código SQLSTATE
+func traduzirErroPostgres(erroPq *pq.Error, contexto string) error {
+	erro := &ErroBanco{Mensagem: contexto, Restricao: erroPq.Constraint, Causa: erroPq}
+
+	switch {
+	case erroPq.Code == codigoViolacaoUnica:
+		erro.Tipo = ErrConflito
+		erro.Mensagem = "já existe um registro com estes dados"
+	case erroPq.Code == codigoViolacaoCheck,
+		erroPq.Code == codigoViolacaoChaveEstrangeira,
+		erroPq.Code == codigoViolacaoNotNull:
+		erro.Tipo = ErrRestricao
+		erro.Mensagem = mensagemRestricao(erroPq)
+	case erroPq.Code.Class() == "22":
+		// data_exception: valor fora do intervalo, texto longo demais etc.
+		erro.Tipo = ErrValidacao
+		erro.Mensagem = "valor inválido: " + erroPq.Message
+	case erroPq.Code.Class() == "08", erroPq.Code.Class() == "53", erroPq.Code.Class() == "57":
+		// connection_exception, insufficient_resources, operator_intervention
+		// (inclui statement_timeout e cancelamentos pedidos pelo driver)
+		erro.Tipo = ErrIndisponivel
+	default:
+		return fmt.Errorf("%s: %w", contexto, erroPq)
+	}
+
+	return erro
+}
+
+// mensagemRestricao monta uma mensagem legível para violações de restrição
+func mensagemRestricao(erroPq *pq.Error) string {
+	switch {
+	case erroPq.Column != "":
+		return fmt.Sprintf("valor inválido para o campo %s", erroPq.Column)
+	case erroPq.Constraint != "":
+		return fmt.Sprintf("restrição %s violada", strings.TrimSpace(erroPq.Constraint))
+	default:
+		return "restrição do banco de dados violada"
+	}
+}
+
+// pessoaNaoEncontrada cria o erro padrão para pessoas inexistentes
+func pessoaNaoEncontrada(id int) error {
+	return &ErroBanco{
+		Tipo:     ErrNaoEncontrado,
+		Mensagem: fmt.Sprintf("pessoa com ID %d não encontrada", id),
+	}
+}
+
+// diretorInexistente rejeita um filme que referencia uma pessoa inexistente
+func diretorInexistente(id int) error {
+	return &ErroBanco{
+		Tipo:     ErrValidacao,
+		Mensagem: fmt.Sprintf("diretor com ID %d não encontrado", id),
+	}
+}
+
+// pessoaInexistente rejeita um crédito que referencia uma pessoa inexistente
+func pessoaInexistente(id int) error {
+	return &ErroBanco{
+		Tipo:     ErrValidacao,
+		Mensagem: fmt.Sprintf("pessoa com ID %d não encontrada", id),
+	}
+}
+
+// creditoNaoEncontrado é o erro de um crédito que não pertence ao filme
+func creditoNaoEncontrado(filmeID, id int) error {
+	return &ErroBanco{
+		Tipo:     ErrNaoEncontrado,
+		Mensagem: fmt.Sprintf("crédito com ID %d não encontrado no filme %d", id, filmeID),
+	}
+}
+
+// pessoaComFilmes impede apagar uma pessoa ainda creditada em filmes
+func pessoaComFilmes(id int) error {
+	return &ErroBanco{
+		Tipo:     ErrConflito,
+		Mensagem: fmt.Sprintf("pessoa com ID %d ainda está ligada a filmes", id),
+	}
+}
+
+// generoInexistente rejeita um filme com gênero fora da taxonomia
+func generoInexistente(chave string) error {
+	return &ErroBanco{
+		Tipo:     ErrValidacao,
+		Mensagem: fmt.Sprintf("gênero %q não cadastrado", chave),
+	}
+}
+
+// usuarioNaoEncontrado é o erro de busca por um email sem conta
+func usuarioNaoEncontrado() error {
+	return &ErroBanco{
+		Tipo:     ErrNaoEncontrado,
+		Mensagem: "usuário não encontrado",
+	}
+}
+
+// emailJaCadastrado rejeita o registro de um email que já tem conta
+func emailJaCadastrado() error {
+	return &ErroBanco{
+		Tipo:     ErrConflito,
+		Mensagem: "email já cadastrado",
+	}
+}
+
+// sessaoNaoEncontrada é o erro de logout com token desconhecido, expirado ou já revogado
+func sessaoNaoEncontrada() error {
+	return &ErroBanco{
+		Tipo:     ErrNaoEncontrado,
+		Mensagem: "sessão não encontrada ou expirada",
+	}
+}
@@ -0,0 +1,158 @@
+package database
+
+import (
+	"fmt"
+	"strings"
+
+	"api-filmes/internal/models"
+
+	"github.com/lib/pq"
+)
+
+// colunaOrdenacao descreve como um campo de ordenação vira SQL. Colunas
+// anuláveis deixam os NULL por último nas duas direções; no cursor, o
+// valor vazio indica que a página anterior terminou entre eles.
+type colunaOrdenacao struct {
+	expressao string
+	tipo      string
+	anulavel  bool
+}
+
+// colunasOrdenacao mapeia os campos públicos para expressões SQL fixas,
+// evitando que qualquer texto vindo da URL seja concatenado na query
+var colunasOrdenacao = map[string]colunaOrdenacao{
+	"id":             {expressao: "id", tipo: "integer"},
+	"titulo":         {expressao: "titulo", tipo: "text"},
+	"ano_lancamento": {expressao: "ano_lancamento", tipo: "integer"},
+	"genero":         {expressao: "COALESCE(genero, '')", tipo: "text"},
+	"diretor":        {expressao: "COALESCE(diretor, '')", tipo: "text"},
+	"avaliacao":      {expressao: "avaliacao", tipo: "numeric", anulavel: true},
+}
+
+// consultaFiltrada acumula cláusulas WHERE e argumentos posicionais
+type consultaFiltrada struct {
+	condicoes []string
+	args      []interface{}
+}
+
+// adicionar inclui uma condição substituindo "?" pelo próximo placeholder
+func (c *consultaFiltrada) adicionar(condicao string, valores ...interface{}) {
+	for _, valor := range valores {
+		c.args = append(c.args, valor)
+		condicao = strings.Replace(condicao, "?", fmt.Sprintf("$%d", len(c.args)), 1)
+	}
+	c.condicoes = append(c.condicoes, condicao)
+}
+
+// where monta a cláusula WHERE (vazia se não houver condições)
+func (c *consultaFiltrada) where() string {
+	if len(c.condicoes) == 0 {
+		return ""
+	}
+	return "WHERE " + strings.Join(c.condicoes, " AND ")
+}
+
+// montarFiltroFilmes traduz os filtros de listagem em condições parametrizadas.
+// Filmes na lixeira nunca entram na listagem.
+func montarFiltroFilmes(filtro *models.FiltroFilmes) *consultaFiltrada {
+	consulta := &consultaFiltrada{}
+	consulta.adicionar("deletado_em IS NULL")
+	if filtro == nil {
+		return consulta
+	}
+
+	if len(filtro.Generos) > 0 {
+		// Cada valor casa com o slug ou com um sinônimo do gênero
+		const temGenero = `EXISTS (
+            SELECT 1 FROM filme_generos fg JOIN generos g ON g.id = fg.genero_id
+            WHERE fg.filme_id = filmes.id AND (g.slug = ANY(?) OR g.sinonimos && ?))`
+		if filtro.TodosGeneros {
+			for _, genero := range filtro.Generos {
+				valor := pq.Array([]string{genero})
+				consulta.adicionar(temGenero, valor, valor)
+			}
+		} else {
+			valor := pq.Array(filtro.Generos)
+			consulta.adicionar(temGenero, valor, valor)
+		}
+	}
+	if filtro.Diretor != "" {
+		consulta.adicionar("diretor ILIKE ?", "%"+escaparLike(filtro.Diretor)+"%")
+	}
+	if filtro.AnoMinimo != nil {
+		consulta.adicionar("ano_lancamento >= ?", *filtro.AnoMinimo)
+	}
+	if filtro.AnoMaximo != nil {
+		consulta.adicionar("ano_lancamento <= ?", *filtro.AnoMaximo)
+	}
+	if filtro.AvaliacaoMinima != nil {
+		consulta.adicionar("avaliacao >= ?", *filtro.AvaliacaoMinima)
+	}
+	if filtro.AvaliacaoMaxima != nil {
+		consulta.adicionar("avaliacao <= ?", *filtro.AvaliacaoMaxima)
+	}
+
+	return consulta
+}
+
+// aplicarCursor restringe a consulta aos registros posteriores ao cursor
+func aplicarCursor(consulta *consultaFiltrada, filtro *models.FiltroFilmes) {
+	if filtro == nil || filtro.Cursor == nil {
+		return
+	}
+
+	coluna := colunasOrdenacao[filtro.Ordenacao]
+	operador := ">"
+	if filtro.Descendente {
+		operador = "<"
+	}
+
+	switch {
+	case !coluna.anulavel:
+		consulta.adicionar(
+			fmt.Sprintf("(%s, id) %s (?::%s, ?)", coluna.expressao, operador, coluna.tipo),
+			filtro.Cursor.Valor, filtro.Cursor.ID,
+		)
+	case filtro.Cursor.Valor == "":
+		consulta.adicionar(fmt.Sprintf("(%s IS NULL AND id %s ?)", coluna.expressao, operador), filtro.Cursor.ID)
+	default:
+		// A comparação com NULL não é verdadeira: os filmes sem valor, que
+		// vêm depois de todos os outros, entram pelo IS NULL
+		consulta.adicionar(
+			fmt.Sprintf("((%s, id) %s (?::%s, ?) OR %s IS NULL)", coluna.expressao, operador, coluna.tipo, coluna.expressao),
+			filtro.Cursor.Valor, filtro.Cursor.ID,
+		)
+	}
+}
+
+// ordenacaoFilmes gera o ORDER BY com desempate por id
+func ordenacaoFilmes(filtro *models.FiltroFilmes) string {
+	if filtro == nil {
+		return "ORDER BY id ASC"
+	}
+
+	coluna, ok := colunasOrdenacao[filtro.Ordenacao]
+	if !ok {
+		coluna = colunasOrdenacao["id"]
+	}
+
+	direcao := "ASC"
+	if filtro.Descendente {
+		direcao = "DESC"
+	}
+
+	if coluna.expressao == "id" {
+		return "ORDER BY id " + direcao
+	}
+	nulos := ""
+	if coluna.anulavel {
+		nulos = " NULLS LAST"
+	}
+	return fmt.Sprintf("ORDER BY %s %s%s, id %s", coluna.expressao, direcao, nulos, direcao)
+}
+
+// escaparLike protege os curingas do LIKE presentes no texto do usuário
+func escaparLike(texto string) string {
+	substituto := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+	return substituto.Replace(texto)
+}
@@ -0,0 +1,204 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+
+	"api-filmes/internal/models"
+
+	"github.com/lib/pq"
+)
+
+// resolverGeneros transforma os gêneros pedidos (ou, sem eles, o texto
+// legado genero) em entradas da taxonomia, aceitando slugs e sinônimos.
+// O texto de exibição segue models.TextoGeneros.
+func resolverGeneros(ctx context.Context, tx *sql.Tx, filme *models.FilmeParaCriar) ([]models.GeneroResumo, *string, error) {
+	chaves := []string{}
+	if len(filme.Generos) > 0 {
+		for _, genero := range filme.Generos {
+			chaves = append(chaves, models.Slug(genero))
+		}
+	} else if filme.Genero != nil {
+		for _, nome := range models.SepararGeneros(*filme.Genero) {
+			chaves = append(chaves, models.Slug(nome))
+		}
+	}
+	if len(chaves) == 0 {
+		return []models.GeneroResumo{}, nil, nil
+	}
+
+	// O texto também é resolvido, para saber se ainda descreve a lista
+	consultadas := append([]string{}, chaves...)
+	if filme.Genero != nil {
+		for _, nome := range models.SepararGeneros(*filme.Genero) {
+			consultadas = append(consultadas, models.Slug(nome))
+		}
+	}
+
+	query := `
+        SELECT c.chave, g.id, g.slug, g.nome
+        FROM unnest($1::text[]) AS c(chave)
+        JOIN generos g ON g.slug = c.chave OR c.chave = ANY(g.sinonimos)
+        ORDER BY g.slug = c.chave DESC
+    `
+	linhas, err := tx.QueryContext(ctx, query, pq.Array(consultadas))
+	if err != nil {
+		return nil, nil, err
+	}
+	defer linhas.Close()
+
+	type entrada struct {
+		id     int
+		genero models.GeneroResumo
+	}
+	porChave := make(map[string]entrada, len(chaves))
+	for linhas.Next() {
+		var chave string
+		var e entrada
+		if err := linhas.Scan(&chave, &e.id, &e.genero.Slug, &e.genero.Nome); err != nil {
+			return nil, nil, err
+		}
+		// O slug exato vem primeiro e prevalece sobre um sinônimo
+		if _, ok := porChave[chave]; !ok {
+			porChave[chave] = e
+		}
+	}
+	if err := linhas.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	generos := []models.GeneroResumo{}
+	vistos := make(map[string]bool)
+	for _, chave := range chaves {
+		e, ok := porChave[chave]
+		if !ok {
+			return nil, nil, generoInexistente(chave)
+		}
+		if !vistos[e.genero.Slug] {
+			vistos[e.genero.Slug] = true
+			generos = append(generos, e.genero)
+		}
+	}
+	resolver := func(nome string) (string, bool) {
+		e, ok := porChave[models.Slug(nome)]
+		return e.genero.Slug, ok
+	}
+	return generos, models.TextoGeneros(filme.Genero, generos, resolver), nil
+}
+
+// vincularGeneros substitui os gêneros do filme e atualiza filme.Generos,
+// pelo mesmo motivo de vincularDiretores
+func vincularGeneros(ctx context.Context, tx *sql.Tx, filme *models.Filme, generos []models.GeneroResumo) error {
+	if _, err := tx.ExecContext(ctx, "DELETE FROM filme_generos WHERE filme_id = $1", filme.ID); err != nil {
+		return err
+	}
+
+	slugs := make([]string, len(generos))
+	for i, genero := range generos {
+		slugs[i] = genero.Slug
+	}
+
+	query := `
+        INSERT INTO filme_generos (filme_id, genero_id, posicao)
+        SELECT $1, g.id, s.posicao
+        FROM unnest($2::text[]) WITH ORDINALITY AS s(slug, posicao)
+        JOIN generos g ON g.slug = s.slug
+    `
+	if _, err := tx.ExecContext(ctx, query, filme.ID, pq.Array(slugs)); err != nil {
+		return err
+	}
+
+	filme.Generos = generos
+	return nil
+}
+
+// colunasGenero lista as colunas lidas por escanearGenero; total_filmes
+// conta apenas filmes fora da lixeira
+const colunasGenero = `g.id, g.nome, g.slug, g.sinonimos,
+        (SELECT COUNT(*) FROM filme_generos fg JOIN filmes f ON f.id = fg.filme_id
+            WHERE fg.genero_id = g.id AND f.deletado_em IS NULL)`
+
+func escanearGenero(linha linhaEscaneavel) (*models.Genero, error) {
+	var genero models.Genero
+	sinonimos := pq.StringArray{}
+	if err := linha.Scan(&genero.ID, &genero.Nome, &genero.Slug, &sinonimos, &genero.TotalFilmes); err != nil {
+		return nil, err
+	}
+	genero.Sinonimos = []string(sinonimos)
+	if genero.Sinonimos == nil {
+		genero.Sinonimos = []string{}
+	}
+	return &genero, nil
+}
+
+// ListarGenerosCatalogo retorna a taxonomia sem a contagem de filmes de
+// colunasGenero, que percorreria filme_generos a cada escrita de filme
+func (bd *BancoDados) ListarGenerosCatalogo(ctx context.Context) ([]models.Genero, error) {
+	linhas, err := bd.conexao.QueryContext(ctx, "SELECT id, nome, slug, sinonimos FROM generos")
+	if err != nil {
+		return nil, traduzirErro(err, "erro ao listar gêneros")
+	}
+	defer linhas.Close()
+
+	generos := []models.Genero{}
+	for linhas.Next() {
+		var genero models.Genero
+		sinonimos := pq.StringArray{}
+		if err := linhas.Scan(&genero.ID, &genero.Nome, &genero.Slug, &sinonimos); err != nil {
+			return nil, traduzirErro(err, "erro ao ler dados do gênero")
+		}
+		genero.Sinonimos = []string(sinonimos)
+		generos = append(generos, genero)
+	}
+	if err := linhas.Err(); err != nil {
+		return nil, traduzirErro(err, "erro durante leitura dos resultados")
+	}
+
+	return generos, nil
+}
+
+// ListarGeneros retorna a taxonomia inteira em ordem alfabética
+func (bd *BancoDados) ListarGeneros(ctx context.Context) ([]models.Genero, error) {
+	query := "SELECT " + colunasGenero + " FROM generos g ORDER BY LOWER(g.nome), g.id"
+
+	linhas, err := bd.conexao.QueryContext(ctx, query)
+	if err != nil {
+		return nil, traduzirErro(err, "erro ao listar gêneros")
+	}
+	defer linhas.Close()
+
+	generos := []models.Genero{}
+	for linhas.Next() {
+		genero, err := escanearGenero(linhas)
+		if err != nil {
+			return nil, traduzirErro(err, "erro ao ler dados do gênero")
+		}
+		generos = append(generos, *genero)
+	}
+	if err := linhas.Err(); err != nil {
+		return nil, traduzirErro(err, "erro durante leitura dos resultados")
+	}
+
+	return generos, nil
+}
+
+func (bd *BancoDados) CriarGenero(ctx context.Context, genero *models.GeneroParaCriar) (*models.Genero, error) {
+	query := `
+        WITH g AS (
+            INSERT INTO generos (nome, slug, sinonimos) VALUES ($1, $2, $3)
+            RETURNING id, nome, slug, sinonimos
+        )
+        SELECT g.id, g.nome, g.slug, g.sinonimos, 0 FROM g
+    `
+
+	criado, err := escanearGenero(bd.conexao.QueryRowContext(ctx, query,
+		genero.Nome,
+		models.Slug(genero.Nome),
+		pq.Array(genero.Sinonimos),
+	))
+	if err != nil {
+		return nil, traduzirErro(err, "erro ao criar gênero")
+	}
+
+	return criado, nil
+}
@@ -0,0 +1,406 @@
+package database
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"api-filmes/internal/metricas"
+	"api-filmes/internal/models"
+	"api-filmes/internal/rastreamento"
+)
+
+// Métricas do repositório expostas em /metrics
+var duracaoConsultas = metricas.NovoHistograma(
+	"api_filmes_db_consulta_duracao_segundos",
+	"Duração das operações do repositório em segundos, por método.",
+	nil,
+	"operacao", "resultado",
+)
+
+// RepositorioInstrumentado envolve um Repositorio e, para cada
+// operação, mede a duração, registra um log e abre um span filho
+type RepositorioInstrumentado struct {
+	repositorio Repositorio
+	sistema     string
+}
+
+// Instrumentar devolve o repositório com métricas, logs e spans por método
+func Instrumentar(repositorio Repositorio) *RepositorioInstrumentado {
+	sistema := "memoria"
+	if _, ok := repositorio.(*BancoDados); ok {
+		sistema = "postgresql"
+	}
+	return &RepositorioInstrumentado{repositorio: repositorio, sistema: sistema}
+}
+
+// iniciarOperacao abre o span da operação e devolve a função que o encerra
+// informando quantas linhas foram lidas ou afetadas
+func (ri *RepositorioInstrumentado) iniciarOperacao(ctx context.Context, operacao string) (context.Context, func(linhas int, err error)) {
+	inicio := time.Now()
+	ctx, span := rastreamento.Iniciar(ctx, operacao, rastreamento.TipoCliente,
+		rastreamento.Atributo{Chave: "db.system", Valor: ri.sistema},
+		rastreamento.Atributo{Chave: "db.operation", Valor: operacao},
+	)
+
+	return ctx, func(linhas int, err error) {
+		duracao := time.Since(inicio)
+		resultado := "ok"
+		if err != nil {
+			resultado = "erro"
+		}
+		duracaoConsultas.Observar(duracao.Seconds(), operacao, resultado)
+
+		span.DefinirAtributo("db.linhas", linhas)
+		span.RegistrarErro(err)
+		span.Finalizar()
+
+		atributos := []slog.Attr{
+			slog.String("operacao", operacao),
+			slog.String("resultado", resultado),
+			slog.Int("linhas", linhas),
+			slog.Float64("duracao_ms", float64(duracao.Microseconds())/1000),
+		}
+		if err != nil {
+			atributos = append(atributos, slog.String("erro", err.Error()))
+		}
+		slog.LogAttrs(ctx, slog.LevelDebug, "operação no repositório", atributos...)
+	}
+}
+
+// BuscarTodosFilmes instrumenta BuscarTodosFilmes do repositório envolvido
+func (ri *RepositorioInstrumentado) BuscarTodosFilmes(ctx context.Context, filtro *models.FiltroFilmes) ([]models.FilmeResumo, error) {
+	ctx, finalizar := ri.iniciarOperacao(ctx, "BuscarTodosFilmes")
+	filmes, err := ri.repositorio.BuscarTodosFilmes(ctx, filtro)
+	finalizar(len(filmes), err)
+	return filmes, err
+}
+
+// BuscarFilmePorID instrumenta BuscarFilmePorID do repositório envolvido
+func (ri *RepositorioInstrumentado) BuscarFilmePorID(ctx context.Context, id int) (*models.Filme, error) {
+	ctx, finalizar := ri.iniciarOperacao(ctx, "BuscarFilmePorID")
+	filme, err := ri.repositorio.BuscarFilmePorID(ctx, id)
+	finalizar(linhasAfetadas(err), err)
+	return filme, err
+}
+
+// BuscarFilmesPorTexto instrumenta BuscarFilmesPorTexto do repositório envolvido
+func (ri *RepositorioInstrumentado) BuscarFilmesPorTexto(ctx context.Context, termo string, limite int) ([]models.ResultadoBusca, error) {
+	ctx, finalizar := ri.iniciarOperacao(ctx, "BuscarFilmesPorTexto")
+	resultados, err := ri.repositorio.BuscarFilmesPorTexto(ctx, termo, limite)
+	finalizar(len(resultados), err)
+	return resultados, err
+}
+
+// ContarFilmes instrumenta ContarFilmes do repositório envolvido
+func (ri *RepositorioInstrumentado) ContarFilmes(ctx context.Context, filtro *models.FiltroFilmes) (int, error) {
+	ctx, finalizar := ri.iniciarOperacao(ctx, "ContarFilmes")
+	total, err := ri.repositorio.ContarFilmes(ctx, filtro)
+	finalizar(linhasAfetadas(err), err)
+	return total, err
+}
+
+// CriarFilme instrumenta CriarFilme do repositório envolvido
+func (ri *RepositorioInstrumentado) CriarFilme(ctx context.Context, filme *models.FilmeParaCriar) (*models.Filme, error) {
+	ctx, finalizar := ri.iniciarOperacao(ctx, "CriarFilme")
+	criado, err := ri.repositorio.CriarFilme(ctx, filme)
+	finalizar(linhasAfetadas(err), err)
+	return criado, err
+}
+
+// AtualizarFilme instrumenta AtualizarFilme do repositório envolvido
+func (ri *RepositorioInstrumentado) AtualizarFilme(ctx context.Context, id int, filme *models.FilmeParaCriar, versao int) (*models.Filme, error) {
+	ctx, finalizar := ri.iniciarOperacao(ctx, "AtualizarFilme")
+	atualizado, err := ri.repositorio.AtualizarFilme(ctx, id, filme, versao)
+	finalizar(linhasAfetadas(err), err)
+	return atualizado, err
+}
+
+// DeletarFilme instrumenta DeletarFilme do repositório envolvido
+func (ri *RepositorioInstrumentado) DeletarFilme(ctx context.Context, id int, versao int) error {
+	ctx, finalizar := ri.iniciarOperacao(ctx, "DeletarFilme")
+	err := ri.repositorio.DeletarFilme(ctx, id, versao)
+	finalizar(linhasAfetadas(err), err)
+	return err
+}
+
+// ListarLixeira instrumenta ListarLixeira do repositório envolvido
+func (ri *RepositorioInstrumentado) ListarLixeira(ctx context.Context, limite, deslocamento int) ([]models.Filme, error) {
+	ctx, finalizar := ri.iniciarOperacao(ctx, "ListarLixeira")
+	filmes, err := ri.repositorio.ListarLixeira(ctx, limite, deslocamento)
+	finalizar(len(filmes), err)
+	return filmes, err
+}
+
+// RestaurarFilme instrumenta RestaurarFilme do repositório envolvido
+func (ri *RepositorioInstrumentado) RestaurarFilme(ctx context.Context, id int) (*models.Filme, error) {
+	ctx, finalizar := ri.iniciarOperacao(ctx, "RestaurarFilme")
+	filme, err := ri.repositorio.RestaurarFilme(ctx, id)
+	finalizar(linhasAfetadas(err), err)
+	return filme, err
+}
+
+// ExpurgarFilme instrumenta ExpurgarFilme do repositório envolvido
+func (ri *RepositorioInstrumentado) ExpurgarFilme(ctx context.Context, id int) error {
+	ctx, finalizar := ri.iniciarOperacao(ctx, "ExpurgarFilme")
+	err := ri.repositorio.ExpurgarFilme(ctx, id)
+	finalizar(linhasAfetadas(err), err)
+	return err
+}
+
+// ExpurgarLixeira instrumenta ExpurgarLixeira do repositório envolvido
+func (ri *RepositorioInstrumentado) ExpurgarLixeira(ctx context.Context, antesDe time.Time) (int, error) {
+	ctx, finalizar := ri.iniciarOperacao(ctx, "ExpurgarLixeira")
+	removidos, err := ri.repositorio.ExpurgarLixeira(ctx, antesDe)
+	finalizar(removidos, err)
+	return removidos, err
+}
+
+// BuscarAuditoria instrumenta BuscarAuditoria do repositório envolvido
+func (ri *RepositorioInstrumentado) BuscarAuditoria(ctx context.Context, filtro *models.FiltroAuditoria) ([]models.RegistroAuditoria, error) {
+	ctx, finalizar := ri.iniciarOperacao(ctx, "BuscarAuditoria")
+	registros, err := ri.repositorio.BuscarAuditoria(ctx, filtro)
+	finalizar(len(registros), err)
+	return registros, err
+}
+
+// ListarVersoesFilme instrumenta ListarVersoesFilme do repositório envolvido
+func (ri *RepositorioInstrumentado) ListarVersoesFilme(ctx context.Context, id int) ([]models.VersaoFilme, error) {
+	ctx, finalizar := ri.iniciarOperacao(ctx, "ListarVersoesFilme")
+	versoes, err := ri.repositorio.ListarVersoesFilme(ctx, id)
+	finalizar(len(versoes), err)
+	return versoes, err
+}
+
+// BuscarVersaoFilme instrumenta BuscarVersaoFilme do repositório envolvido
+func (ri *RepositorioInstrumentado) BuscarVersaoFilme(ctx context.Context, id, numero int) (*models.VersaoFilme, error) {
+	ctx, finalizar := ri.iniciarOperacao(ctx, "BuscarVersaoFilme")
+	versao, err := ri.repositorio.BuscarVersaoFilme(ctx, id, numero)
+	finalizar(linhasAfetadas(err), err)
+	return versao, err
+}
+
+// ReverterFilme instrumenta ReverterFilme do repositório envolvido
+func (ri *RepositorioInstrumentado) ReverterFilme(ctx context.Context, id, numero, versaoAtual int) (*models.Filme, error) {
+	ctx, finalizar := ri.iniciarOperacao(ctx, "ReverterFilme")
+	filme, err := ri.repositorio.ReverterFilme(ctx, id, numero, versaoAtual)
+	finalizar(linhasAfetadas(err), err)
+	return filme, err
+}
+
+// ListarPessoas instrumenta ListarPessoas do repositório envolvido
+func (ri *RepositorioInstrumentado) ListarPessoas(ctx context.Context, filtro *models.FiltroPessoas) ([]models.Pessoa, error) {
+	ctx, finalizar := ri.iniciarOperacao(ctx, "ListarPessoas")
+	pessoas, err := ri.repositorio.ListarPessoas(ctx, filtro)
+	finalizar(len(pessoas), err)
+	return pessoas, err
+}
+
+// BuscarPessoaPorID instrumenta BuscarPessoaPorID do repositório envolvido
+func (ri *RepositorioInstrumentado) BuscarPessoaPorID(ctx context.Context, id int) (*models.Pessoa, error) {
+	ctx, finalizar := ri.iniciarOperacao(ctx, "BuscarPessoaPorID")
+	pessoa, err := ri.repositorio.BuscarPessoaPorID(ctx, id)
+	finalizar(linhasAfetadas(err), err)
+	return pessoa, err
+}
+
+// CriarPessoa instrumenta CriarPessoa do repositório envolvido
+func (ri *RepositorioInstrumentado) CriarPessoa(ctx context.Context, pessoa *models.PessoaParaCriar) (*models.Pessoa, error) {
+	ctx, finalizar := ri.iniciarOperacao(ctx, "CriarPessoa")
+	criada, err := ri.repositorio.CriarPessoa(ctx, pessoa)
+	finalizar(linhasAfetadas(err), err)
+	return criada, err
+}
+
+// AtualizarPessoa instrumenta AtualizarPessoa do repositório envolvido
+func (ri *RepositorioInstrumentado) AtualizarPessoa(ctx context.Context, id int, pessoa *models.PessoaParaCriar) (*models.Pessoa, error) {
+	ctx, finalizar := ri.iniciarOperacao(ctx, "AtualizarPessoa")
+	atualizada, err := ri.repositorio.AtualizarPessoa(ctx, id, pessoa)
+	finalizar(linhasAfetadas(err), err)
+	return atualizada, err
+}
+
+// DeletarPessoa instrumenta DeletarPessoa do repositório envolvido
+func (ri *RepositorioInstrumentado) DeletarPessoa(ctx context.Context, id int) error {
+	ctx, finalizar := ri.iniciarOperacao(ctx, "DeletarPessoa")
+	err := ri.repositorio.DeletarPessoa(ctx, id)
+	finalizar(linhasAfetadas(err), err)
+	return err
+}
+
+// FilmesDoDiretor instrumenta FilmesDoDiretor do repositório envolvido
+func (ri *RepositorioInstrumentado) FilmesDoDiretor(ctx context.Context, id int) ([]models.FilmeResumo, error) {
+	ctx, finalizar := ri.iniciarOperacao(ctx, "FilmesDoDiretor")
+	filmes, err := ri.repositorio.FilmesDoDiretor(ctx, id)
+	finalizar(len(filmes), err)
+	return filmes, err
+}
+
+// ListarCreditos instrumenta ListarCreditos do repositório envolvido
+func (ri *RepositorioInstrumentado) ListarCreditos(ctx context.Context, filmeID int, funcao string) ([]models.Credito, error) {
+	ctx, finalizar := ri.iniciarOperacao(ctx, "ListarCreditos")
+	creditos, err := ri.repositorio.ListarCreditos(ctx, filmeID, funcao)
+	finalizar(len(creditos), err)
+	return creditos, err
+}
+
+// CriarCredito instrumenta CriarCredito do repositório envolvido
+func (ri *RepositorioInstrumentado) CriarCredito(ctx context.Context, filmeID int, credito *models.CreditoParaCriar) (*models.Credito, error) {
+	ctx, finalizar := ri.iniciarOperacao(ctx, "CriarCredito")
+	criado, err := ri.repositorio.CriarCredito(ctx, filmeID, credito)
+	finalizar(linhasAfetadas(err), err)
+	return criado, err
+}
+
+// DeletarCredito instrumenta DeletarCredito do repositório envolvido
+func (ri *RepositorioInstrumentado) DeletarCredito(ctx context.Context, filmeID, id int) error {
+	ctx, finalizar := ri.iniciarOperacao(ctx, "DeletarCredito")
+	err := ri.repositorio.DeletarCredito(ctx, filmeID, id)
+	finalizar(linhasAfetadas(err), err)
+	return err
+}
+
+// Filmografia instrumenta Filmografia do repositório envolvido
+func (ri *RepositorioInstrumentado) Filmografia(ctx context.Context, pessoaID int) ([]models.ItemFilmografia, error) {
+	ctx, finalizar := ri.iniciarOperacao(ctx, "Filmografia")
+	filmografia, err := ri.repositorio.Filmografia(ctx, pessoaID)
+	finalizar(len(filmografia), err)
+	return filmografia, err
+}
+
+// ListarGeneros instrumenta ListarGeneros do repositório envolvido
+func (ri *RepositorioInstrumentado) ListarGeneros(ctx context.Context) ([]models.Genero, error) {
+	ctx, finalizar := ri.iniciarOperacao(ctx, "ListarGeneros")
+	generos, err := ri.repositorio.ListarGeneros(ctx)
+	finalizar(len(generos), err)
+	return generos, err
+}
+
+// ListarGenerosCatalogo instrumenta ListarGenerosCatalogo do repositório envolvido
+func (ri *RepositorioInstrumentado) ListarGenerosCatalogo(ctx context.Context) ([]models.Genero, error) {
+	ctx, finalizar := ri.iniciarOperacao(ctx, "ListarGenerosCatalogo")
+	generos, err := ri.repositorio.ListarGenerosCatalogo(ctx)
+	finalizar(len(generos), err)
+	return generos, err
+}
+
+// CriarGenero instrumenta CriarGenero do repositório envolvido
+func (ri *RepositorioInstrumentado) CriarGenero(ctx context.Context, genero *models.GeneroParaCriar) (*models.Genero, error) {
+	ctx, finalizar := ri.iniciarOperacao(ctx, "CriarGenero")
+	criado, err := ri.repositorio.CriarGenero(ctx, genero)
+	finalizar(linhasAfetadas(err), err)
+	return criado, err
+}
+
+// CriarUsuario instrumenta CriarUsuario do repositório envolvido
+func (ri *RepositorioInstrumentado) CriarUsuario(ctx context.Context, usuario *models.UsuarioParaRegistrar, senhaHash string) (*models.Usuario, error) {
+	ctx, finalizar := ri.iniciarOperacao(ctx, "CriarUsuario")
+	criado, err := ri.repositorio.CriarUsuario(ctx, usuario, senhaHash)
+	finalizar(linhasAfetadas(err), err)
+	return criado, err
+}
+
+// BuscarUsuarioPorEmail instrumenta BuscarUsuarioPorEmail do repositório envolvido
+func (ri *RepositorioInstrumentado) BuscarUsuarioPorEmail(ctx context.Context, email string) (*models.Usuario, error) {
+	ctx, finalizar := ri.iniciarOperacao(ctx, "BuscarUsuarioPorEmail")
+	usuario, err := ri.repositorio.BuscarUsuarioPorEmail(ctx, email)
+	finalizar(linhasAfetadas(err), err)
+	return usuario, err
+}
+
+// BuscarTentativasLogin instrumenta BuscarTentativasLogin do repositório envolvido
+func (ri *RepositorioInstrumentado) BuscarTentativasLogin(ctx context.Context, email string) (*models.TentativasLogin, error) {
+	ctx, finalizar := ri.iniciarOperacao(ctx, "BuscarTentativasLogin")
+	tentativas, err := ri.repositorio.BuscarTentativasLogin(ctx, email)
+	finalizar(linhasAfetadas(err), err)
+	return tentativas, err
+}
+
+// RegistrarFalhaLogin instrumenta RegistrarFalhaLogin do repositório envolvido
+func (ri *RepositorioInstrumentado) RegistrarFalhaLogin(ctx context.Context, email string, maximo int, falhasDesde, bloqueadoAte time.Time) (*models.TentativasLogin, error) {
+	ctx, finalizar := ri.iniciarOperacao(ctx, "RegistrarFalhaLogin")
+	tentativas, err := ri.repositorio.RegistrarFalhaLogin(ctx, email, maximo, falhasDesde, bloqueadoAte)
+	finalizar(linhasAfetadas(err), err)
+	return tentativas, err
+}
+
+// ExpurgarTentativasLogin instrumenta ExpurgarTentativasLogin do repositório envolvido
+func (ri *RepositorioInstrumentado) ExpurgarTentativasLogin(ctx context.Context, antesDe time.Time) (int, error) {
+	ctx, finalizar := ri.iniciarOperacao(ctx, "ExpurgarTentativasLogin")
+	removidos, err := ri.repositorio.ExpurgarTentativasLogin(ctx, antesDe)
+	finalizar(removidos, err)
+	return removidos, err
+}
+
+// RegistrarLoginSucesso instrumenta RegistrarLoginSucesso do repositório envolvido
+func (ri *RepositorioInstrumentado) RegistrarLoginSucesso(ctx context.Context, id int) error {
+	ctx, finalizar := ri.iniciarOperacao(ctx, "RegistrarLoginSucesso")
+	err := ri.repositorio.RegistrarLoginSucesso(ctx, id)
+	finalizar(linhasAfetadas(err), err)
+	return err
+}
+
+// CriarSessao instrumenta CriarSessao do repositório envolvido
+func (ri *RepositorioInstrumentado) CriarSessao(ctx context.Context, sessao *models.Sessao) error {
+	ctx, finalizar := ri.iniciarOperacao(ctx, "CriarSessao")
+	err := ri.repositorio.CriarSessao(ctx, sessao)
+	finalizar(linhasAfetadas(err), err)
+	return err
+}
+
+// RevogarSessao instrumenta RevogarSessao do repositório envolvido
+func (ri *RepositorioInstrumentado) RevogarSessao(ctx context.Context, tokenHash string) error {
+	ctx, finalizar := ri.iniciarOperacao(ctx, "RevogarSessao")
+	err := ri.repositorio.RevogarSessao(ctx, tokenHash)
+	finalizar(linhasAfetadas(err), err)
+	return err
+}
+
+// RenovarSessao instrumenta RenovarSessao do repositório envolvido
+func (ri *RepositorioInstrumentado) RenovarSessao(ctx context.Context, tokenHash string, nova *models.Sessao) (*models.Usuario, error) {
+	ctx, finalizar := ri.iniciarOperacao(ctx, "RenovarSessao")
+	usuario, err := ri.repositorio.RenovarSessao(ctx, tokenHash, nova)
+	finalizar(linhasAfetadas(err), err)
+	return usuario, err
+}
+
+// linhasAfetadas vale 1 para operações de linha única bem-sucedidas
+func linhasAfetadas(err error) int {
+	if err != nil {
+		return 0
+	}
+	return 1
+}
+
+// Fechar repassa o fechamento ao repositório envolvido
+func (ri *RepositorioInstrumentado) Fechar() error {
+	return ri.repositorio.Fechar()
+}
+
+// RegistrarMetricasPool expõe as estatísticas do pool de conexões,
+// lidas de Estatisticas() a cada coleta
+func RegistrarMetricasPool(bd Verificavel) {
+	medidores := []struct {
+		nome, ajuda string
+		valor       func() float64
+	}{
+		{"api_filmes_db_pool_conexoes_maximas", "Limite de conexões abertas com o banco (0 = ilimitado).",
+			func() float64 { return float64(bd.Estatisticas().MaxOpenConnections) }},
+		{"api_filmes_db_pool_conexoes_abertas", "Conexões abertas com o banco, em uso ou ociosas.",
+			func() float64 { return float64(bd.Estatisticas().OpenConnections) }},
+		{"api_filmes_db_pool_conexoes_em_uso", "Conexões do pool em uso.",
+			func() float64 { return float64(bd.Estatisticas().InUse) }},
+		{"api_filmes_db_pool_conexoes_ociosas", "Conexões do pool ociosas.",
+			func() float64 { return float64(bd.Estatisticas().Idle) }},
+	}
+	for _, m := range medidores {
+		metricas.NovoMedidorFuncao(m.nome, m.ajuda, m.valor)
+	}
+
+	metricas.NovoContadorFuncao("api_filmes_db_pool_esperas_total",
+		"Total de vezes que uma requisição esperou por conexão livre.",
+		func() float64 { return float64(bd.Estatisticas().WaitCount) })
+	metricas.NovoContadorFuncao("api_filmes_db_pool_espera_segundos_total",
+		"Tempo total gasto esperando por conexões livres.",
+		func() float64 { return bd.Estatisticas().WaitDuration.Seconds() })
+}
@@ -0,0 +1,1513 @@
+package database
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"api-filmes/internal/models"
+	"api-filmes/internal/requisicao"
+)
+
+// RepositorioMemoria guarda os filmes em memória, protegido por mutex.
+// As operações respeitam o cancelamento do contexto antes de começar.
+// Útil para testes e desenvolvimento local sem PostgreSQL.
+type RepositorioMemoria struct {
+	mu        sync.RWMutex
+	filmes    map[int]models.Filme
+	lixeira   map[int]models.Filme
+	proximoID int
+
+	auditoria          []models.RegistroAuditoria
+	proximoIDAuditoria int64
+	versoes            map[int][]models.VersaoFilme
+
+	pessoas         map[int]models.Pessoa
+	proximoIDPessoa int
+
+	creditos         map[int][]models.Credito
+	proximoIDCredito int
+
+	generos         map[int]models.Genero
+	proximoIDGenero int
+
+	usuarios         map[int]models.Usuario
+	proximoIDUsuario int
+	// sessoes é indexado pelo hash do token
+	sessoes map[string]models.Sessao
+	// tentativas é indexado pelo email normalizado
+	tentativas map[string]models.TentativasLogin
+}
+
+// NovoRepositorioMemoria cria um repositório em memória sem filmes, já com
+// a taxonomia de gêneros da migração 0009
+func NovoRepositorioMemoria() *RepositorioMemoria {
+	rm := &RepositorioMemoria{
+		filmes:    make(map[int]models.Filme),
+		lixeira:   make(map[int]models.Filme),
+		versoes:   make(map[int][]models.VersaoFilme),
+		proximoID: 1,
+
+		pessoas:         make(map[int]models.Pessoa),
+		proximoIDPessoa: 1,
+
+		creditos:         make(map[int][]models.Credito),
+		proximoIDCredito: 1,
+
+		generos:         make(map[int]models.Genero),
+		proximoIDGenero: 1,
+
+		usuarios:         make(map[int]models.Usuario),
+		proximoIDUsuario: 1,
+		sessoes:          make(map[string]models.Sessao),
+		tentativas:       make(map[string]models.TentativasLogin),
+	}
+	for _, genero := range generosExemplo() {
+		rm.inserirGenero(genero)
+	}
+	return rm
+}
+
+// CarregarExemplos insere os mesmos filmes de exemplo da migração inicial
+func (rm *RepositorioMemoria) CarregarExemplos(ctx context.Context) error {
+	for _, exemplo := range filmesExemplo() {
+		if _, err := rm.CriarFilme(ctx, &exemplo); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (rm *RepositorioMemoria) Fechar() error {
+	return nil
+}
+
+// BuscarTodosFilmes aplica filtros, ordenação e paginação como na versão SQL
+func (rm *RepositorioMemoria) BuscarTodosFilmes(ctx context.Context, filtro *models.FiltroFilmes) ([]models.FilmeResumo, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	rm.mu.RLock()
+	filmes := rm.filtrar(filtro)
+	rm.mu.RUnlock()
+
+	ordenarResumos(filmes, filtro)
+
+	if filtro != nil && filtro.Cursor != nil {
+		filmes = aplicarCursorMemoria(filmes, filtro)
+	}
+
+	if filtro != nil && filtro.Limite > 0 {
+		inicio := filtro.Deslocamento
+		if inicio > len(filmes) {
+			inicio = len(filmes)
+		}
+		fim := inicio + filtro.Limite
+		if fim > len(filmes) {
+			fim = len(filmes)
+		}
+		filmes = filmes[inicio:fim]
+	}
+
+	return filmes, nil
+}
+
+func (rm *RepositorioMemoria) BuscarFilmePorID(ctx context.Context, id int) (*models.Filme, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	rm.mu.RLock()
+	defer rm.mu.RUnlock()
+
+	filme, ok := rm.filmes[id]
+	if !ok {
+		return nil, filmeNaoEncontrado(id)
+	}
+	return &filme, nil
+}
+
+// BuscarFilmesPorTexto faz uma busca simples por substring, sem acentos
+func (rm *RepositorioMemoria) BuscarFilmesPorTexto(ctx context.Context, termo string, limite int) ([]models.ResultadoBusca, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	rm.mu.RLock()
+	defer rm.mu.RUnlock()
+
+	termoNormalizado := normalizarTexto(termo)
+	resultados := []models.ResultadoBusca{}
+
+	for _, filme := range rm.filmes {
+		relevancia := 0.0
+		if strings.Contains(normalizarTexto(filme.Titulo), termoNormalizado) {
+			relevancia += 1.0
+		}
+		if strings.Contains(normalizarTexto(filme.Diretor), termoNormalizado) {
+			relevancia += 0.4
+		}
+		if strings.Contains(normalizarTexto(filme.Descricao), termoNormalizado) {
+			relevancia += 0.2
+		}
+		if relevancia == 0 {
+			continue
+		}
+
+		resultados = append(resultados, models.ResultadoBusca{
+			FilmeResumo:     resumir(filme),
+			Relevancia:      relevancia,
+			Destaque:        FormatarDestaque(filme.Titulo),
+			Correspondencia: CorrespondenciaExata,
+		})
+	}
+
+	sort.Slice(resultados, func(i, j int) bool {
+		if resultados[i].Relevancia != resultados[j].Relevancia {
+			return resultados[i].Relevancia > resultados[j].Relevancia
+		}
+		return resultados[i].ID < resultados[j].ID
+	})
+
+	if limite > 0 && len(resultados) > limite {
+		resultados = resultados[:limite]
+	}
+	return resultados, nil
+}
+
+func (rm *RepositorioMemoria) ContarFilmes(ctx context.Context, filtro *models.FiltroFilmes) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	rm.mu.RLock()
+	defer rm.mu.RUnlock()
+
+	return len(rm.filtrar(filtro)), nil
+}
+
+func (rm *RepositorioMemoria) CriarFilme(ctx context.Context, filme *models.FilmeParaCriar) (*models.Filme, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+
+	generos, genero, err := rm.resolverGeneros(filme)
+	if err != nil {
+		return nil, err
+	}
+	diretores, diretor, err := rm.resolverDiretores(filme)
+	if err != nil {
+		return nil, err
+	}
+
+	agora := time.Now()
+	novoFilme := models.Filme{
+		ID:              rm.proximoID,
+		DataCriacao:     agora,
+		DataAtualizacao: agora,
+		Versao:          1,
+	}
+	preencherFilme(&novoFilme, filme)
+	novoFilme.Genero = valorOuVazio(genero)
+	novoFilme.Generos = generos
+	novoFilme.Diretor = valorOuVazio(diretor)
+	novoFilme.Diretores = diretores
+
+	rm.filmes[novoFilme.ID] = novoFilme
+	rm.proximoID++
+	rm.auditar(ctx, models.OperacaoCriar, nil, &novoFilme)
+
+	return &novoFilme, nil
+}
+
+// AtualizarFilme substitui todos os campos editáveis do filme
+func (rm *RepositorioMemoria) AtualizarFilme(ctx context.Context, id int, filme *models.FilmeParaCriar, versao int) (*models.Filme, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+
+	return rm.substituirFilme(ctx, models.OperacaoAtualizar, id, filme, versao)
+}
+
+// substituirFilme grava os campos editáveis (chamar com lock de escrita adquirido)
+func (rm *RepositorioMemoria) substituirFilme(ctx context.Context, operacao string, id int, filme *models.FilmeParaCriar, versao int) (*models.Filme, error) {
+	existente, ok := rm.filmes[id]
+	if !ok {
+		return nil, filmeNaoEncontrado(id)
+	}
+	if versao != QualquerVersao && existente.Versao != versao {
+		return nil, versaoDivergente(id, versao)
+	}
+
+	generos, genero, err := rm.resolverGeneros(filme)
+	if err != nil {
+		return nil, err
+	}
+	diretores, diretor, err := rm.resolverDiretores(filme)
+	if err != nil {
+		return nil, err
+	}
+
+	antes := existente
+	preencherFilme(&existente, filme)
+	existente.Genero = valorOuVazio(genero)
+	existente.Generos = generos
+	existente.Diretor = valorOuVazio(diretor)
+	existente.Diretores = diretores
+	existente.DataAtualizacao = time.Now()
+	existente.Versao++
+	rm.filmes[id] = existente
+	rm.auditar(ctx, operacao, &antes, &existente)
+
+	return &existente, nil
+}
+
+// preencherFilme copia os campos editáveis; opcionais ausentes ficam vazios,
+// como as colunas NULL lidas do PostgreSQL
+func preencherFilme(destino *models.Filme, filme *models.FilmeParaCriar) {
+	destino.Titulo = filme.Titulo
+	destino.AnoLancamento = filme.AnoLancamento
+	destino.Descricao = valorOuVazio(filme.Descricao)
+	destino.Genero = valorOuVazio(filme.Genero)
+	destino.Diretor = valorOuVazio(filme.Diretor)
+	destino.DuracaoMinutos = valorOuVazio(filme.DuracaoMinutos)
+	destino.Avaliacao = nil
+	if filme.Avaliacao != nil {
+		avaliacao := *filme.Avaliacao
+		destino.Avaliacao = &avaliacao
+	}
+}
+
+func valorOuVazio[T any](valor *T) T {
+	var vazio T
+	if valor == nil {
+		return vazio
+	}
+	return *valor
+}
+
+func (rm *RepositorioMemoria) DeletarFilme(ctx context.Context, id int, versao int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+
+	existente, ok := rm.filmes[id]
+	if !ok {
+		return filmeNaoEncontrado(id)
+	}
+	if versao != QualquerVersao && existente.Versao != versao {
+		return versaoDivergente(id, versao)
+	}
+
+	antes := existente
+	agora := time.Now()
+	existente.DeletadoEm = &agora
+	existente.Versao++
+	delete(rm.filmes, id)
+	rm.lixeira[id] = existente
+	rm.auditar(ctx, models.OperacaoDeletar, &antes, &existente)
+	return nil
+}
+
+// ListarLixeira retorna os filmes excluídos, dos mais recentes aos mais antigos
+func (rm *RepositorioMemoria) ListarLixeira(ctx context.Context, limite, deslocamento int) ([]models.Filme, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	rm.mu.RLock()
+	filmes := make([]models.Filme, 0, len(rm.lixeira))
+	for _, filme := range rm.lixeira {
+		filmes = append(filmes, filme)
+	}
+	rm.mu.RUnlock()
+
+	sort.Slice(filmes, func(i, j int) bool {
+		if !filmes[i].DeletadoEm.Equal(*filmes[j].DeletadoEm) {
+			return filmes[i].DeletadoEm.After(*filmes[j].DeletadoEm)
+		}
+		return filmes[i].ID > filmes[j].ID
+	})
+
+	if deslocamento > len(filmes) {
+		deslocamento = len(filmes)
+	}
+	filmes = filmes[deslocamento:]
+	if limite > 0 && len(filmes) > limite {
+		filmes = filmes[:limite]
+	}
+	return filmes, nil
+}
+
+// RestaurarFilme devolve o filme da lixeira ao catálogo
+func (rm *RepositorioMemoria) RestaurarFilme(ctx context.Context, id int) (*models.Filme, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+
+	filme, ok := rm.lixeira[id]
+	if !ok {
+		return nil, filmeForaDaLixeira(id)
+	}
+
+	antes := filme
+	filme.DeletadoEm = nil
+	filme.Versao++
+	filme.DataAtualizacao = time.Now()
+	delete(rm.lixeira, id)
+	rm.filmes[id] = filme
+	rm.auditar(ctx, models.OperacaoRestaurar, &antes, &filme)
+	return &filme, nil
+}
+
+// ExpurgarFilme apaga definitivamente um filme da lixeira
+func (rm *RepositorioMemoria) ExpurgarFilme(ctx context.Context, id int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+
+	filme, ok := rm.lixeira[id]
+	if !ok {
+		return filmeForaDaLixeira(id)
+	}
+	delete(rm.lixeira, id)
+	delete(rm.creditos, id)
+	rm.auditar(ctx, models.OperacaoExpurgar, &filme, nil)
+	return nil
+}
+
+// ExpurgarLixeira apaga os filmes excluídos antes de antesDe
+func (rm *RepositorioMemoria) ExpurgarLixeira(ctx context.Context, antesDe time.Time) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+
+	removidos := 0
+	for id, filme := range rm.lixeira {
+		if filme.DeletadoEm.Before(antesDe) {
+			delete(rm.lixeira, id)
+			delete(rm.creditos, id)
+			rm.auditar(ctx, models.OperacaoExpurgar, &filme, nil)
+			removidos++
+		}
+	}
+	return removidos, nil
+}
+
+// auditar registra a alteração como faria a transação do PostgreSQL
+// (chamar com lock de escrita adquirido)
+func (rm *RepositorioMemoria) auditar(ctx context.Context, operacao string, antes, depois *models.Filme) {
+	rm.proximoIDAuditoria++
+	registro := models.RegistroAuditoria{
+		ID:           rm.proximoIDAuditoria,
+		Operacao:     operacao,
+		Ator:         requisicao.Ator(ctx),
+		IDRequisicao: requisicao.ID(ctx),
+		Momento:      time.Now(),
+		Antes:        copiarFilme(antes),
+		Depois:       copiarFilme(depois),
+		Alteracoes:   models.DiferencaFilmes(antes, depois),
+	}
+	if depois != nil {
+		registro.FilmeID = depois.ID
+	} else if antes != nil {
+		registro.FilmeID = antes.ID
+	}
+	rm.auditoria = append(rm.auditoria, registro)
+
+	if depois != nil {
+		rm.versoes[depois.ID] = append(rm.versoes[depois.ID], models.VersaoFilme{
+			Versao:       depois.Versao,
+			Operacao:     operacao,
+			Ator:         registro.Ator,
+			IDRequisicao: registro.IDRequisicao,
+			CriadaEm:     registro.Momento,
+			Filme:        copiarFilme(depois),
+		})
+	}
+}
+
+func copiarFilme(filme *models.Filme) *models.Filme {
+	if filme == nil {
+		return nil
+	}
+	copia := *filme
+	return &copia
+}
+
+// BuscarAuditoria percorre os registros do mais recente ao mais antigo
+func (rm *RepositorioMemoria) BuscarAuditoria(ctx context.Context, filtro *models.FiltroAuditoria) ([]models.RegistroAuditoria, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	rm.mu.RLock()
+	defer rm.mu.RUnlock()
+
+	registros := []models.RegistroAuditoria{}
+	ignorados := 0
+	for i := len(rm.auditoria) - 1; i >= 0; i-- {
+		registro := rm.auditoria[i]
+		if !atendeFiltroAuditoria(registro, filtro) {
+			continue
+		}
+		if ignorados < filtro.Deslocamento {
+			ignorados++
+			continue
+		}
+		if filtro.Limite > 0 && len(registros) == filtro.Limite {
+			break
+		}
+		registros = append(registros, registro)
+	}
+	return registros, nil
+}
+
+// atendeFiltroAuditoria replica em Go as condições de BancoDados.BuscarAuditoria
+func atendeFiltroAuditoria(registro models.RegistroAuditoria, filtro *models.FiltroAuditoria) bool {
+	switch {
+	case filtro.FilmeID != nil && registro.FilmeID != *filtro.FilmeID:
+		return false
+	case filtro.Ator != "" && registro.Ator != filtro.Ator:
+		return false
+	case filtro.Operacao != "" && registro.Operacao != filtro.Operacao:
+		return false
+	case filtro.Desde != nil && registro.Momento.Before(*filtro.Desde):
+		return false
+	case filtro.Ate != nil && registro.Momento.After(*filtro.Ate):
+		return false
+	}
+	return true
+}
+
+// ListarVersoesFilme resume as revisões, da mais recente à mais antiga
+func (rm *RepositorioMemoria) ListarVersoesFilme(ctx context.Context, id int) ([]models.VersaoFilme, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	rm.mu.RLock()
+	defer rm.mu.RUnlock()
+
+	revisoes := rm.versoes[id]
+	if _, ok := rm.filmes[id]; !ok || len(revisoes) == 0 {
+		return nil, filmeNaoEncontrado(id)
+	}
+
+	versoes := make([]models.VersaoFilme, 0, len(revisoes))
+	for i := len(revisoes) - 1; i >= 0; i-- {
+		resumo := revisoes[i]
+		resumo.Filme = nil
+		versoes = append(versoes, resumo)
+	}
+	return versoes, nil
+}
+
+func (rm *RepositorioMemoria) BuscarVersaoFilme(ctx context.Context, id, numero int) (*models.VersaoFilme, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	rm.mu.RLock()
+	defer rm.mu.RUnlock()
+
+	if _, ok := rm.filmes[id]; !ok {
+		return nil, versaoNaoEncontrada(id, numero)
+	}
+	return rm.versao(id, numero)
+}
+
+// versao procura a revisão (chamar com lock adquirido)
+func (rm *RepositorioMemoria) versao(id, numero int) (*models.VersaoFilme, error) {
+	for _, revisao := range rm.versoes[id] {
+		if revisao.Versao == numero {
+			revisao.Filme = copiarFilme(revisao.Filme)
+			return &revisao, nil
+		}
+	}
+	return nil, versaoNaoEncontrada(id, numero)
+}
+
+func (rm *RepositorioMemoria) ReverterFilme(ctx context.Context, id, numero, versaoAtual int) (*models.Filme, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+
+	alvo, err := rm.versao(id, numero)
+	if err != nil {
+		return nil, err
+	}
+	return rm.substituirFilme(ctx, models.OperacaoReverter, id, alvo.Filme.ParaCriar(), versaoAtual)
+}
+
+// filtrar retorna os resumos que atendem ao filtro (chamar com lock adquirido)
+func (rm *RepositorioMemoria) filtrar(filtro *models.FiltroFilmes) []models.FilmeResumo {
+	filmes := []models.FilmeResumo{}
+	if filtro != nil && len(filtro.Generos) > 0 {
+		filtro = rm.canonizarFiltroGeneros(filtro)
+	}
+
+	for _, filme := range rm.filmes {
+		if filtro != nil && !atendeFiltro(filme, filtro) {
+			continue
+		}
+		filmes = append(filmes, resumir(filme))
+	}
+
+	return filmes
+}
+
+// atendeFiltro replica em Go as condições de montarFiltroFilmes
+func atendeFiltro(filme models.Filme, filtro *models.FiltroFilmes) bool {
+	if len(filtro.Generos) > 0 && !temGeneros(filme, filtro.Generos, filtro.TodosGeneros) {
+		return false
+	}
+	if filtro.Diretor != "" && !strings.Contains(strings.ToLower(filme.Diretor), strings.ToLower(filtro.Diretor)) {
+		return false
+	}
+	if filtro.AnoMinimo != nil && filme.AnoLancamento < *filtro.AnoMinimo {
+		return false
+	}
+	if filtro.AnoMaximo != nil && filme.AnoLancamento > *filtro.AnoMaximo {
+		return false
+	}
+	// Como no PostgreSQL, filmes sem nota não atendem filtros de avaliação
+	if filtro.AvaliacaoMinima != nil && (filme.Avaliacao == nil || *filme.Avaliacao < *filtro.AvaliacaoMinima) {
+		return false
+	}
+	if filtro.AvaliacaoMaxima != nil && (filme.Avaliacao == nil || *filme.Avaliacao > *filtro.AvaliacaoMaxima) {
+		return false
+	}
+	return true
+}
+
+// temGeneros verifica se o filme tem algum (ou, com todos, cada um) dos slugs
+func temGeneros(filme models.Filme, slugs []string, todos bool) bool {
+	for _, slug := range slugs {
+		tem := slices.ContainsFunc(filme.Generos, func(genero models.GeneroResumo) bool {
+			return genero.Slug == slug
+		})
+		if tem != todos {
+			return tem
+		}
+	}
+	return todos
+}
+
+// compararResumos é negativo se a vem antes de b na listagem: compara pelo
+// campo de ordenação e desempata por id, na direção pedida. Filmes sem
+// avaliação ficam por último nas duas direções, como o NULLS LAST do
+// PostgreSQL.
+func compararResumos(a, b models.FilmeResumo, campo string, descendente bool) int {
+	if campo == "avaliacao" && (a.Avaliacao == nil) != (b.Avaliacao == nil) {
+		if a.Avaliacao == nil {
+			return 1
+		}
+		return -1
+	}
+
+	var resultado int
+
+	switch campo {
+	case "titulo":
+		resultado = strings.Compare(a.Titulo, b.Titulo)
+	case "genero":
+		resultado = strings.Compare(a.Genero, b.Genero)
+	case "diretor":
+		resultado = strings.Compare(a.Diretor, b.Diretor)
+	case "ano_lancamento":
+		resultado = a.AnoLancamento - b.AnoLancamento
+	case "avaliacao":
+		if a.Avaliacao != nil && b.Avaliacao != nil {
+			resultado = cmp.Compare(*a.Avaliacao, *b.Avaliacao)
+		}
+	}
+
+	if resultado == 0 {
+		resultado = a.ID - b.ID
+	}
+	if descendente {
+		return -resultado
+	}
+	return resultado
+}
+
+func ordenarResumos(filmes []models.FilmeResumo, filtro *models.FiltroFilmes) {
+	campo, descendente := "id", false
+	if filtro != nil {
+		campo, descendente = filtro.Ordenacao, filtro.Descendente
+	}
+
+	sort.Slice(filmes, func(i, j int) bool {
+		return compararResumos(filmes[i], filmes[j], campo, descendente) < 0
+	})
+}
+
+// aplicarCursorMemoria descarta os filmes até a posição indicada pelo cursor
+func aplicarCursorMemoria(filmes []models.FilmeResumo, filtro *models.FiltroFilmes) []models.FilmeResumo {
+	referencia, err := resumoDoCursor(filtro.Cursor)
+	if err != nil {
+		return []models.FilmeResumo{}
+	}
+
+	for i, filme := range filmes {
+		if compararResumos(filme, referencia, filtro.Ordenacao, filtro.Descendente) > 0 {
+			return filmes[i:]
+		}
+	}
+	return []models.FilmeResumo{}
+}
+
+// resumoDoCursor reconstrói um FilmeResumo com o valor guardado no cursor
+func resumoDoCursor(cursor *models.CursorFilmes) (models.FilmeResumo, error) {
+	referencia := models.FilmeResumo{ID: cursor.ID}
+
+	switch cursor.Campo {
+	case "titulo":
+		referencia.Titulo = cursor.Valor
+	case "genero":
+		referencia.Genero = cursor.Valor
+	case "diretor":
+		referencia.Diretor = cursor.Valor
+	case "ano_lancamento":
+		ano, err := strconv.Atoi(cursor.Valor)
+		if err != nil {
+			return referencia, err
+		}
+		referencia.AnoLancamento = ano
+	case "avaliacao":
+		if cursor.Valor == "" {
+			break
+		}
+		avaliacao, err := strconv.ParseFloat(cursor.Valor, 64)
+		if err != nil {
+			return referencia, err
+		}
+		referencia.Avaliacao = &avaliacao
+	}
+
+	return referencia, nil
+}
+
+func resumir(filme models.Filme) models.FilmeResumo {
+	return models.FilmeResumo{
+		ID:            filme.ID,
+		Titulo:        filme.Titulo,
+		AnoLancamento: filme.AnoLancamento,
+		Genero:        filme.Genero,
+		Diretor:       filme.Diretor,
+		Avaliacao:     filme.Avaliacao,
+	}
+}
+
+// normalizarTexto deixa o texto em minúsculas e sem acentos para comparação
+func normalizarTexto(texto string) string {
+	return models.RemoverAcentos(strings.ToLower(texto))
+}
+
+// filmesExemplo espelha os dados inseridos pela migração 0001_schema_inicial
+func filmesExemplo() []models.FilmeParaCriar {
+	exemplo := func(titulo, descricao string, ano, duracao int, genero, diretor string, avaliacao float64) models.FilmeParaCriar {
+		return models.FilmeParaCriar{
+			Titulo:         titulo,
+			Descricao:      &descricao,
+			AnoLancamento:  ano,
+			DuracaoMinutos: &duracao,
+			Genero:         &genero,
+			Diretor:        &diretor,
+			Avaliacao:      &avaliacao,
+		}
+	}
+
+	return []models.FilmeParaCriar{
+		exemplo("O Poderoso Chefão", "A saga de uma família mafiosa italiana nos Estados Unidos", 1972, 175, "Drama", "Francis Ford Coppola", 9.2),
+		exemplo("Cidade de Deus", "Retrato da violência urbana no Rio de Janeiro", 2002, 130, "Drama", "Fernando Meirelles", 8.6),
+		exemplo("Vingadores: Ultimato", "Os heróis se unem para derrotar Thanos", 2019, 181, "Ação", "Anthony e Joe Russo", 8.4),
+		exemplo("Parasita", "Uma família pobre se infiltra na casa de uma família rica", 2019, 132, "Thriller", "Bong Joon-ho", 8.6),
+		exemplo("Pulp Fiction", "Histórias entrelaçadas no submundo de Los Angeles", 1994, 154, "Crime", "Quentin Tarantino", 8.9),
+	}
+}
+
+// resolverDiretores replica a regra de BancoDados: IDs explícitos precisam
+// existir; sem eles, os nomes do texto legado são achados ou criados
+// (chamar com lock de escrita adquirido)
+func (rm *RepositorioMemoria) resolverDiretores(filme *models.FilmeParaCriar) ([]models.PessoaResumo, *string, error) {
+	diretores := []models.PessoaResumo{}
+
+	if len(filme.Diretores) > 0 {
+		for _, id := range filme.Diretores {
+			pessoa, ok := rm.pessoas[id]
+			if !ok {
+				return nil, nil, diretorInexistente(id)
+			}
+			diretores = append(diretores, models.PessoaResumo{ID: pessoa.ID, Nome: pessoa.Nome})
+		}
+		diretor := models.TextoDiretores(filme.Diretor, diretores)
+		return diretores, &diretor, nil
+	}
+
+	if filme.Diretor == nil {
+		return diretores, nil, nil
+	}
+
+	vistos := make(map[int]bool)
+	for _, nome := range models.SepararDiretores(*filme.Diretor) {
+		pessoa, ok := rm.pessoaPorNome(nome)
+		if !ok {
+			pessoa = rm.inserirPessoa(nome)
+		}
+		if !vistos[pessoa.ID] {
+			vistos[pessoa.ID] = true
+			diretores = append(diretores, models.PessoaResumo{ID: pessoa.ID, Nome: pessoa.Nome})
+		}
+	}
+	return diretores, filme.Diretor, nil
+}
+
+// pessoaPorNome compara nomes sem diferenciar maiúsculas, como o índice
+// único do PostgreSQL (chamar com lock adquirido)
+func (rm *RepositorioMemoria) pessoaPorNome(nome string) (models.Pessoa, bool) {
+	for _, pessoa := range rm.pessoas {
+		if strings.EqualFold(pessoa.Nome, nome) {
+			return pessoa, true
+		}
+	}
+	return models.Pessoa{}, false
+}
+
+// inserirPessoa cria a pessoa (chamar com lock de escrita adquirido)
+func (rm *RepositorioMemoria) inserirPessoa(nome string) models.Pessoa {
+	agora := time.Now()
+	pessoa := models.Pessoa{
+		ID:              rm.proximoIDPessoa,
+		Nome:            nome,
+		DataCriacao:     agora,
+		DataAtualizacao: agora,
+	}
+	rm.pessoas[pessoa.ID] = pessoa
+	rm.proximoIDPessoa++
+	return pessoa
+}
+
+// ListarPessoas retorna uma página de pessoas em ordem alfabética
+func (rm *RepositorioMemoria) ListarPessoas(ctx context.Context, filtro *models.FiltroPessoas) ([]models.Pessoa, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	rm.mu.RLock()
+	pessoas := []models.Pessoa{}
+	trecho := strings.ToLower(filtro.Nome)
+	for _, pessoa := range rm.pessoas {
+		if !strings.Contains(strings.ToLower(pessoa.Nome), trecho) {
+			continue
+		}
+		if filtro.ApenasDiretores && !rm.dirigeAlgumFilme(pessoa.ID) {
+			continue
+		}
+		pessoas = append(pessoas, pessoa)
+	}
+	rm.mu.RUnlock()
+
+	sort.Slice(pessoas, func(i, j int) bool {
+		a, b := strings.ToLower(pessoas[i].Nome), strings.ToLower(pessoas[j].Nome)
+		if a != b {
+			return a < b
+		}
+		return pessoas[i].ID < pessoas[j].ID
+	})
+
+	deslocamento := filtro.Deslocamento
+	if deslocamento > len(pessoas) {
+		deslocamento = len(pessoas)
+	}
+	pessoas = pessoas[deslocamento:]
+	if filtro.Limite > 0 && len(pessoas) > filtro.Limite {
+		pessoas = pessoas[:filtro.Limite]
+	}
+	return pessoas, nil
+}
+
+func (rm *RepositorioMemoria) BuscarPessoaPorID(ctx context.Context, id int) (*models.Pessoa, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	rm.mu.RLock()
+	defer rm.mu.RUnlock()
+
+	pessoa, ok := rm.pessoas[id]
+	if !ok {
+		return nil, pessoaNaoEncontrada(id)
+	}
+	return &pessoa, nil
+}
+
+func (rm *RepositorioMemoria) CriarPessoa(ctx context.Context, pessoa *models.PessoaParaCriar) (*models.Pessoa, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+
+	if _, existe := rm.pessoaPorNome(pessoa.Nome); existe {
+		return nil, &ErroBanco{Tipo: ErrConflito, Mensagem: "já existe um registro com estes dados"}
+	}
+	criada := rm.inserirPessoa(pessoa.Nome)
+	return &criada, nil
+}
+
+// AtualizarPessoa renomeia a pessoa e gera uma nova versão de cada filme
+// que ela dirige, como BancoDados.AtualizarPessoa
+func (rm *RepositorioMemoria) AtualizarPessoa(ctx context.Context, id int, pessoa *models.PessoaParaCriar) (*models.Pessoa, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+
+	existente, ok := rm.pessoas[id]
+	if !ok {
+		return nil, pessoaNaoEncontrada(id)
+	}
+	if outra, existe := rm.pessoaPorNome(pessoa.Nome); existe && outra.ID != id {
+		return nil, &ErroBanco{Tipo: ErrConflito, Mensagem: "já existe um registro com estes dados"}
+	}
+
+	agora := time.Now()
+	renomear := existente.Nome != pessoa.Nome
+	existente.Nome = pessoa.Nome
+	existente.DataAtualizacao = agora
+	rm.pessoas[id] = existente
+	if !renomear {
+		return &existente, nil
+	}
+
+	for _, filmes := range []map[int]models.Filme{rm.filmes, rm.lixeira} {
+		for filmeID, filme := range filmes {
+			if !dirigidoPor(filme, id) {
+				continue
+			}
+			antes := filme
+			filme.Diretores = renomearPessoa(filme.Diretores, id, pessoa.Nome)
+			filme.Diretor = models.TextoDiretores(&filme.Diretor, filme.Diretores)
+			filme.DataAtualizacao = agora
+			filme.Versao++
+			filmes[filmeID] = filme
+			rm.auditar(ctx, models.OperacaoAtualizar, &antes, &filme)
+		}
+	}
+	return &existente, nil
+}
+
+// DeletarPessoa apaga uma pessoa que não dirige nem tem créditos em nenhum
+// filme, nem na lixeira
+func (rm *RepositorioMemoria) DeletarPessoa(ctx context.Context, id int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+
+	if _, ok := rm.pessoas[id]; !ok {
+		return pessoaNaoEncontrada(id)
+	}
+	for _, filmes := range []map[int]models.Filme{rm.filmes, rm.lixeira} {
+		for _, filme := range filmes {
+			if dirigidoPor(filme, id) {
+				return pessoaComFilmes(id)
+			}
+		}
+	}
+	for _, creditos := range rm.creditos {
+		for _, credito := range creditos {
+			if credito.Pessoa.ID == id {
+				return pessoaComFilmes(id)
+			}
+		}
+	}
+	delete(rm.pessoas, id)
+	return nil
+}
+
+// FilmesDoDiretor lista os filmes ativos dirigidos pela pessoa, do mais antigo ao mais recente
+func (rm *RepositorioMemoria) FilmesDoDiretor(ctx context.Context, id int) ([]models.FilmeResumo, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	rm.mu.RLock()
+	defer rm.mu.RUnlock()
+
+	if _, ok := rm.pessoas[id]; !ok {
+		return nil, pessoaNaoEncontrada(id)
+	}
+
+	filmes := []models.FilmeResumo{}
+	for _, filme := range rm.filmes {
+		if dirigidoPor(filme, id) {
+			filmes = append(filmes, resumir(filme))
+		}
+	}
+	sort.Slice(filmes, func(i, j int) bool {
+		if filmes[i].AnoLancamento != filmes[j].AnoLancamento {
+			return filmes[i].AnoLancamento < filmes[j].AnoLancamento
+		}
+		return filmes[i].ID < filmes[j].ID
+	})
+	return filmes, nil
+}
+
+func dirigidoPor(filme models.Filme, pessoaID int) bool {
+	for _, diretor := range filme.Diretores {
+		if diretor.ID == pessoaID {
+			return true
+		}
+	}
+	return false
+}
+
+// dirigeAlgumFilme indica se a pessoa dirige algum filme ativo (chamar com
+// lock adquirido)
+func (rm *RepositorioMemoria) dirigeAlgumFilme(pessoaID int) bool {
+	for _, filme := range rm.filmes {
+		if dirigidoPor(filme, pessoaID) {
+			return true
+		}
+	}
+	return false
+}
+
+// ListarCreditos retorna os créditos do filme na ordem de exibição
+func (rm *RepositorioMemoria) ListarCreditos(ctx context.Context, filmeID int, funcao string) ([]models.Credito, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	rm.mu.RLock()
+	defer rm.mu.RUnlock()
+
+	if _, ok := rm.filmes[filmeID]; !ok {
+		return nil, filmeNaoEncontrado(filmeID)
+	}
+
+	creditos := []models.Credito{}
+	for _, credito := range rm.creditos[filmeID] {
+		if funcao == "" || credito.Funcao == funcao {
+			credito.Pessoa.Nome = rm.pessoas[credito.Pessoa.ID].Nome
+			creditos = append(creditos, credito)
+		}
+	}
+	sort.SliceStable(creditos, func(i, j int) bool {
+		if creditos[i].Posicao != creditos[j].Posicao {
+			return creditos[i].Posicao < creditos[j].Posicao
+		}
+		return creditos[i].ID < creditos[j].ID
+	})
+	return creditos, nil
+}
+
+// CriarCredito adiciona um crédito com as mesmas regras de BancoDados.CriarCredito
+func (rm *RepositorioMemoria) CriarCredito(ctx context.Context, filmeID int, credito *models.CreditoParaCriar) (*models.Credito, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+
+	if _, ok := rm.filmes[filmeID]; !ok {
+		return nil, filmeNaoEncontrado(filmeID)
+	}
+
+	var pessoa models.Pessoa
+	if credito.PessoaID != nil {
+		var ok bool
+		if pessoa, ok = rm.pessoas[*credito.PessoaID]; !ok {
+			return nil, pessoaInexistente(*credito.PessoaID)
+		}
+	} else if existente, ok := rm.pessoaPorNome(credito.Nome); ok {
+		pessoa = existente
+	} else {
+		pessoa = rm.inserirPessoa(credito.Nome)
+	}
+
+	novo := models.Credito{
+		ID:         rm.proximoIDCredito,
+		Pessoa:     models.PessoaResumo{ID: pessoa.ID, Nome: pessoa.Nome},
+		Funcao:     credito.Funcao,
+		Personagem: valorOuVazio(credito.Personagem),
+		Posicao:    valorOuVazio(credito.Posicao),
+	}
+
+	ultimaPosicao := 0
+	for _, existente := range rm.creditos[filmeID] {
+		if existente.Pessoa.ID == novo.Pessoa.ID && existente.Funcao == novo.Funcao && existente.Personagem == novo.Personagem {
+			return nil, &ErroBanco{Tipo: ErrConflito, Mensagem: "já existe um registro com estes dados"}
+		}
+		ultimaPosicao = max(ultimaPosicao, existente.Posicao)
+	}
+	if novo.Posicao == 0 {
+		novo.Posicao = ultimaPosicao + 1
+	}
+
+	rm.creditos[filmeID] = append(rm.creditos[filmeID], novo)
+	rm.proximoIDCredito++
+	return &novo, nil
+}
+
+// DeletarCredito remove um crédito do filme
+func (rm *RepositorioMemoria) DeletarCredito(ctx context.Context, filmeID, id int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+
+	creditos := rm.creditos[filmeID]
+	for i, credito := range creditos {
+		if credito.ID == id {
+			rm.creditos[filmeID] = append(creditos[:i:i], creditos[i+1:]...)
+			return nil
+		}
+	}
+	return creditoNaoEncontrado(filmeID, id)
+}
+
+// Filmografia lista as participações da pessoa em filmes ativos, incluindo a direção
+func (rm *RepositorioMemoria) Filmografia(ctx context.Context, pessoaID int) ([]models.ItemFilmografia, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	rm.mu.RLock()
+	defer rm.mu.RUnlock()
+
+	if _, ok := rm.pessoas[pessoaID]; !ok {
+		return nil, pessoaNaoEncontrada(pessoaID)
+	}
+
+	type participacao struct {
+		item    models.ItemFilmografia
+		posicao int
+	}
+	var participacoes []participacao
+	for _, filme := range rm.filmes {
+		if dirigidoPor(filme, pessoaID) {
+			participacoes = append(participacoes, participacao{item: itemFilmografia(filme, models.FuncaoDiretor, "")})
+		}
+		for _, credito := range rm.creditos[filme.ID] {
+			if credito.Pessoa.ID == pessoaID {
+				participacoes = append(participacoes, participacao{
+					item:    itemFilmografia(filme, credito.Funcao, credito.Personagem),
+					posicao: credito.Posicao,
+				})
+			}
+		}
+	}
+
+	sort.Slice(participacoes, func(i, j int) bool {
+		a, b := participacoes[i], participacoes[j]
+		if a.item.AnoLancamento != b.item.AnoLancamento {
+			return a.item.AnoLancamento < b.item.AnoLancamento
+		}
+		if a.item.FilmeID != b.item.FilmeID {
+			return a.item.FilmeID < b.item.FilmeID
+		}
+		return a.posicao < b.posicao
+	})
+
+	filmografia := make([]models.ItemFilmografia, 0, len(participacoes))
+	for _, p := range participacoes {
+		filmografia = append(filmografia, p.item)
+	}
+	return filmografia, nil
+}
+
+func itemFilmografia(filme models.Filme, funcao, personagem string) models.ItemFilmografia {
+	return models.ItemFilmografia{
+		FilmeID:       filme.ID,
+		Titulo:        filme.Titulo,
+		AnoLancamento: filme.AnoLancamento,
+		Funcao:        funcao,
+		Personagem:    personagem,
+	}
+}
+
+// generosExemplo espelha a taxonomia inserida pela migração 0009_generos
+func generosExemplo() []models.GeneroParaCriar {
+	genero := func(nome string, sinonimos ...string) models.GeneroParaCriar {
+		return models.GeneroParaCriar{Nome: nome, Sinonimos: append([]string{}, sinonimos...)}
+	}
+
+	return []models.GeneroParaCriar{
+		genero("Ação", "action"),
+		genero("Animação", "animation"),
+		genero("Aventura", "adventure"),
+		genero("Biografia", "biography", "biopic"),
+		genero("Comédia", "comedy"),
+		genero("Crime", "policial"),
+		genero("Documentário", "documentary"),
+		genero("Drama"),
+		genero("Família", "family"),
+		genero("Fantasia", "fantasy"),
+		genero("Faroeste", "western"),
+		genero("Ficção Científica", "sci-fi", "science-fiction", "ficcao"),
+		genero("Guerra", "war"),
+		genero("História", "history", "historico"),
+		genero("Mistério", "mystery"),
+		genero("Musical"),
+		genero("Romance"),
+		genero("Suspense", "thriller"),
+		genero("Terror", "horror"),
+	}
+}
+
+// inserirGenero cadastra o gênero (chamar com lock de escrita adquirido)
+func (rm *RepositorioMemoria) inserirGenero(genero models.GeneroParaCriar) models.Genero {
+	novo := models.Genero{
+		ID:        rm.proximoIDGenero,
+		Nome:      genero.Nome,
+		Slug:      models.Slug(genero.Nome),
+		Sinonimos: append([]string{}, genero.Sinonimos...),
+	}
+	rm.generos[novo.ID] = novo
+	rm.proximoIDGenero++
+	return novo
+}
+
+// catalogoGeneros indexa a taxonomia atual (chamar com lock adquirido)
+func (rm *RepositorioMemoria) catalogoGeneros() *models.CatalogoGeneros {
+	generos := make([]models.Genero, 0, len(rm.generos))
+	for _, genero := range rm.generos {
+		generos = append(generos, genero)
+	}
+	return models.NovoCatalogoGeneros(generos)
+}
+
+// resolverGeneros replica a regra de BancoDados: slugs, nomes e sinônimos
+// precisam estar na taxonomia e o texto de exibição segue
+// models.TextoGeneros (chamar com lock de escrita adquirido)
+func (rm *RepositorioMemoria) resolverGeneros(filme *models.FilmeParaCriar) ([]models.GeneroResumo, *string, error) {
+	nomes := filme.Generos
+	if len(nomes) == 0 && filme.Genero != nil {
+		nomes = models.SepararGeneros(*filme.Genero)
+	}
+
+	generos := []models.GeneroResumo{}
+	catalogo := rm.catalogoGeneros()
+	vistos := make(map[string]bool)
+	for _, nome := range nomes {
+		genero, ok := catalogo.Buscar(nome)
+		if !ok {
+			return nil, nil, generoInexistente(models.Slug(nome))
+		}
+		if !vistos[genero.Slug] {
+			vistos[genero.Slug] = true
+			generos = append(generos, models.GeneroResumo{Slug: genero.Slug, Nome: genero.Nome})
+		}
+	}
+	return generos, models.TextoGeneros(filme.Genero, generos, catalogo.Slug), nil
+}
+
+// canonizarFiltroGeneros troca sinônimos do filtro pelos slugs, como a
+// comparação com sinonimos feita em SQL (chamar com lock adquirido)
+func (rm *RepositorioMemoria) canonizarFiltroGeneros(filtro *models.FiltroFilmes) *models.FiltroFilmes {
+	copia := *filtro
+	copia.Generos = make([]string, len(filtro.Generos))
+	catalogo := rm.catalogoGeneros()
+	for i, valor := range filtro.Generos {
+		copia.Generos[i] = valor
+		if genero, ok := catalogo.Buscar(valor); ok {
+			copia.Generos[i] = genero.Slug
+		}
+	}
+	return &copia
+}
+
+// ListarGeneros retorna a taxonomia inteira em ordem alfabética
+func (rm *RepositorioMemoria) ListarGeneros(ctx context.Context) ([]models.Genero, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	rm.mu.RLock()
+	defer rm.mu.RUnlock()
+
+	generos := make([]models.Genero, 0, len(rm.generos))
+	for _, genero := range rm.generos {
+		genero.TotalFilmes = 0
+		for _, filme := range rm.filmes {
+			if temGeneros(filme, []string{genero.Slug}, false) {
+				genero.TotalFilmes++
+			}
+		}
+		generos = append(generos, genero)
+	}
+
+	sort.Slice(generos, func(i, j int) bool {
+		a, b := normalizarTexto(generos[i].Nome), normalizarTexto(generos[j].Nome)
+		if a != b {
+			return a < b
+		}
+		return generos[i].ID < generos[j].ID
+	})
+	return generos, nil
+}
+
+// ListarGenerosCatalogo retorna a taxonomia sem contar filmes
+func (rm *RepositorioMemoria) ListarGenerosCatalogo(ctx context.Context) ([]models.Genero, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	rm.mu.RLock()
+	defer rm.mu.RUnlock()
+
+	generos := make([]models.Genero, 0, len(rm.generos))
+	for _, genero := range rm.generos {
+		genero.Sinonimos = append([]string{}, genero.Sinonimos...)
+		generos = append(generos, genero)
+	}
+	return generos, nil
+}
+
+func (rm *RepositorioMemoria) CriarGenero(ctx context.Context, genero *models.GeneroParaCriar) (*models.Genero, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+
+	slug := models.Slug(genero.Nome)
+	for _, existente := range rm.generos {
+		if existente.Slug == slug {
+			return nil, &ErroBanco{Tipo: ErrConflito, Mensagem: "já existe um registro com estes dados"}
+		}
+	}
+	criado := rm.inserirGenero(*genero)
+	return &criado, nil
+}
+
+func (rm *RepositorioMemoria) CriarUsuario(ctx context.Context, usuario *models.UsuarioParaRegistrar, senhaHash string) (*models.Usuario, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+
+	if _, existe := rm.usuarioPorEmail(usuario.Email); existe {
+		return nil, emailJaCadastrado()
+	}
+
+	agora := time.Now()
+	criado := models.Usuario{
+		ID:              rm.proximoIDUsuario,
+		Nome:            usuario.Nome,
+		Email:           usuario.Email,
+		SenhaHash:       senhaHash,
+		DataCriacao:     agora,
+		DataAtualizacao: agora,
+	}
+	rm.usuarios[criado.ID] = criado
+	rm.proximoIDUsuario++
+
+	return &criado, nil
+}
+
+func (rm *RepositorioMemoria) BuscarUsuarioPorEmail(ctx context.Context, email string) (*models.Usuario, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	rm.mu.RLock()
+	defer rm.mu.RUnlock()
+
+	usuario, ok := rm.usuarioPorEmail(email)
+	if !ok {
+		return nil, usuarioNaoEncontrado()
+	}
+	return &usuario, nil
+}
+
+// usuarioPorEmail compara sem diferenciar maiúsculas, como o índice único
+// do PostgreSQL (chamar com lock adquirido)
+func (rm *RepositorioMemoria) usuarioPorEmail(email string) (models.Usuario, bool) {
+	for _, usuario := range rm.usuarios {
+		if strings.EqualFold(usuario.Email, email) {
+			return usuario, true
+		}
+	}
+	return models.Usuario{}, false
+}
+
+func (rm *RepositorioMemoria) BuscarTentativasLogin(ctx context.Context, email string) (*models.TentativasLogin, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	rm.mu.RLock()
+	defer rm.mu.RUnlock()
+
+	tentativas := rm.tentativas[email]
+	tentativas.Email = email
+	return &tentativas, nil
+}
+
+// RegistrarFalhaLogin aplica a mesma regra de BancoDados.RegistrarFalhaLogin
+func (rm *RepositorioMemoria) RegistrarFalhaLogin(ctx context.Context, email string, maximo int, falhasDesde, bloqueadoAte time.Time) (*models.TentativasLogin, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+
+	tentativas := rm.tentativas[email]
+	tentativas.Email = email
+	if tentativas.AtualizadoEm.Before(falhasDesde) {
+		tentativas.Falhas = 0
+	}
+	tentativas.Falhas++
+	tentativas.AtualizadoEm = time.Now()
+	if tentativas.Falhas >= maximo {
+		tentativas.Falhas = 0
+		tentativas.BloqueadoAte = &bloqueadoAte
+	}
+	rm.tentativas[email] = tentativas
+
+	return &tentativas, nil
+}
+
+// ExpurgarTentativasLogin aplica a mesma regra de BancoDados.ExpurgarTentativasLogin
+func (rm *RepositorioMemoria) ExpurgarTentativasLogin(ctx context.Context, antesDe time.Time) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+
+	agora := time.Now()
+	removidos := 0
+	for email, tentativas := range rm.tentativas {
+		if tentativas.AtualizadoEm.Before(antesDe) && !tentativas.Bloqueado(agora) {
+			delete(rm.tentativas, email)
+			removidos++
+		}
+	}
+	return removidos, nil
+}
+
+func (rm *RepositorioMemoria) RegistrarLoginSucesso(ctx context.Context, id int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+
+	usuario, ok := rm.usuarios[id]
+	if !ok {
+		return usuarioNaoEncontrado()
+	}
+
+	agora := time.Now()
+	usuario.UltimoLogin = &agora
+	rm.usuarios[id] = usuario
+	delete(rm.tentativas, models.NormalizarEmail(usuario.Email))
+
+	return nil
+}
+
+func (rm *RepositorioMemoria) CriarSessao(ctx context.Context, sessao *models.Sessao) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+
+	if _, ok := rm.usuarios[sessao.UsuarioID]; !ok {
+		return usuarioNaoEncontrado()
+	}
+	rm.sessoes[sessao.TokenHash] = *sessao
+
+	return nil
+}
+
+// RevogarSessao apaga a sessão: sem consultas ao histórico, não há por que
+// guardar sessões revogadas em memória
+func (rm *RepositorioMemoria) RevogarSessao(ctx context.Context, tokenHash string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+
+	sessao, ok := rm.sessoes[tokenHash]
+	if !ok || !time.Now().Before(sessao.ExpiraEm) {
+		return sessaoNaoEncontrada()
+	}
+	delete(rm.sessoes, tokenHash)
+
+	return nil
+}
+
+func (rm *RepositorioMemoria) RenovarSessao(ctx context.Context, tokenHash string, nova *models.Sessao) (*models.Usuario, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+
+	antiga, ok := rm.sessoes[tokenHash]
+	if !ok || !nova.DataCriacao.Before(antiga.ExpiraEm) {
+		return nil, sessaoNaoEncontrada()
+	}
+	usuario, ok := rm.usuarios[antiga.UsuarioID]
+	if !ok {
+		return nil, sessaoNaoEncontrada()
+	}
+
+	delete(rm.sessoes, tokenHash)
+	nova.UsuarioID = antiga.UsuarioID
+	rm.sessoes[nova.TokenHash] = *nova
+
+	return &usuario, nil
+}
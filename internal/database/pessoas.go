@@ -0,0 +1,346 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"api-filmes/internal/models"
+
+	"github.com/lib/pq"
+)
+
+// resolverDiretores transforma os diretores pedidos em pessoas. IDs
+// explícitos precisam existir e definem o texto de exibição, mantido se
+// ainda corresponder a eles (models.TextoDiretores); sem eles, o
+// texto legado é separado em nomes e cada nome é achado ou criado.
+func resolverDiretores(ctx context.Context, tx *sql.Tx, filme *models.FilmeParaCriar) ([]models.PessoaResumo, *string, error) {
+	if len(filme.Diretores) > 0 {
+		diretores, err := pessoasPorID(ctx, tx, filme.Diretores)
+		if err != nil {
+			return nil, nil, err
+		}
+		diretor := models.TextoDiretores(filme.Diretor, diretores)
+		return diretores, &diretor, nil
+	}
+
+	if filme.Diretor == nil {
+		return []models.PessoaResumo{}, nil, nil
+	}
+
+	diretores := []models.PessoaResumo{}
+	vistos := make(map[int]bool)
+	for _, nome := range models.SepararDiretores(*filme.Diretor) {
+		pessoa, err := acharOuCriarPessoa(ctx, tx, nome)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !vistos[pessoa.ID] {
+			vistos[pessoa.ID] = true
+			diretores = append(diretores, pessoa)
+		}
+	}
+	return diretores, filme.Diretor, nil
+}
+
+// acharOuCriarPessoa devolve a pessoa com o nome (sem diferenciar
+// maiúsculas), criando-a se ainda não existir
+func acharOuCriarPessoa(ctx context.Context, tx *sql.Tx, nome string) (models.PessoaResumo, error) {
+	query := `
+        INSERT INTO pessoas (nome) VALUES ($1)
+        ON CONFLICT ((LOWER(nome))) DO UPDATE SET nome = pessoas.nome
+        RETURNING id, nome
+    `
+
+	var pessoa models.PessoaResumo
+	err := tx.QueryRowContext(ctx, query, nome).Scan(&pessoa.ID, &pessoa.Nome)
+	return pessoa, err
+}
+
+// pessoasPorID busca as pessoas na ordem dos IDs informados
+func pessoasPorID(ctx context.Context, tx *sql.Tx, ids []int) ([]models.PessoaResumo, error) {
+	linhas, err := tx.QueryContext(ctx, "SELECT id, nome FROM pessoas WHERE id = ANY($1)", pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer linhas.Close()
+
+	nomes := make(map[int]string, len(ids))
+	for linhas.Next() {
+		var id int
+		var nome string
+		if err := linhas.Scan(&id, &nome); err != nil {
+			return nil, err
+		}
+		nomes[id] = nome
+	}
+	if err := linhas.Err(); err != nil {
+		return nil, err
+	}
+
+	pessoas := make([]models.PessoaResumo, 0, len(ids))
+	for _, id := range ids {
+		nome, ok := nomes[id]
+		if !ok {
+			return nil, diretorInexistente(id)
+		}
+		pessoas = append(pessoas, models.PessoaResumo{ID: id, Nome: nome})
+	}
+	return pessoas, nil
+}
+
+// vincularDiretores substitui os diretores do filme e atualiza filme.Diretores,
+// já que o RETURNING da escrita ainda enxerga os vínculos anteriores
+func vincularDiretores(ctx context.Context, tx *sql.Tx, filme *models.Filme, diretores []models.PessoaResumo) error {
+	if _, err := tx.ExecContext(ctx, "DELETE FROM filme_diretores WHERE filme_id = $1", filme.ID); err != nil {
+		return err
+	}
+
+	ids := make([]int, len(diretores))
+	for i, diretor := range diretores {
+		ids[i] = diretor.ID
+	}
+
+	query := `
+        INSERT INTO filme_diretores (filme_id, pessoa_id, posicao)
+        SELECT $1, pessoa, posicao FROM unnest($2::int[]) WITH ORDINALITY AS d(pessoa, posicao)
+    `
+	if _, err := tx.ExecContext(ctx, query, filme.ID, pq.Array(ids)); err != nil {
+		return err
+	}
+
+	filme.Diretores = diretores
+	return nil
+}
+
+// colunasPessoa lista as colunas lidas por escanearPessoa
+const colunasPessoa = "id, nome, data_criacao, data_atualizacao"
+
+func escanearPessoa(linha linhaEscaneavel) (*models.Pessoa, error) {
+	var pessoa models.Pessoa
+	if err := linha.Scan(&pessoa.ID, &pessoa.Nome, &pessoa.DataCriacao, &pessoa.DataAtualizacao); err != nil {
+		return nil, err
+	}
+	return &pessoa, nil
+}
+
+// ListarPessoas retorna uma página de pessoas em ordem alfabética
+func (bd *BancoDados) ListarPessoas(ctx context.Context, filtro *models.FiltroPessoas) ([]models.Pessoa, error) {
+	consulta := &consultaFiltrada{}
+	if filtro.Nome != "" {
+		consulta.adicionar("nome ILIKE ?", "%"+escaparLike(filtro.Nome)+"%")
+	}
+	if filtro.ApenasDiretores {
+		consulta.adicionar(`EXISTS (
+            SELECT 1 FROM filme_diretores fd
+            JOIN filmes f ON f.id = fd.filme_id
+            WHERE fd.pessoa_id = pessoas.id AND f.deletado_em IS NULL)`)
+	}
+
+	consulta.args = append(consulta.args, filtro.Limite, filtro.Deslocamento)
+	query := fmt.Sprintf(`
+        SELECT %s
+        FROM pessoas
+        %s
+        ORDER BY LOWER(nome), id
+        LIMIT $%d OFFSET $%d
+    `, colunasPessoa, consulta.where(), len(consulta.args)-1, len(consulta.args))
+
+	linhas, err := bd.conexao.QueryContext(ctx, query, consulta.args...)
+	if err != nil {
+		return nil, traduzirErro(err, "erro ao listar pessoas")
+	}
+	defer linhas.Close()
+
+	pessoas := []models.Pessoa{}
+	for linhas.Next() {
+		pessoa, err := escanearPessoa(linhas)
+		if err != nil {
+			return nil, traduzirErro(err, "erro ao ler dados da pessoa")
+		}
+		pessoas = append(pessoas, *pessoa)
+	}
+	if err := linhas.Err(); err != nil {
+		return nil, traduzirErro(err, "erro durante leitura dos resultados")
+	}
+
+	return pessoas, nil
+}
+
+func (bd *BancoDados) BuscarPessoaPorID(ctx context.Context, id int) (*models.Pessoa, error) {
+	query := "SELECT " + colunasPessoa + " FROM pessoas WHERE id = $1"
+
+	pessoa, err := escanearPessoa(bd.conexao.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, pessoaNaoEncontrada(id)
+		}
+		return nil, traduzirErro(err, "erro ao buscar pessoa")
+	}
+
+	return pessoa, nil
+}
+
+// CriarPessoa insere uma pessoa; nomes repetidos (sem diferenciar
+// maiúsculas) resultam em ErrConflito
+func (bd *BancoDados) CriarPessoa(ctx context.Context, pessoa *models.PessoaParaCriar) (*models.Pessoa, error) {
+	query := "INSERT INTO pessoas (nome) VALUES ($1) RETURNING " + colunasPessoa
+
+	criada, err := escanearPessoa(bd.conexao.QueryRowContext(ctx, query, pessoa.Nome))
+	if err != nil {
+		return nil, traduzirErro(err, "erro ao criar pessoa")
+	}
+
+	return criada, nil
+}
+
+// AtualizarPessoa renomeia a pessoa e recalcula o texto de exibição dos
+// filmes em que ela é diretora. Cada filme afetado ganha uma nova versão,
+// registrada na auditoria, para que o ETag acompanhe a mudança de nome.
+func (bd *BancoDados) AtualizarPessoa(ctx context.Context, id int, pessoa *models.PessoaParaCriar) (*models.Pessoa, error) {
+	query := `
+        UPDATE pessoas AS p
+        SET nome = $2, data_atualizacao = $3
+        FROM (SELECT id, nome FROM pessoas WHERE id = $1 FOR UPDATE) AS antiga
+        WHERE p.id = antiga.id
+        RETURNING antiga.nome, p.id, p.nome, p.data_criacao, p.data_atualizacao
+    `
+
+	var atualizada models.Pessoa
+	err := bd.emTransacao(ctx, func(tx *sql.Tx) error {
+		var nomeAnterior string
+		agora := time.Now()
+		err := tx.QueryRowContext(ctx, query, id, pessoa.Nome, agora).Scan(&nomeAnterior,
+			&atualizada.ID, &atualizada.Nome, &atualizada.DataCriacao, &atualizada.DataAtualizacao)
+		if err != nil || nomeAnterior == atualizada.Nome {
+			return err
+		}
+
+		filmes, err := filmesComDiretor(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+
+		for _, filmeID := range filmes {
+			antes, depois, err := alterarFilmeTx(ctx, tx, "id = $1",
+				"data_atualizacao = $2, versao = f.versao + 1", filmeID, agora)
+			if err != nil {
+				return err
+			}
+
+			// depois.Diretores já traz o novo nome; o texto de exibição só é
+			// recalculado se deixou de descrever os diretores
+			depois.Diretor = models.TextoDiretores(&depois.Diretor, depois.Diretores)
+			if _, err := tx.ExecContext(ctx, "UPDATE filmes SET diretor = $2 WHERE id = $1", filmeID, depois.Diretor); err != nil {
+				return err
+			}
+
+			// A subconsulta de antes já enxerga o novo nome
+			antes.Diretores = renomearPessoa(antes.Diretores, id, nomeAnterior)
+			if err := registrarAlteracao(ctx, tx, models.OperacaoAtualizar, antes, depois); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, pessoaNaoEncontrada(id)
+		}
+		return nil, traduzirErro(err, "erro ao atualizar pessoa")
+	}
+
+	return &atualizada, nil
+}
+
+// filmesComDiretor lista os IDs dos filmes (inclusive na lixeira) dirigidos pela pessoa
+func filmesComDiretor(ctx context.Context, tx *sql.Tx, pessoaID int) ([]int, error) {
+	linhas, err := tx.QueryContext(ctx, "SELECT filme_id FROM filme_diretores WHERE pessoa_id = $1 ORDER BY filme_id", pessoaID)
+	if err != nil {
+		return nil, err
+	}
+	defer linhas.Close()
+
+	var ids []int
+	for linhas.Next() {
+		var id int
+		if err := linhas.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, linhas.Err()
+}
+
+// renomearPessoa devolve uma cópia da lista com o nome da pessoa trocado
+func renomearPessoa(pessoas []models.PessoaResumo, id int, nome string) []models.PessoaResumo {
+	copia := make([]models.PessoaResumo, len(pessoas))
+	for i, pessoa := range pessoas {
+		if pessoa.ID == id {
+			pessoa.Nome = nome
+		}
+		copia[i] = pessoa
+	}
+	return copia
+}
+
+// DeletarPessoa apaga uma pessoa que não dirige nem tem créditos em nenhum filme
+func (bd *BancoDados) DeletarPessoa(ctx context.Context, id int) error {
+	query := `
+        DELETE FROM pessoas
+        WHERE id = $1
+            AND NOT EXISTS (SELECT 1 FROM filme_diretores WHERE pessoa_id = $1)
+            AND NOT EXISTS (SELECT 1 FROM creditos WHERE pessoa_id = $1)
+        RETURNING id
+    `
+
+	err := bd.conexao.QueryRowContext(ctx, query, id).Scan(&id)
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return traduzirErro(err, "erro ao deletar pessoa")
+	}
+
+	if _, err := bd.BuscarPessoaPorID(ctx, id); err != nil {
+		return err
+	}
+	return pessoaComFilmes(id)
+}
+
+// FilmesDoDiretor lista os filmes ativos dirigidos pela pessoa, do mais antigo ao mais recente
+func (bd *BancoDados) FilmesDoDiretor(ctx context.Context, id int) ([]models.FilmeResumo, error) {
+	if _, err := bd.BuscarPessoaPorID(ctx, id); err != nil {
+		return nil, err
+	}
+
+	query := `
+        SELECT f.id, f.titulo, f.ano_lancamento, COALESCE(f.genero, ''), COALESCE(f.diretor, ''), f.avaliacao
+        FROM filmes f
+        JOIN filme_diretores fd ON fd.filme_id = f.id
+        WHERE fd.pessoa_id = $1 AND f.deletado_em IS NULL
+        ORDER BY f.ano_lancamento, f.id
+    `
+
+	linhas, err := bd.conexao.QueryContext(ctx, query, id)
+	if err != nil {
+		return nil, traduzirErro(err, "erro ao listar filmes da pessoa")
+	}
+	defer linhas.Close()
+
+	filmes := []models.FilmeResumo{}
+	for linhas.Next() {
+		var filme models.FilmeResumo
+		if err := linhas.Scan(&filme.ID, &filme.Titulo, &filme.AnoLancamento, &filme.Genero, &filme.Diretor, &filme.Avaliacao); err != nil {
+			return nil, traduzirErro(err, "erro ao ler dados do filme")
+		}
+		filmes = append(filmes, filme)
+	}
+	if err := linhas.Err(); err != nil {
+		return nil, traduzirErro(err, "erro durante leitura dos resultados")
+	}
+
+	return filmes, nil
+}
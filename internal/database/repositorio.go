@@ -0,0 +1,135 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"api-filmes/internal/models"
+)
+
+// QualquerVersao dispensa a verificação de versão em AtualizarFilme e DeletarFilme
+const QualquerVersao = 0
+
+// FilmeRepositorio define as operações de persistência usadas pelos handlers.
+// BancoDados (PostgreSQL) e RepositorioMemoria implementam esta interface.
+type FilmeRepositorio interface {
+	BuscarTodosFilmes(ctx context.Context, filtro *models.FiltroFilmes) ([]models.FilmeResumo, error)
+	BuscarFilmePorID(ctx context.Context, id int) (*models.Filme, error)
+	BuscarFilmesPorTexto(ctx context.Context, termo string, limite int) ([]models.ResultadoBusca, error)
+	ContarFilmes(ctx context.Context, filtro *models.FiltroFilmes) (int, error)
+	// CriarFilme liga o filme às pessoas de filme.Diretores ou, sem elas, às
+	// pessoas nomeadas no texto legado filme.Diretor, criando as que faltarem
+	CriarFilme(ctx context.Context, filme *models.FilmeParaCriar) (*models.Filme, error)
+	// AtualizarFilme substitui o filme inteiro: opcionais nil viram NULL.
+	// Com versao diferente de QualquerVersao, só altera se o filme ainda
+	// estiver nessa versão; caso contrário retorna ErrPrecondicao.
+	AtualizarFilme(ctx context.Context, id int, filme *models.FilmeParaCriar, versao int) (*models.Filme, error)
+	// DeletarFilme move o filme para a lixeira; leituras passam a ignorá-lo
+	DeletarFilme(ctx context.Context, id int, versao int) error
+	// ListarLixeira retorna os filmes excluídos, dos mais recentes aos mais antigos
+	ListarLixeira(ctx context.Context, limite, deslocamento int) ([]models.Filme, error)
+	RestaurarFilme(ctx context.Context, id int) (*models.Filme, error)
+	// ExpurgarFilme apaga definitivamente um filme que está na lixeira
+	ExpurgarFilme(ctx context.Context, id int) error
+	// ExpurgarLixeira apaga definitivamente os filmes excluídos antes do instante informado
+	ExpurgarLixeira(ctx context.Context, antesDe time.Time) (int, error)
+	// BuscarAuditoria lista as alterações registradas por todas as operações
+	// de escrita acima, do registro mais recente ao mais antigo
+	BuscarAuditoria(ctx context.Context, filtro *models.FiltroAuditoria) ([]models.RegistroAuditoria, error)
+	// ListarVersoesFilme resume as revisões do filme, da mais recente à mais antiga
+	ListarVersoesFilme(ctx context.Context, id int) ([]models.VersaoFilme, error)
+	BuscarVersaoFilme(ctx context.Context, id, numero int) (*models.VersaoFilme, error)
+	// ReverterFilme grava o conteúdo da revisão numero como uma nova revisão;
+	// versaoAtual segue a mesma regra de AtualizarFilme
+	ReverterFilme(ctx context.Context, id, numero, versaoAtual int) (*models.Filme, error)
+	Fechar() error
+}
+
+// PessoaRepositorio define as operações sobre pessoas (diretores)
+type PessoaRepositorio interface {
+	ListarPessoas(ctx context.Context, filtro *models.FiltroPessoas) ([]models.Pessoa, error)
+	BuscarPessoaPorID(ctx context.Context, id int) (*models.Pessoa, error)
+	CriarPessoa(ctx context.Context, pessoa *models.PessoaParaCriar) (*models.Pessoa, error)
+	// AtualizarPessoa renomeia a pessoa; os filmes que ela dirige ganham
+	// uma nova versão com o texto legado diretor recalculado
+	AtualizarPessoa(ctx context.Context, id int, pessoa *models.PessoaParaCriar) (*models.Pessoa, error)
+	// DeletarPessoa retorna ErrConflito se a pessoa ainda dirige algum filme
+	// ou tem créditos
+	DeletarPessoa(ctx context.Context, id int) error
+	FilmesDoDiretor(ctx context.Context, id int) ([]models.FilmeResumo, error)
+}
+
+// CreditoRepositorio define as operações sobre o elenco e a equipe dos filmes
+type CreditoRepositorio interface {
+	// ListarCreditos retorna ErrNaoEncontrado se o filme não existir ou
+	// estiver na lixeira; funcao vazia lista todas as funções
+	ListarCreditos(ctx context.Context, filmeID int, funcao string) ([]models.Credito, error)
+	// CriarCredito usa a pessoa de credito.PessoaID ou acha/cria pelo nome
+	CriarCredito(ctx context.Context, filmeID int, credito *models.CreditoParaCriar) (*models.Credito, error)
+	DeletarCredito(ctx context.Context, filmeID, id int) error
+	// Filmografia inclui os filmes dirigidos, com a função "diretor"
+	Filmografia(ctx context.Context, pessoaID int) ([]models.ItemFilmografia, error)
+}
+
+// GeneroRepositorio define as operações sobre a taxonomia de gêneros
+type GeneroRepositorio interface {
+	// ListarGeneros ordena por nome e conta apenas filmes fora da lixeira
+	ListarGeneros(ctx context.Context) ([]models.Genero, error)
+	// ListarGenerosCatalogo lê só nome, slug e sinônimos, sem contar filmes;
+	// basta para validar os gêneros de um filme
+	ListarGenerosCatalogo(ctx context.Context) ([]models.Genero, error)
+	// CriarGenero retorna ErrConflito se o slug já existir
+	CriarGenero(ctx context.Context, genero *models.GeneroParaCriar) (*models.Genero, error)
+}
+
+// UsuarioRepositorio define as operações sobre contas e sessões de usuário
+type UsuarioRepositorio interface {
+	// CriarUsuario retorna ErrConflito se o email já estiver cadastrado
+	CriarUsuario(ctx context.Context, usuario *models.UsuarioParaRegistrar, senhaHash string) (*models.Usuario, error)
+	// BuscarUsuarioPorEmail compara o email sem diferenciar maiúsculas
+	BuscarUsuarioPorEmail(ctx context.Context, email string) (*models.Usuario, error)
+	// BuscarTentativasLogin devolve o estado de bloqueio do email, vazio se
+	// não houver falhas registradas
+	BuscarTentativasLogin(ctx context.Context, email string) (*models.TentativasLogin, error)
+	// RegistrarFalhaLogin conta uma falha consecutiva do email, tenha ele
+	// conta ou não; falhas anteriores a falhasDesde são descartadas. Ao
+	// chegar a maximo, bloqueia até bloqueadoAte e zera o contador
+	RegistrarFalhaLogin(ctx context.Context, email string, maximo int, falhasDesde, bloqueadoAte time.Time) (*models.TentativasLogin, error)
+	// ExpurgarTentativasLogin apaga os emails sem bloqueio vigente cuja
+	// última falha é anterior a antesDe; retorna quantos foram removidos
+	ExpurgarTentativasLogin(ctx context.Context, antesDe time.Time) (int, error)
+	// RegistrarLoginSucesso guarda o horário do login e zera as falhas do
+	// email da conta
+	RegistrarLoginSucesso(ctx context.Context, id int) error
+	CriarSessao(ctx context.Context, sessao *models.Sessao) error
+	// RevogarSessao retorna ErrNaoEncontrado se a sessão não estiver ativa
+	RevogarSessao(ctx context.Context, tokenHash string) error
+	// RenovarSessao troca a sessão ativa de tokenHash por nova, preenchendo
+	// nova.UsuarioID; retorna ErrNaoEncontrado se a sessão não estiver ativa
+	RenovarSessao(ctx context.Context, tokenHash string, nova *models.Sessao) (*models.Usuario, error)
+}
+
+// Repositorio reúne todas as operações de persistência da aplicação
+type Repositorio interface {
+	FilmeRepositorio
+	PessoaRepositorio
+	CreditoRepositorio
+	GeneroRepositorio
+	UsuarioRepositorio
+}
+
+// Verificavel é implementado por repositórios com dependências externas que
+// precisam ser checadas pela sonda de prontidão
+type Verificavel interface {
+	Ping(ctx context.Context) error
+	Estatisticas() sql.DBStats
+}
+
+// Garantir em tempo de compilação que as implementações satisfazem a interface
+var (
+	_ Repositorio = (*BancoDados)(nil)
+	_ Repositorio = (*RepositorioMemoria)(nil)
+	_ Repositorio = (*RepositorioInstrumentado)(nil)
+	_ Verificavel = (*BancoDados)(nil)
+)
@@ -0,0 +1,223 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"api-filmes/internal/models"
+)
+
+// colunasUsuario lista as colunas lidas por escanearUsuario
+const colunasUsuario = `id, nome, email, senha_hash, ultimo_login, data_criacao, data_atualizacao`
+
+func escanearUsuario(linha linhaEscaneavel) (*models.Usuario, error) {
+	var usuario models.Usuario
+	err := linha.Scan(
+		&usuario.ID,
+		&usuario.Nome,
+		&usuario.Email,
+		&usuario.SenhaHash,
+		&usuario.UltimoLogin,
+		&usuario.DataCriacao,
+		&usuario.DataAtualizacao,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &usuario, nil
+}
+
+// CriarUsuario cadastra a conta; o email já deve vir normalizado
+func (bd *BancoDados) CriarUsuario(ctx context.Context, usuario *models.UsuarioParaRegistrar, senhaHash string) (*models.Usuario, error) {
+	query := "INSERT INTO usuarios (nome, email, senha_hash) VALUES ($1, $2, $3) RETURNING " + colunasUsuario
+
+	criado, err := escanearUsuario(bd.conexao.QueryRowContext(ctx, query, usuario.Nome, usuario.Email, senhaHash))
+	if err != nil {
+		err = traduzirErro(err, "erro ao criar usuário")
+		if errors.Is(err, ErrConflito) {
+			return nil, emailJaCadastrado()
+		}
+		return nil, err
+	}
+
+	return criado, nil
+}
+
+func (bd *BancoDados) BuscarUsuarioPorEmail(ctx context.Context, email string) (*models.Usuario, error) {
+	query := "SELECT " + colunasUsuario + " FROM usuarios WHERE LOWER(email) = LOWER($1)"
+
+	usuario, err := escanearUsuario(bd.conexao.QueryRowContext(ctx, query, email))
+	if err != nil {
+		err = traduzirErro(err, "erro ao buscar usuário")
+		if errors.Is(err, ErrNaoEncontrado) {
+			return nil, usuarioNaoEncontrado()
+		}
+		return nil, err
+	}
+
+	return usuario, nil
+}
+
+// BuscarTentativasLogin lê o estado de bloqueio do email; sem linha, não
+// há falhas registradas
+func (bd *BancoDados) BuscarTentativasLogin(ctx context.Context, email string) (*models.TentativasLogin, error) {
+	query := "SELECT falhas, bloqueado_ate, data_atualizacao FROM tentativas_login WHERE email = $1"
+
+	tentativas := &models.TentativasLogin{Email: email}
+	err := bd.conexao.QueryRowContext(ctx, query, email).Scan(&tentativas.Falhas, &tentativas.BloqueadoAte, &tentativas.AtualizadoEm)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, traduzirErro(err, "erro ao buscar tentativas de login")
+	}
+
+	return tentativas, nil
+}
+
+// falhasNaJanela são as falhas já contadas para o email, desde que a última
+// seja posterior a falhasDesde ($4)
+const falhasNaJanela = "(CASE WHEN t.data_atualizacao >= $4 THEN t.falhas ELSE 0 END)"
+
+// RegistrarFalhaLogin soma uma falha em uma única instrução, para que
+// tentativas simultâneas não se percam. Se a última falha é anterior a
+// falhasDesde, a contagem recomeça; ao atingir maximo, o email fica
+// bloqueado até bloqueadoAte e o contador volta a zero.
+func (bd *BancoDados) RegistrarFalhaLogin(ctx context.Context, email string, maximo int, falhasDesde, bloqueadoAte time.Time) (*models.TentativasLogin, error) {
+	query := `
+        INSERT INTO tentativas_login AS t (email, falhas, bloqueado_ate)
+        VALUES ($1, CASE WHEN 1 >= $2 THEN 0 ELSE 1 END, CASE WHEN 1 >= $2 THEN $3::timestamptz END)
+        ON CONFLICT (email) DO UPDATE
+        SET falhas = CASE WHEN ` + falhasNaJanela + ` + 1 >= $2 THEN 0 ELSE ` + falhasNaJanela + ` + 1 END,
+            bloqueado_ate = CASE WHEN ` + falhasNaJanela + ` + 1 >= $2 THEN $3::timestamptz ELSE t.bloqueado_ate END,
+            data_atualizacao = CURRENT_TIMESTAMP
+        RETURNING falhas, bloqueado_ate, data_atualizacao
+    `
+
+	tentativas := &models.TentativasLogin{Email: email}
+	err := bd.conexao.QueryRowContext(ctx, query, email, maximo, bloqueadoAte, falhasDesde).Scan(
+		&tentativas.Falhas, &tentativas.BloqueadoAte, &tentativas.AtualizadoEm)
+	if err != nil {
+		return nil, traduzirErro(err, "erro ao registrar falha de login")
+	}
+
+	return tentativas, nil
+}
+
+// RegistrarLoginSucesso guarda o horário do login e apaga as falhas do
+// email da conta, na mesma transação
+func (bd *BancoDados) RegistrarLoginSucesso(ctx context.Context, id int) error {
+	err := bd.emTransacao(ctx, func(tx *sql.Tx) error {
+		var email string
+		err := tx.QueryRowContext(ctx,
+			"UPDATE usuarios SET ultimo_login = $2 WHERE id = $1 RETURNING LOWER(email)", id, time.Now()).Scan(&email)
+		if err != nil {
+			return err
+		}
+		_, err = tx.ExecContext(ctx, "DELETE FROM tentativas_login WHERE email = $1", email)
+		return err
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return usuarioNaoEncontrado()
+		}
+		return traduzirErro(err, "erro ao registrar login")
+	}
+
+	return nil
+}
+
+// ExpurgarTentativasLogin apaga as falhas esquecidas e os bloqueios vencidos
+func (bd *BancoDados) ExpurgarTentativasLogin(ctx context.Context, antesDe time.Time) (int, error) {
+	query := `
+        DELETE FROM tentativas_login
+        WHERE data_atualizacao < $1
+          AND (bloqueado_ate IS NULL OR bloqueado_ate < CURRENT_TIMESTAMP)
+    `
+
+	resultado, err := bd.conexao.ExecContext(ctx, query, antesDe)
+	if err != nil {
+		return 0, traduzirErro(err, "erro ao expurgar tentativas de login")
+	}
+	removidos, err := resultado.RowsAffected()
+	if err != nil {
+		return 0, traduzirErro(err, "erro ao expurgar tentativas de login")
+	}
+
+	return int(removidos), nil
+}
+
+func (bd *BancoDados) CriarSessao(ctx context.Context, sessao *models.Sessao) error {
+	query := `
+        INSERT INTO sessoes (token_hash, usuario_id, data_criacao, expira_em)
+        VALUES ($1, $2, $3, $4)
+    `
+
+	_, err := bd.conexao.ExecContext(ctx, query, sessao.TokenHash, sessao.UsuarioID, sessao.DataCriacao, sessao.ExpiraEm)
+	if err != nil {
+		return traduzirErro(err, "erro ao criar sessão")
+	}
+
+	return nil
+}
+
+// RevogarSessao encerra uma sessão ativa; tokens desconhecidos, expirados
+// ou já revogados retornam ErrNaoEncontrado
+func (bd *BancoDados) RevogarSessao(ctx context.Context, tokenHash string) error {
+	query := `
+        UPDATE sessoes
+        SET revogada_em = $2
+        WHERE token_hash = $1 AND revogada_em IS NULL AND expira_em > $2
+    `
+
+	resultado, err := bd.conexao.ExecContext(ctx, query, tokenHash, time.Now())
+	if err != nil {
+		return traduzirErro(err, "erro ao revogar sessão")
+	}
+	linhas, err := resultado.RowsAffected()
+	if err != nil {
+		return traduzirErro(err, "erro ao revogar sessão")
+	}
+	if linhas == 0 {
+		return sessaoNaoEncontrada()
+	}
+
+	return nil
+}
+
+// RenovarSessao revoga a sessão ativa de tokenHash e cria nova no lugar, para
+// o mesmo usuário, na mesma transação: cada token de renovação vale uma vez
+func (bd *BancoDados) RenovarSessao(ctx context.Context, tokenHash string, nova *models.Sessao) (*models.Usuario, error) {
+	query := `
+        UPDATE sessoes
+        SET revogada_em = $2
+        WHERE token_hash = $1 AND revogada_em IS NULL AND expira_em > $2
+        RETURNING usuario_id
+    `
+
+	var usuario *models.Usuario
+	err := bd.emTransacao(ctx, func(tx *sql.Tx) error {
+		if err := tx.QueryRowContext(ctx, query, tokenHash, nova.DataCriacao).Scan(&nova.UsuarioID); err != nil {
+			return err
+		}
+
+		_, err := tx.ExecContext(ctx, `
+            INSERT INTO sessoes (token_hash, usuario_id, data_criacao, expira_em)
+            VALUES ($1, $2, $3, $4)
+        `, nova.TokenHash, nova.UsuarioID, nova.DataCriacao, nova.ExpiraEm)
+		if err != nil {
+			return err
+		}
+
+		usuario, err = escanearUsuario(tx.QueryRowContext(ctx,
+			"SELECT "+colunasUsuario+" FROM usuarios WHERE id = $1", nova.UsuarioID))
+		return err
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, sessaoNaoEncontrada()
+		}
+		return nil, traduzirErro(err, "erro ao renovar sessão")
+	}
+
+	return usuario, nil
+}
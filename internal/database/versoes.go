@@ -0,0 +1,120 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"api-filmes/internal/models"
+	"api-filmes/internal/requisicao"
+)
+
+// versaoNaoEncontrada é o erro de uma revisão inexistente
+func versaoNaoEncontrada(id, versao int) error {
+	return &ErroBanco{
+		Tipo:     ErrNaoEncontrado,
+		Mensagem: fmt.Sprintf("versão %d do filme com ID %d não encontrada", versao, id),
+	}
+}
+
+// registrarVersao grava o snapshot completo do filme na revisão filme.Versao
+func registrarVersao(ctx context.Context, tx *sql.Tx, operacao string, filme *models.Filme) error {
+	var idRequisicao *string
+	if id := requisicao.ID(ctx); id != "" {
+		idRequisicao = &id
+	}
+
+	query := `
+        INSERT INTO versoes_filmes (filme_id, versao, operacao, ator, id_requisicao, dados)
+        VALUES ($1, $2, $3, $4, $5, $6::jsonb)
+    `
+	_, err := tx.ExecContext(ctx, query,
+		filme.ID,
+		filme.Versao,
+		operacao,
+		requisicao.Ator(ctx),
+		idRequisicao,
+		jsonOuNulo(filme),
+	)
+	return err
+}
+
+// filmeVisivel restringe as revisões a filmes fora da lixeira: as revisões
+// de filmes excluídos ou expurgados não são consultáveis
+const filmeVisivel = `EXISTS (
+            SELECT 1 FROM filmes f WHERE f.id = versoes_filmes.filme_id AND f.deletado_em IS NULL
+        )`
+
+// ListarVersoesFilme retorna as revisões do filme, da mais recente à mais antiga
+func (bd *BancoDados) ListarVersoesFilme(ctx context.Context, id int) ([]models.VersaoFilme, error) {
+	query := `
+        SELECT versao, operacao, ator, COALESCE(id_requisicao, ''), criada_em
+        FROM versoes_filmes
+        WHERE filme_id = $1 AND ` + filmeVisivel + `
+        ORDER BY versao DESC
+    `
+
+	linhas, err := bd.conexao.QueryContext(ctx, query, id)
+	if err != nil {
+		return nil, traduzirErro(err, "erro ao listar versões")
+	}
+	defer linhas.Close()
+
+	versoes := []models.VersaoFilme{}
+	for linhas.Next() {
+		var versao models.VersaoFilme
+		err := linhas.Scan(&versao.Versao, &versao.Operacao, &versao.Ator, &versao.IDRequisicao, &versao.CriadaEm)
+		if err != nil {
+			return nil, traduzirErro(err, "erro ao ler versão")
+		}
+		versoes = append(versoes, versao)
+	}
+	if err := linhas.Err(); err != nil {
+		return nil, traduzirErro(err, "erro durante leitura dos resultados")
+	}
+
+	if len(versoes) == 0 {
+		return nil, filmeNaoEncontrado(id)
+	}
+	return versoes, nil
+}
+
+// BuscarVersaoFilme retorna a revisão com o snapshot do filme; filmes na
+// lixeira ou expurgados não têm revisões visíveis
+func (bd *BancoDados) BuscarVersaoFilme(ctx context.Context, id, numero int) (*models.VersaoFilme, error) {
+	query := `
+        SELECT versao, operacao, ator, COALESCE(id_requisicao, ''), criada_em, dados
+        FROM versoes_filmes
+        WHERE filme_id = $1 AND versao = $2 AND ` + filmeVisivel + `
+    `
+
+	var versao models.VersaoFilme
+	var dados []byte
+	err := bd.conexao.QueryRowContext(ctx, query, id, numero).Scan(
+		&versao.Versao, &versao.Operacao, &versao.Ator, &versao.IDRequisicao, &versao.CriadaEm, &dados)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, versaoNaoEncontrada(id, numero)
+		}
+		return nil, traduzirErro(err, "erro ao buscar versão")
+	}
+
+	versao.Filme = &models.Filme{}
+	if err := json.Unmarshal(dados, versao.Filme); err != nil {
+		return nil, traduzirErro(err, "erro ao ler versão")
+	}
+	return &versao, nil
+}
+
+// ReverterFilme grava os campos editáveis da revisão informada como uma
+// nova revisão; versaoAtual, se diferente de QualquerVersao, deve ser a
+// revisão vigente
+func (bd *BancoDados) ReverterFilme(ctx context.Context, id, numero, versaoAtual int) (*models.Filme, error) {
+	alvo, err := bd.BuscarVersaoFilme(ctx, id, numero)
+	if err != nil {
+		return nil, err
+	}
+	return bd.substituirFilme(ctx, models.OperacaoReverter, id, alvo.Filme.ParaCriar(), versaoAtual)
+}
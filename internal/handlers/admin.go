@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	"api-filmes/internal/requisicao"
+	"api-filmes/internal/roteador"
+)
+
+// CabecalhoTokenAdmin transporta o token das operações administrativas
+const CabecalhoTokenAdmin = "X-Admin-Token"
+
+// AtorAdmin identifica na auditoria as operações feitas com o token
+const AtorAdmin = "admin"
+
+// AdminMiddleware só deixa passar requisições com o token de administrador.
+// Sem token configurado as rotas administrativas ficam desativadas.
+func AdminMiddleware(token string) roteador.Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			configurarCabecalhos(w)
+
+			if token == "" {
+				enviarErro(w, r, "Operações administrativas desativadas", http.StatusForbidden,
+					[]string{"Defina ADMIN_TOKEN para habilitá-las"})
+				return
+			}
+
+			recebido := r.Header.Get(CabecalhoTokenAdmin)
+			if subtle.ConstantTimeCompare([]byte(recebido), []byte(token)) != 1 {
+				enviarErro(w, r, "Acesso restrito a administradores", http.StatusForbidden,
+					[]string{"Informe o cabeçalho " + CabecalhoTokenAdmin + " válido"})
+				return
+			}
+
+			requisicao.DefinirAtor(r.Context(), AtorAdmin)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
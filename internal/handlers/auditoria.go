@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"api-filmes/internal/models"
+)
+
+// HistoricoFilme lista as alterações de um filme, mesmo que ele já tenha
+// sido expurgado
+func (fh *FilmeHandler) HistoricoFilme(w http.ResponseWriter, r *http.Request) {
+	id, ok := lerID(w, r)
+	if !ok {
+		configurarCabecalhos(w)
+		return
+	}
+	fh.responderAuditoria(w, r, &id)
+}
+
+// ListarAuditoria é o feed global em /auditoria, filtrável por ator,
+// operacao e intervalo de tempo (desde/ate em RFC 3339)
+func (fh *FilmeHandler) ListarAuditoria(w http.ResponseWriter, r *http.Request) {
+	fh.responderAuditoria(w, r, nil)
+}
+
+func (fh *FilmeHandler) responderAuditoria(w http.ResponseWriter, r *http.Request, filmeID *int) {
+	configurarCabecalhos(w)
+
+	filtro, pagina, erros := lerFiltroAuditoria(r)
+	if len(erros) > 0 {
+		enviarErro(w, r, "Parâmetros inválidos", http.StatusBadRequest, erros)
+		return
+	}
+	filtro.FilmeID = filmeID
+
+	ctx, cancelar := fh.contexto(r, "auditoria")
+	defer cancelar()
+
+	// Buscar um item a mais para saber se existe próxima página
+	limite := filtro.Limite
+	filtro.Limite++
+
+	registros, err := fh.repositorio.BuscarAuditoria(ctx, filtro)
+	if err != nil {
+		responderErro(w, r, ctx, err, "Erro ao buscar auditoria")
+		return
+	}
+
+	haMais := len(registros) > limite
+	if haMais {
+		registros = registros[:limite]
+	}
+
+	slog.InfoContext(ctx, "auditoria consultada", "quantidade", len(registros))
+	enviarJSON(w, models.RespostaAuditoria{
+		Registros: registros,
+		Pagina:    pagina,
+		Limite:    limite,
+		HaMais:    haMais,
+	}, http.StatusOK)
+}
+
+// lerFiltroAuditoria interpreta os parâmetros de query da auditoria
+func lerFiltroAuditoria(r *http.Request) (*models.FiltroAuditoria, int, []string) {
+	parametros := r.URL.Query()
+	var erros []string
+
+	pagina, limite := lerPaginaLimite(parametros, &erros)
+	filtro := &models.FiltroAuditoria{
+		Ator:         strings.TrimSpace(parametros.Get("ator")),
+		Operacao:     strings.TrimSpace(parametros.Get("operacao")),
+		Limite:       limite,
+		Deslocamento: (pagina - 1) * limite,
+	}
+
+	if filtro.Operacao != "" && !slices.Contains(models.OperacoesAuditoria, filtro.Operacao) {
+		erros = append(erros, fmt.Sprintf("operacao deve ser uma de: %s", strings.Join(models.OperacoesAuditoria, ", ")))
+	}
+
+	filtro.Desde = lerMomentoOpcional(parametros.Get("desde"), "desde", &erros)
+	filtro.Ate = lerMomentoOpcional(parametros.Get("ate"), "ate", &erros)
+	if filtro.Desde != nil && filtro.Ate != nil && filtro.Desde.After(*filtro.Ate) {
+		erros = append(erros, "desde não pode ser posterior a ate")
+	}
+
+	return filtro, pagina, erros
+}
+
+// lerMomentoOpcional aceita RFC 3339 (2026-01-02T15:04:05Z) ou só a data
+func lerMomentoOpcional(valor, nome string, erros *[]string) *time.Time {
+	if valor == "" {
+		return nil
+	}
+	for _, layout := range []string{time.RFC3339, time.DateOnly} {
+		if momento, err := time.Parse(layout, valor); err == nil {
+			return &momento
+		}
+	}
+	*erros = append(*erros, fmt.Sprintf("%s deve estar no formato RFC 3339 ou AAAA-MM-DD", nome))
+	return nil
+}
@@ -0,0 +1,328 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"api-filmes/internal/autenticacao"
+	"api-filmes/internal/config"
+	"api-filmes/internal/database"
+	"api-filmes/internal/models"
+	"api-filmes/internal/roteador"
+)
+
+// TipoTokenBearer é o esquema do cabeçalho Authorization (RFC 6750)
+const TipoTokenBearer = "Bearer"
+
+// tamanhoMaximoAutenticacao limita os corpos de /auth/*, que só trazem
+// nome, email, senha ou token
+const tamanhoMaximoAutenticacao = 8 << 10
+
+// AutenticacaoHandler contém as dependências dos handlers de conta e sessão
+type AutenticacaoHandler struct {
+	repositorio database.Repositorio
+	timeouts    *config.ConfiguracaoTimeouts
+	config      *config.ConfiguracaoAutenticacao
+	tokens      *autenticacao.GerenciadorTokens
+}
+
+// NovoAutenticacaoHandler cria uma nova instância do handler
+func NovoAutenticacaoHandler(repositorio database.Repositorio, timeouts *config.ConfiguracaoTimeouts,
+	cfg *config.ConfiguracaoAutenticacao, tokens *autenticacao.GerenciadorTokens) *AutenticacaoHandler {
+	return &AutenticacaoHandler{repositorio: repositorio, timeouts: timeouts, config: cfg, tokens: tokens}
+}
+
+// contexto aplica o mesmo timeout por rota usado pelos demais handlers
+func (ah *AutenticacaoHandler) contexto(r *http.Request, rota string) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(r.Context(), ah.timeouts.Timeout(rota))
+}
+
+// RegistrarRotas associa os handlers de autenticação aos padrões do roteador
+func (ah *AutenticacaoHandler) RegistrarRotas(rt roteador.Rotas) {
+	rt.Post("/auth/registrar", ah.Registrar)
+	rt.Post("/auth/login", ah.Login)
+	rt.Post("/auth/renovar", ah.Renovar)
+	rt.Post("/auth/logout", ah.Logout)
+	rt.Manipular(http.MethodGet, "/auth/eu", ExigirUsuarioMiddleware(http.HandlerFunc(ah.Eu)))
+}
+
+// Registrar cria uma conta com a senha guardada em hash argon2id
+func (ah *AutenticacaoHandler) Registrar(w http.ResponseWriter, r *http.Request) {
+	configurarCabecalhos(w)
+
+	var usuario models.UsuarioParaRegistrar
+	if !lerCorpoAutenticacao(w, r, &usuario) {
+		return
+	}
+
+	if erros := models.ValidarRegistro(&usuario); len(erros) > 0 {
+		enviarErroValidacao(w, r, erros)
+		return
+	}
+
+	ctx, cancelar := ah.contexto(r, "registrar")
+	defer cancelar()
+
+	senhaHash, err := autenticacao.GerarHash(ctx, usuario.Senha)
+	if err != nil {
+		responderErro(w, r, ctx, err, "Erro ao gerar hash da senha")
+		return
+	}
+
+	criado, err := ah.repositorio.CriarUsuario(ctx, &usuario, senhaHash)
+	if err != nil {
+		responderErro(w, r, ctx, err, "Erro ao registrar usuário")
+		return
+	}
+
+	slog.InfoContext(ctx, "usuário registrado", "usuario_id", criado.ID)
+	enviarJSON(w, models.RespostaSucesso{Mensagem: "Usuário registrado com sucesso", Dados: criado}, http.StatusCreated)
+}
+
+// Login troca email e senha pelos tokens de acesso e de renovação. Email
+// inexistente e senha errada têm a mesma resposta; depois de
+// MaximoTentativas falhas seguidas o email fica bloqueado por
+// DuracaoBloqueio, tenha ele conta ou não.
+func (ah *AutenticacaoHandler) Login(w http.ResponseWriter, r *http.Request) {
+	configurarCabecalhos(w)
+
+	var credenciais models.Credenciais
+	if !lerCorpoAutenticacao(w, r, &credenciais) {
+		return
+	}
+
+	credenciais.Email = models.NormalizarEmail(credenciais.Email)
+	if credenciais.Email == "" || credenciais.Senha == "" || len(credenciais.Senha) > 4*models.TamanhoMaximoSenha {
+		enviarErro(w, r, "Credenciais incompletas", http.StatusBadRequest, []string{"Informe email e senha"})
+		return
+	}
+
+	ctx, cancelar := ah.contexto(r, "login")
+	defer cancelar()
+
+	tentativas, err := ah.repositorio.BuscarTentativasLogin(ctx, credenciais.Email)
+	if err != nil {
+		responderErro(w, r, ctx, err, "Erro ao buscar tentativas de login")
+		return
+	}
+
+	usuario, err := ah.repositorio.BuscarUsuarioPorEmail(ctx, credenciais.Email)
+	if err != nil && !errors.Is(err, database.ErrNaoEncontrado) {
+		responderErro(w, r, ctx, err, "Erro ao buscar usuário")
+		return
+	}
+
+	// A senha é sempre verificada, com ou sem conta e com ou sem bloqueio,
+	// para que o tempo de resposta não revele nada; durante o bloqueio o
+	// resultado é descartado, para não servir de oráculo
+	valida := false
+	if usuario == nil {
+		autenticacao.SimularVerificacao(ctx, credenciais.Senha)
+	} else if valida, err = autenticacao.VerificarSenha(ctx, credenciais.Senha, usuario.SenhaHash); err != nil {
+		responderErro(w, r, ctx, err, "Erro ao verificar senha")
+		return
+	}
+
+	agora := time.Now()
+	if tentativas.Bloqueado(agora) {
+		enviarContaBloqueada(w, r, *tentativas.BloqueadoAte)
+		return
+	}
+
+	if !valida {
+		// Falhas mais antigas que a duração do bloqueio não contam mais
+		tentativas, err = ah.repositorio.RegistrarFalhaLogin(ctx, credenciais.Email, ah.config.MaximoTentativas,
+			agora.Add(-ah.config.DuracaoBloqueio), agora.Add(ah.config.DuracaoBloqueio))
+		if err != nil {
+			responderErro(w, r, ctx, err, "Erro ao registrar falha de login")
+			return
+		}
+		if tentativas.Bloqueado(agora) {
+			slog.WarnContext(ctx, "email bloqueado por falhas de login", "bloqueado_ate", tentativas.BloqueadoAte)
+			enviarContaBloqueada(w, r, *tentativas.BloqueadoAte)
+			return
+		}
+		slog.InfoContext(ctx, "falha de login", "tentativas", tentativas.Falhas)
+		enviarCredenciaisInvalidas(w, r)
+		return
+	}
+
+	if err := ah.repositorio.RegistrarLoginSucesso(ctx, usuario.ID); err != nil {
+		responderErro(w, r, ctx, err, "Erro ao registrar login")
+		return
+	}
+
+	token, sessao, err := ah.novaSessao(agora)
+	if err != nil {
+		responderErro(w, r, ctx, err, "Erro ao gerar token")
+		return
+	}
+	sessao.UsuarioID = usuario.ID
+	if err := ah.repositorio.CriarSessao(ctx, sessao); err != nil {
+		responderErro(w, r, ctx, err, "Erro ao criar sessão")
+		return
+	}
+
+	slog.InfoContext(ctx, "login realizado", "usuario_id", usuario.ID)
+	usuario.UltimoLogin = &agora
+	ah.enviarTokens(w, r, ctx, usuario, token, sessao)
+}
+
+// Renovar troca um token de renovação ainda ativo por um novo par de
+// tokens. O token usado é revogado: cada um vale uma única vez.
+func (ah *AutenticacaoHandler) Renovar(w http.ResponseWriter, r *http.Request) {
+	configurarCabecalhos(w)
+
+	pedido, ok := lerPedidoRenovacao(w, r)
+	if !ok {
+		return
+	}
+
+	ctx, cancelar := ah.contexto(r, "renovar")
+	defer cancelar()
+
+	token, sessao, err := ah.novaSessao(time.Now())
+	if err != nil {
+		responderErro(w, r, ctx, err, "Erro ao gerar token")
+		return
+	}
+
+	usuario, err := ah.repositorio.RenovarSessao(ctx, autenticacao.HashToken(pedido.TokenRenovacao), sessao)
+	if errors.Is(err, database.ErrNaoEncontrado) {
+		enviarNaoAutenticado(w, r, "Token de renovação inexistente, expirado ou já utilizado")
+		return
+	}
+	if err != nil {
+		responderErro(w, r, ctx, err, "Erro ao renovar sessão")
+		return
+	}
+
+	slog.InfoContext(ctx, "sessão renovada", "usuario_id", usuario.ID)
+	ah.enviarTokens(w, r, ctx, usuario, token, sessao)
+}
+
+// Logout revoga a sessão do token de renovação enviado no corpo. Tokens de
+// acesso já emitidos continuam válidos até expirar, por isso duram pouco.
+func (ah *AutenticacaoHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	configurarCabecalhos(w)
+
+	pedido, ok := lerPedidoRenovacao(w, r)
+	if !ok {
+		return
+	}
+
+	ctx, cancelar := ah.contexto(r, "logout")
+	defer cancelar()
+
+	err := ah.repositorio.RevogarSessao(ctx, autenticacao.HashToken(pedido.TokenRenovacao))
+	if errors.Is(err, database.ErrNaoEncontrado) {
+		enviarNaoAutenticado(w, r, "Sessão inexistente, expirada ou já encerrada")
+		return
+	}
+	if err != nil {
+		responderErro(w, r, ctx, err, "Erro ao encerrar sessão")
+		return
+	}
+
+	enviarJSON(w, models.RespostaSucesso{Mensagem: "Sessão encerrada"}, http.StatusOK)
+}
+
+// Eu retorna o usuário autenticado pelo token de acesso
+func (ah *AutenticacaoHandler) Eu(w http.ResponseWriter, r *http.Request) {
+	configurarCabecalhos(w)
+	enviarJSON(w, models.RespostaSucesso{Mensagem: "Usuário autenticado", Dados: autenticacao.PrincipalDe(r.Context())}, http.StatusOK)
+}
+
+// novaSessao gera o token de renovação e a sessão correspondente, ainda
+// sem usuário
+func (ah *AutenticacaoHandler) novaSessao(agora time.Time) (string, *models.Sessao, error) {
+	token, tokenHash, err := autenticacao.NovoToken()
+	if err != nil {
+		return "", nil, err
+	}
+	return token, &models.Sessao{
+		TokenHash:   tokenHash,
+		DataCriacao: agora,
+		ExpiraEm:    agora.Add(ah.config.DuracaoSessao),
+	}, nil
+}
+
+// enviarTokens emite o token de acesso e responde com o par de tokens
+func (ah *AutenticacaoHandler) enviarTokens(w http.ResponseWriter, r *http.Request, ctx context.Context,
+	usuario *models.Usuario, tokenRenovacao string, sessao *models.Sessao) {
+	tokenAcesso, expiraEm, err := ah.tokens.Emitir(&autenticacao.Principal{
+		UsuarioID: usuario.ID,
+		Email:     usuario.Email,
+		Nome:      usuario.Nome,
+	})
+	if err != nil {
+		responderErro(w, r, ctx, err, "Erro ao emitir token de acesso")
+		return
+	}
+
+	enviarJSON(w, models.RespostaLogin{
+		TokenAcesso:       tokenAcesso,
+		TipoToken:         TipoTokenBearer,
+		ExpiraEm:          expiraEm,
+		TokenRenovacao:    tokenRenovacao,
+		RenovacaoExpiraEm: sessao.ExpiraEm,
+		Usuario:           usuario,
+	}, http.StatusOK)
+}
+
+// lerPedidoRenovacao lê o token de renovação do corpo de /auth/renovar e
+// /auth/logout
+func lerPedidoRenovacao(w http.ResponseWriter, r *http.Request) (*models.PedidoRenovacao, bool) {
+	var pedido models.PedidoRenovacao
+	if !lerCorpoAutenticacao(w, r, &pedido) {
+		return nil, false
+	}
+	pedido.TokenRenovacao = strings.TrimSpace(pedido.TokenRenovacao)
+	if pedido.TokenRenovacao == "" {
+		enviarErro(w, r, "Token de renovação ausente", http.StatusBadRequest, []string{"Informe token_renovacao no corpo"})
+		return nil, false
+	}
+	return &pedido, true
+}
+
+// lerCorpoAutenticacao decodifica o corpo JSON de /auth/*, limitado a
+// tamanhoMaximoAutenticacao: corpos maiores recebem 413 antes de chegar ao
+// argon2
+func lerCorpoAutenticacao(w http.ResponseWriter, r *http.Request, destino any) bool {
+	return lerCorpoJSON(w, r, destino, tamanhoMaximoAutenticacao)
+}
+
+// lerTokenBearer extrai o token do cabeçalho Authorization
+func lerTokenBearer(r *http.Request) (string, bool) {
+	esquema, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(esquema, TipoTokenBearer) {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
+
+// enviarNaoAutenticado responde 401 com o desafio WWW-Authenticate
+func enviarNaoAutenticado(w http.ResponseWriter, r *http.Request, detalhe string) {
+	w.Header().Set("WWW-Authenticate", TipoTokenBearer)
+	enviarErro(w, r, "Não autenticado", http.StatusUnauthorized, []string{detalhe})
+}
+
+// enviarCredenciaisInvalidas não diz qual dos dois campos está errado
+func enviarCredenciaisInvalidas(w http.ResponseWriter, r *http.Request) {
+	enviarErro(w, r, "Credenciais inválidas", http.StatusUnauthorized, []string{"Email ou senha incorretos"})
+}
+
+// enviarContaBloqueada responde 423 com o tempo restante em Retry-After
+func enviarContaBloqueada(w http.ResponseWriter, r *http.Request, ate time.Time) {
+	segundos := int(math.Ceil(time.Until(ate).Seconds()))
+	w.Header().Set("Retry-After", strconv.Itoa(max(segundos, 1)))
+	escreverErro(w, r, "Conta temporariamente bloqueada", http.StatusLocked, CodigoContaBloqueada,
+		[]string{"Muitas tentativas de login sem sucesso; tente novamente após " + ate.UTC().Format(time.RFC3339)}, nil)
+}
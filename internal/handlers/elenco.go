@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"api-filmes/internal/models"
+)
+
+// ListarElenco retorna os créditos do filme em /filmes/{id}/elenco?funcao=
+func (fh *FilmeHandler) ListarElenco(w http.ResponseWriter, r *http.Request) {
+	configurarCabecalhos(w)
+
+	id, ok := lerID(w, r)
+	if !ok {
+		return
+	}
+
+	funcao := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("funcao")))
+	if funcao != "" && !models.FuncaoCreditoValida(funcao) {
+		enviarErro(w, r, "Parâmetros inválidos", http.StatusBadRequest,
+			[]string{"funcao deve ser uma de: " + strings.Join(models.FuncoesCredito, ", ")})
+		return
+	}
+
+	ctx, cancelar := fh.contexto(r, "buscar")
+	defer cancelar()
+
+	creditos, err := fh.repositorio.ListarCreditos(ctx, id, funcao)
+	if err != nil {
+		responderErro(w, r, ctx, err, "Erro ao listar elenco")
+		return
+	}
+
+	enviarJSON(w, models.RespostaElenco{FilmeID: id, Creditos: creditos}, http.StatusOK)
+}
+
+// CriarCredito adiciona uma pessoa ao elenco ou à equipe do filme
+func (fh *FilmeHandler) CriarCredito(w http.ResponseWriter, r *http.Request) {
+	configurarCabecalhos(w)
+
+	id, ok := lerID(w, r)
+	if !ok {
+		return
+	}
+
+	var credito models.CreditoParaCriar
+	if !lerCorpoJSON(w, r, &credito, tamanhoMaximoCorpo) {
+		return
+	}
+
+	if erros := models.ValidarCredito(&credito); len(erros) > 0 {
+		enviarErroValidacao(w, r, erros)
+		return
+	}
+
+	ctx, cancelar := fh.contexto(r, "criar")
+	defer cancelar()
+
+	criado, err := fh.repositorio.CriarCredito(ctx, id, &credito)
+	if err != nil {
+		responderErro(w, r, ctx, err, "Erro ao criar crédito")
+		return
+	}
+
+	slog.InfoContext(ctx, "crédito criado", "filme_id", id, "credito_id", criado.ID,
+		"pessoa_id", criado.Pessoa.ID, "funcao", criado.Funcao)
+	enviarJSON(w, models.RespostaSucesso{Mensagem: "Crédito criado com sucesso", Dados: criado}, http.StatusCreated)
+}
+
+// DeletarCredito remove um crédito do filme
+func (fh *FilmeHandler) DeletarCredito(w http.ResponseWriter, r *http.Request) {
+	configurarCabecalhos(w)
+
+	id, ok := lerID(w, r)
+	if !ok {
+		return
+	}
+
+	creditoID, err := strconv.Atoi(r.PathValue("credito"))
+	if err != nil || creditoID < 1 {
+		enviarErro(w, r, "ID inválido", http.StatusBadRequest, []string{"ID do crédito deve ser um número inteiro positivo"})
+		return
+	}
+
+	ctx, cancelar := fh.contexto(r, "deletar")
+	defer cancelar()
+
+	if err := fh.repositorio.DeletarCredito(ctx, id, creditoID); err != nil {
+		responderErro(w, r, ctx, err, "Erro ao deletar crédito")
+		return
+	}
+
+	slog.InfoContext(ctx, "crédito deletado", "filme_id", id, "credito_id", creditoID)
+	enviarJSON(w, models.RespostaSucesso{Mensagem: "Crédito deletado com sucesso"}, http.StatusOK)
+}
@@ -0,0 +1,229 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"api-filmes/internal/database"
+	"api-filmes/internal/models"
+)
+
+// StatusClienteEncerrouRequisicao segue a convenção do nginx (499) para
+// requisições abandonadas pelo cliente antes da resposta
+const StatusClienteEncerrouRequisicao = 499
+
+// Códigos de erro estáveis, pensados para consumo por máquinas.
+// Nunca altere um valor existente: clientes dependem deles.
+const (
+	CodigoRequisicaoInvalida  = "requisicao_invalida"
+	CodigoDadosInvalidos      = "dados_invalidos"
+	CodigoNaoEncontrado       = "nao_encontrado"
+	CodigoAcessoNegado        = "acesso_negado"
+	CodigoMetodoNaoPermitido  = "metodo_nao_permitido"
+	CodigoConflito            = "conflito"
+	CodigoViolacaoRestricao   = "violacao_restricao"
+	CodigoServicoIndisponivel = "servico_indisponivel"
+	CodigoTempoEsgotado       = "tempo_esgotado"
+	CodigoRequisicaoCancelada = "requisicao_cancelada"
+	CodigoErroInterno         = "erro_interno"
+	CodigoTipoNaoSuportado    = "tipo_midia_nao_suportado"
+	CodigoTesteFalhou         = "teste_patch_falhou"
+	CodigoPatchInaplicavel    = "patch_inaplicavel"
+	CodigoPrecondicaoFalhou   = "precondicao_falhou"
+	CodigoNaoAutenticado      = "nao_autenticado"
+	CodigoContaBloqueada      = "conta_bloqueada"
+	CodigoCorpoMuitoGrande    = "corpo_muito_grande"
+)
+
+// codigoPorStatus define o código padrão quando só o status HTTP é conhecido
+var codigoPorStatus = map[int]string{
+	http.StatusBadRequest:            CodigoRequisicaoInvalida,
+	http.StatusUnauthorized:          CodigoNaoAutenticado,
+	http.StatusForbidden:             CodigoAcessoNegado,
+	http.StatusNotFound:              CodigoNaoEncontrado,
+	http.StatusMethodNotAllowed:      CodigoMetodoNaoPermitido,
+	http.StatusConflict:              CodigoConflito,
+	http.StatusPreconditionFailed:    CodigoPrecondicaoFalhou,
+	http.StatusRequestEntityTooLarge: CodigoCorpoMuitoGrande,
+	http.StatusUnsupportedMediaType:  CodigoTipoNaoSuportado,
+	http.StatusUnprocessableEntity:   CodigoDadosInvalidos,
+	http.StatusServiceUnavailable:    CodigoServicoIndisponivel,
+	StatusClienteEncerrouRequisicao:  CodigoRequisicaoCancelada,
+	http.StatusInternalServerError:   CodigoErroInterno,
+}
+
+// erroTraduzido é o resultado da tradução de um erro do repositório para HTTP
+type erroTraduzido struct {
+	status   int
+	codigo   string
+	mensagem string
+}
+
+// traduzirErro é o ponto único que decide status HTTP e código de um erro
+func traduzirErro(ctx context.Context, err error) erroTraduzido {
+	// O driver pode devolver o cancelamento como erro do PostgreSQL
+	// ("canceling statement due to user request"), então o contexto
+	// da requisição é consultado antes do próprio erro
+	if ctx.Err() != nil {
+		err = fmt.Errorf("%w: %v", ctx.Err(), err)
+	}
+
+	var erroBanco *database.ErroBanco
+	mensagemBanco := ""
+	if errors.As(err, &erroBanco) {
+		mensagemBanco = erroBanco.Mensagem
+	}
+
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return erroTraduzido{http.StatusServiceUnavailable, CodigoTempoEsgotado, "Tempo limite da operação excedido"}
+	case errors.Is(err, context.Canceled):
+		return erroTraduzido{StatusClienteEncerrouRequisicao, CodigoRequisicaoCancelada, "Requisição cancelada pelo cliente"}
+	case errors.Is(err, database.ErrNaoEncontrado):
+		return erroTraduzido{http.StatusNotFound, CodigoNaoEncontrado, capitalizar(mensagemBanco)}
+	case errors.Is(err, database.ErrPrecondicao):
+		return erroTraduzido{http.StatusPreconditionFailed, CodigoPrecondicaoFalhou, capitalizar(mensagemBanco)}
+	case errors.Is(err, database.ErrConflito):
+		return erroTraduzido{http.StatusConflict, CodigoConflito, capitalizar(mensagemBanco)}
+	case errors.Is(err, database.ErrRestricao):
+		return erroTraduzido{http.StatusUnprocessableEntity, CodigoViolacaoRestricao, capitalizar(mensagemBanco)}
+	case errors.Is(err, database.ErrValidacao):
+		return erroTraduzido{http.StatusUnprocessableEntity, CodigoDadosInvalidos, capitalizar(mensagemBanco)}
+	case errors.Is(err, database.ErrIndisponivel):
+		return erroTraduzido{http.StatusServiceUnavailable, CodigoServicoIndisponivel, "Banco de dados indisponível"}
+	default:
+		return erroTraduzido{http.StatusInternalServerError, CodigoErroInterno, "Erro interno do servidor"}
+	}
+}
+
+// responderErro traduz um erro do repositório e envia a resposta.
+// Falhas do servidor (5xx) são registradas com o prefixo informado.
+func responderErro(w http.ResponseWriter, r *http.Request, ctx context.Context, err error, operacao string) {
+	traduzido := traduzirErro(ctx, err)
+
+	if traduzido.status >= http.StatusInternalServerError || traduzido.status == StatusClienteEncerrouRequisicao {
+		slog.ErrorContext(r.Context(), operacao, "erro", err, "status", traduzido.status, "codigo_erro", traduzido.codigo)
+	}
+
+	escreverErro(w, r, traduzido.mensagem, traduzido.status, traduzido.codigo, nil, nil)
+}
+
+// enviarErro envia um erro usando o código padrão do status HTTP
+func enviarErro(w http.ResponseWriter, r *http.Request, mensagem string, status int, detalhes []string) {
+	codigo, ok := codigoPorStatus[status]
+	if !ok {
+		codigo = CodigoErroInterno
+	}
+
+	escreverErro(w, r, mensagem, status, codigo, detalhes, nil)
+}
+
+// enviarErroLeituraCorpo responde 413 quando o corpo passou do limite de
+// http.MaxBytesReader e 400 para as demais falhas de leitura
+func enviarErroLeituraCorpo(w http.ResponseWriter, r *http.Request, err error) {
+	var muitoGrande *http.MaxBytesError
+	if errors.As(err, &muitoGrande) {
+		enviarErro(w, r, "Corpo da requisição muito grande", http.StatusRequestEntityTooLarge,
+			[]string{fmt.Sprintf("O limite é de %d bytes", muitoGrande.Limit)})
+		return
+	}
+	enviarErro(w, r, "Corpo da requisição inválido", http.StatusBadRequest, []string{err.Error()})
+}
+
+// enviarErroValidacao responde 400 com os erros por campo da validação
+func enviarErroValidacao(w http.ResponseWriter, r *http.Request, erros models.ErrosValidacao) {
+	escreverErro(w, r, "Dados inválidos", http.StatusBadRequest, CodigoDadosInvalidos, erros.Mensagens(), erros)
+}
+
+// RotaNaoEncontrada responde 404 para caminhos sem rota registrada
+func RotaNaoEncontrada(w http.ResponseWriter, r *http.Request) {
+	configurarCabecalhos(w)
+	enviarErro(w, r, "Rota não encontrada", http.StatusNotFound, []string{r.Method + " " + r.URL.Path})
+}
+
+// MetodoNaoPermitido responde 405; o roteador já preencheu o cabeçalho Allow
+func MetodoNaoPermitido(w http.ResponseWriter, r *http.Request) {
+	configurarCabecalhos(w)
+	enviarErro(w, r, "Método não permitido", http.StatusMethodNotAllowed,
+		[]string{"Métodos aceitos: " + w.Header().Get("Allow")})
+}
+
+// escreverErro escolhe o formato da resposta de erro pelo cabeçalho Accept:
+// application/problem+json (RFC 7807) para quem pedir, ou o formato legado
+// RespostaErro para os clientes existentes
+func escreverErro(w http.ResponseWriter, r *http.Request, mensagem string, status int, codigo string, detalhes []string, campos models.ErrosValidacao) {
+	w.Header().Add("Vary", "Accept")
+
+	if !aceitaProblemaJSON(r) {
+		erro := models.RespostaErro{
+			Erro:       mensagem,
+			Codigo:     status,
+			CodigoErro: codigo,
+			Detalhes:   detalhes,
+		}
+		enviarJSON(w, erro, status)
+		return
+	}
+
+	problema := models.Problema{
+		Tipo:       "/erros/" + codigo,
+		Titulo:     mensagem,
+		Status:     status,
+		Detalhe:    strings.Join(detalhes, "; "),
+		Instancia:  r.URL.RequestURI(),
+		CodigoErro: codigo,
+		Erros:      campos,
+	}
+
+	w.Header().Set("Content-Type", TipoProblemaJSON+"; charset=utf-8")
+	enviarJSON(w, problema, status)
+}
+
+// TipoProblemaJSON é o media type das respostas de erro da RFC 7807
+const TipoProblemaJSON = "application/problem+json"
+
+// aceitaProblemaJSON indica se o cliente prefere problem+json a application/json.
+// Apenas menções explícitas contam: "*/*" mantém o formato legado.
+func aceitaProblemaJSON(r *http.Request) bool {
+	qualidadeProblema, qualidadeJSON := -1.0, -1.0
+
+	for _, faixa := range strings.Split(r.Header.Get("Accept"), ",") {
+		partes := strings.Split(faixa, ";")
+		tipo := strings.ToLower(strings.TrimSpace(partes[0]))
+
+		qualidade := 1.0
+		for _, parametro := range partes[1:] {
+			chave, valor, ok := strings.Cut(strings.TrimSpace(parametro), "=")
+			if ok && strings.EqualFold(chave, "q") {
+				if q, err := strconv.ParseFloat(valor, 64); err == nil {
+					qualidade = q
+				}
+			}
+		}
+
+		switch tipo {
+		case TipoProblemaJSON:
+			qualidadeProblema = qualidade
+		case "application/json":
+			qualidadeJSON = qualidade
+		}
+	}
+
+	return qualidadeProblema > 0 && qualidadeProblema >= qualidadeJSON
+}
+
+// capitalizar deixa a primeira letra maiúscula, como nas demais mensagens da API
+func capitalizar(texto string) string {
+	if texto == "" {
+		return texto
+	}
+	runas := []rune(texto)
+	runas[0] = unicode.ToUpper(runas[0])
+	return string(runas)
+}
@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"api-filmes/internal/database"
+	"api-filmes/internal/models"
+)
+
+// etagFilme deriva o ETag forte da versão do filme (ex.: "3")
+func etagFilme(filme *models.Filme) string {
+	return strconv.Quote(strconv.Itoa(filme.Versao))
+}
+
+// etagFilmeDetalhado deriva o ETag de GET /filmes/{id}. O bloco de créditos
+// embutido muda sem alterar a versão do filme, então o ETag soma à versão um
+// resumo do bloco (ex.: "3-5f0c9a1e")
+func etagFilmeDetalhado(detalhe *models.FilmeDetalhado) string {
+	resumo := fnv.New32a()
+	json.NewEncoder(resumo).Encode(detalhe.Creditos)
+	return strconv.Quote(fmt.Sprintf("%d-%08x", detalhe.Versao, resumo.Sum32()))
+}
+
+// definirETag envia o ETag da representação atual do filme
+func definirETag(w http.ResponseWriter, filme *models.Filme) {
+	w.Header().Set("ETag", etagFilme(filme))
+}
+
+// versoesEsperadas interpreta If-Match. Sem o cabeçalho, ou com "*", qualquer
+// versão serve e o resultado é nil. Cada ETag forte da lista vira uma versão
+// aceita; ETags fracos ou que não vieram desta API nunca correspondem
+// (RFC 9110, 13.1.1). ok é falso quando nenhuma entidade da lista pode
+// corresponder, o que resulta em 412.
+func versoesEsperadas(r *http.Request) (versoes []int, ok bool) {
+	valor := strings.TrimSpace(r.Header.Get("If-Match"))
+	if valor == "" || valor == "*" {
+		return nil, true
+	}
+
+	for _, etag := range strings.Split(valor, ",") {
+		etag = strings.TrimSpace(etag)
+		if !strings.HasPrefix(etag, `"`) {
+			continue
+		}
+		texto, err := strconv.Unquote(etag)
+		if err != nil {
+			continue
+		}
+		// O ETag de GET /filmes/{id} traz o resumo dos créditos depois da
+		// versão; as escritas não mexem nos créditos e comparam só a versão
+		texto, _, _ = strings.Cut(texto, "-")
+		versao, err := strconv.Atoi(texto)
+		if err != nil || versao < 1 {
+			continue
+		}
+		versoes = append(versoes, versao)
+	}
+	return versoes, len(versoes) > 0
+}
+
+// versaoCorrespondente escolhe, entre as versões aceitas por If-Match, a que
+// será exigida no WHERE do banco. Com uma só não há o que escolher; com
+// várias, vale a versão atual do filme se ela estiver na lista, e a escrita
+// continua protegida caso o filme mude entre a busca e a gravação. ok falso
+// indica que nenhuma entidade corresponde (412).
+func (fh *FilmeHandler) versaoCorrespondente(ctx context.Context, id int, versoes []int) (versao int, ok bool, err error) {
+	switch len(versoes) {
+	case 0:
+		return database.QualquerVersao, true, nil
+	case 1:
+		return versoes[0], true, nil
+	}
+
+	atual, err := fh.repositorio.BuscarFilmePorID(ctx, id)
+	if err != nil {
+		return 0, false, err
+	}
+	if !slices.Contains(versoes, atual.Versao) {
+		return 0, false, nil
+	}
+	return atual.Versao, true, nil
+}
+
+// naoModificado avalia If-None-Match contra o ETag atual com comparação fraca
+func naoModificado(r *http.Request, atual string) bool {
+	valor := r.Header.Get("If-None-Match")
+	if valor == "" {
+		return false
+	}
+
+	for _, etag := range strings.Split(valor, ",") {
+		etag = strings.TrimPrefix(strings.TrimSpace(etag), "W/")
+		if etag == "*" || etag == atual {
+			return true
+		}
+	}
+	return false
+}
+
+// enviarPrecondicaoFalhou responde 412 quando If-Match não pode corresponder
+func enviarPrecondicaoFalhou(w http.ResponseWriter, r *http.Request) {
+	enviarErro(w, r, "Filme foi alterado por outra requisição", http.StatusPreconditionFailed,
+		[]string{"Busque a versão atual do filme (ETag) e tente novamente"})
+}
@@ -1,142 +1,258 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
 	"net/http"
 	"strconv"
 	"strings"
 
+	"api-filmes/internal/config"
 	"api-filmes/internal/database"
 	"api-filmes/internal/models"
+	"api-filmes/internal/roteador"
 )
 
+// tamanhoMaximoCorpo limita os corpos JSON de criação e substituição
+const tamanhoMaximoCorpo = 1 << 20
+
 // FilmeHandler contém as dependências para os handlers de filme
 type FilmeHandler struct {
-	bancoDados *database.BancoDados
+	repositorio database.Repositorio
+	timeouts    *config.ConfiguracaoTimeouts
 }
 
 // NovoFilmeHandler cria uma nova instância do handler
-func NovoFilmeHandler(bd *database.BancoDados) *FilmeHandler {
-	return &FilmeHandler{bancoDados: bd}
+func NovoFilmeHandler(repositorio database.Repositorio, timeouts *config.ConfiguracaoTimeouts) *FilmeHandler {
+	return &FilmeHandler{repositorio: repositorio, timeouts: timeouts}
 }
 
-// ManipularFilmes lida com requisições para /filmes
-func (fh *FilmeHandler) ManipularFilmes(w http.ResponseWriter, r *http.Request) {
-	configurarCabecalhos(w)
+// contexto deriva da requisição um contexto com o timeout configurado para a rota.
+// Se o cliente desconectar, o contexto é cancelado e a query é interrompida.
+func (fh *FilmeHandler) contexto(r *http.Request, rota string) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(r.Context(), fh.timeouts.Timeout(rota))
+}
+
+// RegistrarRotas associa as consultas de filmes, abertas a qualquer cliente
+func (fh *FilmeHandler) RegistrarRotas(rt roteador.Rotas) {
+	rt.Get("/filmes", fh.ListarFilmes)
+	rt.Get("/filmes/busca", fh.BuscarFilmes)
+	rt.Get("/filmes/{id}", fh.BuscarFilmePorID)
+	rt.Get("/filmes/{id}/elenco", fh.ListarElenco)
+	rt.Get("/filmes/{id}/versoes", fh.ListarVersoes)
+	rt.Get("/filmes/{id}/versoes/diferenca", fh.DiferencaVersoes)
+	rt.Get("/filmes/{id}/versoes/{n}", fh.BuscarVersao)
+	rt.Get("/generos", fh.ListarGeneros)
+}
+
+// RegistrarRotasEscrita associa as operações que alteram o catálogo e a
+// consulta à lixeira, que só quem pode restaurar precisa ver; o grupo
+// recebido deve exigir usuário autenticado
+func (fh *FilmeHandler) RegistrarRotasEscrita(rt roteador.Rotas) {
+	rt.Get("/filmes/lixeira", fh.ListarLixeira)
+	rt.Post("/filmes", fh.CriarFilme)
+	rt.Put("/filmes/{id}", fh.AtualizarFilme)
+	rt.Patch("/filmes/{id}", fh.AplicarPatchFilme)
+	rt.Delete("/filmes/{id}", fh.DeletarFilme)
+	rt.Post("/filmes/{id}/restaurar", fh.RestaurarFilme)
+	rt.Post("/filmes/{id}/elenco", fh.CriarCredito)
+	rt.Delete("/filmes/{id}/elenco/{credito}", fh.DeletarCredito)
+	rt.Post("/filmes/{id}/versoes/{n}/reverter", fh.ReverterFilme)
+}
 
-	switch r.Method {
-	case "GET":
-		fh.listarFilmes(w, r)
-	case "POST":
-		fh.criarFilme(w, r)
-	default:
-		enviarErro(w, "Método não permitido", http.StatusMethodNotAllowed, nil)
+// RegistrarRotasAdmin associa as operações irreversíveis da lixeira, a
+// manutenção da taxonomia de gêneros e a auditoria, que expõe atores e IDs
+// de requisição; o grupo recebido deve exigir credencial de administrador
+func (fh *FilmeHandler) RegistrarRotasAdmin(rt roteador.Rotas) {
+	rt.Delete("/filmes/lixeira", fh.EsvaziarLixeira)
+	rt.Delete("/filmes/lixeira/{id}", fh.ExpurgarFilme)
+	rt.Post("/generos", fh.CriarGenero)
+	rt.Get("/filmes/{id}/historico", fh.HistoricoFilme)
+	rt.Get("/auditoria", fh.ListarAuditoria)
+}
+
+// lerID extrai o parâmetro {id} da rota; responde 400 se não for numérico
+func lerID(w http.ResponseWriter, r *http.Request) (int, bool) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil || id < 1 {
+		enviarErro(w, r, "ID inválido", http.StatusBadRequest, []string{"ID deve ser um número inteiro positivo"})
+		return 0, false
 	}
+	return id, true
 }
 
-// ManipularFilmeIndividual lida com requisições para /filmes/{id}
-func (fh *FilmeHandler) ManipularFilmeIndividual(w http.ResponseWriter, r *http.Request) {
+// ListarFilmes retorna uma página de filmes com filtros e ordenação
+func (fh *FilmeHandler) ListarFilmes(w http.ResponseWriter, r *http.Request) {
 	configurarCabecalhos(w)
 
-	// Extrair ID da URL
-	caminho := strings.TrimPrefix(r.URL.Path, "/filmes/")
-	if caminho == "" {
-		enviarErro(w, "ID do filme é obrigatório", http.StatusBadRequest, nil)
+	ctx, cancelar := fh.contexto(r, "listar")
+	defer cancelar()
+
+	slog.DebugContext(ctx, "listando filmes", "consulta", r.URL.RawQuery)
+
+	filtro, erros := lerFiltroFilmes(r)
+	if len(erros) > 0 {
+		enviarErro(w, r, "Parâmetros inválidos", http.StatusBadRequest, erros)
 		return
 	}
 
-	id, err := strconv.Atoi(caminho)
+	// Buscar um item a mais para saber se existe próxima página
+	consulta := *filtro
+	consulta.Limite = filtro.Limite + 1
+
+	filmes, err := fh.repositorio.BuscarTodosFilmes(ctx, &consulta)
 	if err != nil {
-		enviarErro(w, "ID inválido", http.StatusBadRequest, []string{"ID deve ser um número inteiro"})
+		responderErro(w, r, ctx, err, "Erro ao buscar filmes")
 		return
 	}
 
-	switch r.Method {
-	case "GET":
-		fh.buscarFilmePorID(w, r, id)
-	case "PUT":
-		fh.atualizarFilme(w, r, id)
-	case "DELETE":
-		fh.deletarFilme(w, r, id)
-	default:
-		enviarErro(w, "Método não permitido", http.StatusMethodNotAllowed, nil)
+	haMais := len(filmes) > filtro.Limite
+	if haMais {
+		filmes = filmes[:filtro.Limite]
 	}
+
+	total, err := fh.repositorio.ContarFilmes(ctx, filtro)
+	if err != nil {
+		if ctx.Err() != nil {
+			responderErro(w, r, ctx, err, "Erro ao contar filmes")
+			return
+		}
+		slog.WarnContext(ctx, "erro ao contar filmes; usando total estimado", "erro", err)
+		total = filtro.Deslocamento + len(filmes)
+	}
+
+	resposta := models.RespostaFilmes{
+		Filmes:    filmes,
+		Total:     total,
+		Paginacao: montarPaginacao(r, filtro, filmes, total, haMais),
+	}
+
+	slog.InfoContext(ctx, "filmes listados", "quantidade", len(filmes), "total", total)
+	enviarJSON(w, resposta, http.StatusOK)
 }
 
-// listarFilmes retorna todos os filmes
-func (fh *FilmeHandler) listarFilmes(w http.ResponseWriter, r *http.Request) {
-	fmt.Println("📋 Listando filmes...")
+// BuscarFilmes executa a busca textual em /filmes/busca?q=...
+func (fh *FilmeHandler) BuscarFilmes(w http.ResponseWriter, r *http.Request) {
+	configurarCabecalhos(w)
+
+	ctx, cancelar := fh.contexto(r, "busca")
+	defer cancelar()
 
-	filmes, err := fh.bancoDados.BuscarTodosFilmes()
-	if err != nil {
-		fmt.Printf("❌ Erro ao buscar filmes: %v\n", err)
-		enviarErro(w, "Erro interno do servidor", http.StatusInternalServerError, nil)
+	termo := strings.TrimSpace(r.URL.Query().Get("q"))
+	slog.DebugContext(ctx, "buscando filmes", "termo", termo)
+
+	if len([]rune(termo)) < 2 {
+		enviarErro(w, r, "Parâmetros inválidos", http.StatusBadRequest, []string{"q deve ter pelo menos 2 caracteres"})
 		return
 	}
 
-	total, err := fh.bancoDados.ContarFilmes()
+	limite := models.LimitePadrao
+	if valor := r.URL.Query().Get("limite"); valor != "" {
+		numero, err := strconv.Atoi(valor)
+		if err != nil || numero < 1 || numero > models.LimiteMaximo {
+			enviarErro(w, r, "Parâmetros inválidos", http.StatusBadRequest,
+				[]string{fmt.Sprintf("limite deve estar entre 1 e %d", models.LimiteMaximo)})
+			return
+		}
+		limite = numero
+	}
+
+	resultados, err := fh.repositorio.BuscarFilmesPorTexto(ctx, termo, limite)
 	if err != nil {
-		fmt.Printf("⚠️ Erro ao contar filmes: %v\n", err)
-		total = len(filmes)
+		responderErro(w, r, ctx, err, "Erro na busca")
+		return
 	}
 
-	resposta := models.RespostaFilmes{
-		Filmes: filmes,
-		Total:  total,
+	resposta := models.RespostaBusca{
+		Consulta:   termo,
+		Resultados: resultados,
+		Total:      len(resultados),
 	}
 
-	fmt.Printf("✅ Listados %d filmes\n", len(filmes))
+	slog.InfoContext(ctx, "busca concluída", "termo", termo, "quantidade", len(resultados))
 	enviarJSON(w, resposta, http.StatusOK)
 }
 
-// buscarFilmePorID retorna um filme específico
-func (fh *FilmeHandler) buscarFilmePorID(w http.ResponseWriter, r *http.Request, id int) {
-	fmt.Printf("🔍 Buscando filme ID: %d\n", id)
+// BuscarFilmePorID retorna um filme específico
+func (fh *FilmeHandler) BuscarFilmePorID(w http.ResponseWriter, r *http.Request) {
+	configurarCabecalhos(w)
 
-	filme, err := fh.bancoDados.BuscarFilmePorID(id)
+	id, ok := lerID(w, r)
+	if !ok {
+		return
+	}
+
+	ctx, cancelar := fh.contexto(r, "buscar")
+	defer cancelar()
+
+	slog.DebugContext(ctx, "buscando filme", "filme_id", id)
+
+	filme, err := fh.repositorio.BuscarFilmePorID(ctx, id)
 	if err != nil {
-		if strings.Contains(err.Error(), "não encontrado") {
-			enviarErro(w, fmt.Sprintf("Filme com ID %d não encontrado", id), http.StatusNotFound, nil)
-		} else {
-			fmt.Printf("❌ Erro ao buscar filme: %v\n", err)
-			enviarErro(w, "Erro interno do servidor", http.StatusInternalServerError, nil)
-		}
+		responderErro(w, r, ctx, err, "Erro ao buscar filme")
 		return
 	}
 
-	fmt.Printf("✅ Filme encontrado: %s\n", filme.Titulo)
-	enviarJSON(w, filme, http.StatusOK)
+	creditos, err := fh.repositorio.ListarCreditos(ctx, id, "")
+	if err != nil {
+		responderErro(w, r, ctx, err, "Erro ao listar elenco")
+		return
+	}
+	detalhe := models.FilmeDetalhado{Filme: filme, Creditos: models.ResumirCreditos(creditos)}
+
+	// O ETag cobre também os créditos embutidos
+	etag := etagFilmeDetalhado(&detalhe)
+	w.Header().Set("ETag", etag)
+	if naoModificado(r, etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	slog.InfoContext(ctx, "filme encontrado", "filme_id", filme.ID, "titulo", filme.Titulo)
+	enviarJSON(w, detalhe, http.StatusOK)
 }
 
-// criarFilme cria um novo filme
-func (fh *FilmeHandler) criarFilme(w http.ResponseWriter, r *http.Request) {
-	fmt.Println("➕ Criando novo filme...")
+// CriarFilme cria um novo filme
+func (fh *FilmeHandler) CriarFilme(w http.ResponseWriter, r *http.Request) {
+	configurarCabecalhos(w)
+
+	ctx, cancelar := fh.contexto(r, "criar")
+	defer cancelar()
+
+	slog.DebugContext(ctx, "criando filme")
 
 	var filme models.FilmeParaCriar
 
 	// Decodificar JSON do body
-	if err := json.NewDecoder(r.Body).Decode(&filme); err != nil {
-		enviarErro(w, "JSON inválido", http.StatusBadRequest, []string{"Verifique a sintaxe do JSON"})
+	if !lerCorpoJSON(w, r, &filme, tamanhoMaximoCorpo) {
+		return
+	}
+
+	catalogo, err := fh.catalogoGeneros(ctx)
+	if err != nil {
+		responderErro(w, r, ctx, err, "Erro ao carregar gêneros")
 		return
 	}
 
 	// Validar dados
-	if erros := models.ValidarFilme(&filme); len(erros) > 0 {
-		enviarErro(w, "Dados inválidos", http.StatusBadRequest, erros)
+	if erros := models.ValidarFilme(&filme, catalogo); len(erros) > 0 {
+		enviarErroValidacao(w, r, erros)
 		return
 	}
 
 	// Salvar no banco
-	novoFilme, err := fh.bancoDados.CriarFilme(&filme)
+	novoFilme, err := fh.repositorio.CriarFilme(ctx, &filme)
 	if err != nil {
-		fmt.Printf("❌ Erro ao criar filme: %v\n", err)
-		enviarErro(w, "Erro interno do servidor", http.StatusInternalServerError, nil)
+		responderErro(w, r, ctx, err, "Erro ao criar filme")
 		return
 	}
 
-	fmt.Printf("✅ Filme criado: %s (ID: %d)\n", novoFilme.Titulo, novoFilme.ID)
+	slog.InfoContext(ctx, "filme criado", "filme_id", novoFilme.ID, "titulo", novoFilme.Titulo)
 
+	definirETag(w, novoFilme)
 	resposta := models.RespostaSucesso{
 		Mensagem: "Filme criado com sucesso",
 		Dados:    novoFilme,
@@ -145,38 +261,72 @@ func (fh *FilmeHandler) criarFilme(w http.ResponseWriter, r *http.Request) {
 	enviarJSON(w, resposta, http.StatusCreated)
 }
 
-// atualizarFilme atualiza um filme existente
-func (fh *FilmeHandler) atualizarFilme(w http.ResponseWriter, r *http.Request, id int) {
-	fmt.Printf("✏️ Atualizando filme ID: %d\n", id)
+// AtualizarFilme substitui o filme inteiro (PUT): campos opcionais
+// ausentes no corpo são apagados
+func (fh *FilmeHandler) AtualizarFilme(w http.ResponseWriter, r *http.Request) {
+	configurarCabecalhos(w)
 
-	var filme models.FilmeParaAtualizar
+	id, ok := lerID(w, r)
+	if !ok {
+		return
+	}
+
+	versoes, ok := versoesEsperadas(r)
+	if !ok {
+		enviarPrecondicaoFalhou(w, r)
+		return
+	}
+
+	ctx, cancelar := fh.contexto(r, "atualizar")
+	defer cancelar()
+
+	slog.DebugContext(ctx, "substituindo filme", "filme_id", id)
+
+	var filme models.FilmeParaCriar
 
 	// Decodificar JSON
-	if err := json.NewDecoder(r.Body).Decode(&filme); err != nil {
-		enviarErro(w, "JSON inválido", http.StatusBadRequest, []string{"Verifique a sintaxe do JSON"})
+	if !lerCorpoJSON(w, r, &filme, tamanhoMaximoCorpo) {
+		return
+	}
+
+	versao, ok, err := fh.versaoCorrespondente(ctx, id, versoes)
+	if err != nil {
+		responderErro(w, r, ctx, err, "Erro ao buscar filme")
+		return
+	}
+	if !ok {
+		enviarPrecondicaoFalhou(w, r)
+		return
+	}
+
+	fh.gravarFilme(w, r, ctx, id, &filme, versao)
+}
+
+// gravarFilme valida a nova versão completa do filme e a persiste se o
+// filme ainda estiver na versão informada; compartilhado por PUT e PATCH
+func (fh *FilmeHandler) gravarFilme(w http.ResponseWriter, r *http.Request, ctx context.Context, id int, filme *models.FilmeParaCriar, versao int) {
+	catalogo, err := fh.catalogoGeneros(ctx)
+	if err != nil {
+		responderErro(w, r, ctx, err, "Erro ao carregar gêneros")
 		return
 	}
 
 	// Validar dados
-	if erros := models.ValidarFilmeParaAtualizar(&filme); len(erros) > 0 {
-		enviarErro(w, "Dados inválidos", http.StatusBadRequest, erros)
+	if erros := models.ValidarFilme(filme, catalogo); len(erros) > 0 {
+		enviarErroValidacao(w, r, erros)
 		return
 	}
 
 	// Atualizar no banco
-	filmeAtualizado, err := fh.bancoDados.AtualizarFilme(id, &filme)
+	filmeAtualizado, err := fh.repositorio.AtualizarFilme(ctx, id, filme, versao)
 	if err != nil {
-		if strings.Contains(err.Error(), "não encontrado") {
-			enviarErro(w, fmt.Sprintf("Filme com ID %d não encontrado", id), http.StatusNotFound, nil)
-		} else {
-			fmt.Printf("❌ Erro ao atualizar filme: %v\n", err)
-			enviarErro(w, "Erro interno do servidor", http.StatusInternalServerError, nil)
-		}
+		responderErro(w, r, ctx, err, "Erro ao atualizar filme")
 		return
 	}
 
-	fmt.Printf("✅ Filme atualizado: %s\n", filmeAtualizado.Titulo)
+	slog.InfoContext(ctx, "filme atualizado", "filme_id", filmeAtualizado.ID, "titulo", filmeAtualizado.Titulo)
 
+	definirETag(w, filmeAtualizado)
 	resposta := models.RespostaSucesso{
 		Mensagem: "Filme atualizado com sucesso",
 		Dados:    filmeAtualizado,
@@ -185,25 +335,46 @@ func (fh *FilmeHandler) atualizarFilme(w http.ResponseWriter, r *http.Request, i
 	enviarJSON(w, resposta, http.StatusOK)
 }
 
-// deletarFilme remove um filme
-func (fh *FilmeHandler) deletarFilme(w http.ResponseWriter, r *http.Request, id int) {
-	fmt.Printf("🗑️ Deletando filme ID: %d\n", id)
+// DeletarFilme move um filme para a lixeira
+func (fh *FilmeHandler) DeletarFilme(w http.ResponseWriter, r *http.Request) {
+	configurarCabecalhos(w)
 
-	err := fh.bancoDados.DeletarFilme(id)
+	id, ok := lerID(w, r)
+	if !ok {
+		return
+	}
+
+	versoes, ok := versoesEsperadas(r)
+	if !ok {
+		enviarPrecondicaoFalhou(w, r)
+		return
+	}
+
+	ctx, cancelar := fh.contexto(r, "deletar")
+	defer cancelar()
+
+	slog.DebugContext(ctx, "deletando filme", "filme_id", id)
+
+	versao, ok, err := fh.versaoCorrespondente(ctx, id, versoes)
 	if err != nil {
-		if strings.Contains(err.Error(), "não encontrado") {
-			enviarErro(w, fmt.Sprintf("Filme com ID %d não encontrado", id), http.StatusNotFound, nil)
-		} else {
-			fmt.Printf("❌ Erro ao deletar filme: %v\n", err)
-			enviarErro(w, "Erro interno do servidor", http.StatusInternalServerError, nil)
-		}
+		responderErro(w, r, ctx, err, "Erro ao buscar filme")
+		return
+	}
+	if !ok {
+		enviarPrecondicaoFalhou(w, r)
+		return
+	}
+
+	err = fh.repositorio.DeletarFilme(ctx, id, versao)
+	if err != nil {
+		responderErro(w, r, ctx, err, "Erro ao deletar filme")
 		return
 	}
 
-	fmt.Printf("✅ Filme deletado (ID: %d)\n", id)
+	slog.InfoContext(ctx, "filme deletado", "filme_id", id)
 
 	resposta := models.RespostaSucesso{
-		Mensagem: "Filme deletado com sucesso",
+		Mensagem: "Filme movido para a lixeira",
 	}
 
 	enviarJSON(w, resposta, http.StatusOK)
@@ -213,25 +384,33 @@ func (fh *FilmeHandler) deletarFilme(w http.ResponseWriter, r *http.Request, id
 func configurarCabecalhos(w http.ResponseWriter) {
 	w.Header().Set("Content-Type", "application/json; charset=utf-8")
 	w.Header().Set("Access-Control-Allow-Origin", "*")
-	w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-	w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+	w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
+	w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Request-ID, If-Match, If-None-Match, X-Admin-Token")
+	w.Header().Set("Access-Control-Expose-Headers", "X-Request-ID, ETag, Deprecation, Sunset, Link, Retry-After, WWW-Authenticate")
+}
+
+// lerCorpoJSON decodifica o corpo limitado a limite bytes; responde 413 se
+// passar do limite e 400 se o JSON for inválido
+func lerCorpoJSON(w http.ResponseWriter, r *http.Request, destino any, limite int64) bool {
+	err := json.NewDecoder(http.MaxBytesReader(w, r.Body, limite)).Decode(destino)
+	if err == nil {
+		return true
+	}
+
+	var muitoGrande *http.MaxBytesError
+	if errors.As(err, &muitoGrande) {
+		enviarErroLeituraCorpo(w, r, err)
+	} else {
+		enviarErro(w, r, "JSON inválido", http.StatusBadRequest, []string{"Verifique a sintaxe do JSON"})
+	}
+	return false
 }
 
 func enviarJSON(w http.ResponseWriter, dados interface{}, status int) {
 	w.WriteHeader(status)
 
 	if err := json.NewEncoder(w).Encode(dados); err != nil {
-		fmt.Printf("❌ Erro ao codificar JSON: %v\n", err)
+		slog.Error("erro ao codificar JSON", "erro", err)
 		http.Error(w, "Erro interno", http.StatusInternalServerError)
 	}
 }
-
-func enviarErro(w http.ResponseWriter, mensagem string, status int, detalhes []string) {
-	erro := models.RespostaErro{
-		Erro:     mensagem,
-		Codigo:   status,
-		Detalhes: detalhes,
-	}
-
-	enviarJSON(w, erro, status)
-}
@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"api-filmes/internal/autenticacao"
+	"api-filmes/internal/config"
+	"api-filmes/internal/database"
+	"api-filmes/internal/roteador"
+)
+
+// novoServidorTeste monta as rotas de filmes como o main, sobre um
+// repositório em memória vazio, e devolve um token de acesso válido
+func novoServidorTeste(t *testing.T) (http.Handler, string) {
+	t.Helper()
+
+	chave, err := autenticacao.NovaChaveSegredo("teste", []byte("0123456789abcdef0123456789abcdef"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	conjunto, err := autenticacao.NovoConjuntoChaves(chave)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chaveiro, err := autenticacao.NovoChaveiro(conjunto, chave.KID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tokens := autenticacao.NovoGerenciadorTokens(chaveiro, "api-filmes", "api-filmes", time.Minute)
+	token, _, err := tokens.Emitir(&autenticacao.Principal{UsuarioID: 1, Email: "ana@ex.com", Nome: "Ana"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fh := NovoFilmeHandler(database.NovoRepositorioMemoria(), &config.ConfiguracaoTimeouts{Padrao: 5 * time.Second})
+	rotas := roteador.Novo()
+	rotas.NaoEncontrado = http.HandlerFunc(RotaNaoEncontrada)
+	rotas.MetodoNaoPermitido = http.HandlerFunc(MetodoNaoPermitido)
+	fh.RegistrarRotas(rotas.Grupo(VersaoAtual))
+	fh.RegistrarRotasEscrita(rotas.Grupo(VersaoAtual, ExigirUsuarioMiddleware))
+
+	return AutenticacaoMiddleware(tokens)(rotas), token
+}
+
+func TestCicloDeVidaFilme(t *testing.T) {
+	servidor, token := novoServidorTeste(t)
+
+	const (
+		matrix   = `{"titulo": "Matrix", "ano_lancamento": 1999, "generos": ["ficcao-cientifica"], "avaliacao": 8.7}`
+		reloaded = `{"titulo": "Matrix Reloaded", "ano_lancamento": 2003, "generos": ["ficcao-cientifica"]}`
+	)
+
+	// Os passos rodam em ordem sobre o mesmo repositório
+	passos := []struct {
+		nome      string
+		metodo    string
+		caminho   string
+		corpo     string
+		anonimo   bool
+		ifMatch   string
+		status    int
+		etag      string
+		contem    []string
+		naoContem []string
+	}{
+		{nome: "criar sem token", metodo: "POST", caminho: "/v1/filmes", corpo: matrix, anonimo: true, status: 401},
+		{nome: "criar com JSON inválido", metodo: "POST", caminho: "/v1/filmes", corpo: `{"titulo":`, status: 400},
+		{nome: "criar sem campos obrigatórios", metodo: "POST", caminho: "/v1/filmes", corpo: `{"avaliacao": 11}`, status: 400,
+			contem: []string{`"codigo_erro":"dados_invalidos"`, "título é obrigatório"}},
+		{nome: "criar com gênero desconhecido", metodo: "POST", caminho: "/v1/filmes", corpo: `{"titulo": "X", "ano_lancamento": 2000, "generos": ["inexistente"]}`, status: 400},
+		{nome: "criar com corpo grande demais", metodo: "POST", caminho: "/v1/filmes", corpo: `{"titulo": "` + strings.Repeat("a", tamanhoMaximoCorpo) + `"}`, status: 413},
+		{nome: "criar", metodo: "POST", caminho: "/v1/filmes", corpo: matrix, status: 201, etag: `"1"`,
+			contem: []string{`"id":1`, `"titulo":"Matrix"`, `"slug":"ficcao-cientifica"`, `"versao":1`}},
+		{nome: "criar sem avaliação", metodo: "POST", caminho: "/v1/filmes", corpo: `{"titulo": "Sem Nota", "ano_lancamento": 2001}`, status: 201,
+			contem: []string{`"id":2`, `"avaliacao":null`}},
+
+		{nome: "buscar", metodo: "GET", caminho: "/v1/filmes/1", status: 200, etag: `"1-`,
+			contem: []string{`"titulo":"Matrix"`, `"avaliacao":8.7`, `"creditos"`}},
+		{nome: "buscar inexistente", metodo: "GET", caminho: "/v1/filmes/99", status: 404},
+		{nome: "buscar com ID inválido", metodo: "GET", caminho: "/v1/filmes/abc", status: 400},
+
+		{nome: "listar", metodo: "GET", caminho: "/v1/filmes", status: 200,
+			contem: []string{`"total":2`, `"titulo":"Matrix"`, `"titulo":"Sem Nota"`}},
+		{nome: "listar sem nota por último", metodo: "GET", caminho: "/v1/filmes?sort=-avaliacao&limite=1", status: 200,
+			contem: []string{`"titulo":"Matrix"`}, naoContem: []string{"Sem Nota"}},
+		{nome: "listar com filtro inválido", metodo: "GET", caminho: "/v1/filmes?limite=abc", status: 400},
+
+		{nome: "versões sem autoria", metodo: "GET", caminho: "/v1/filmes/1/versoes", status: 200,
+			contem: []string{`"versao":1`}, naoContem: []string{`"ator"`, `"id_requisicao"`}},
+
+		{nome: "atualizar com versão antiga", metodo: "PUT", caminho: "/v1/filmes/1", corpo: reloaded, ifMatch: `"7"`, status: 412},
+		{nome: "atualizar com JSON inválido", metodo: "PUT", caminho: "/v1/filmes/1", corpo: `[`, ifMatch: `"1"`, status: 400},
+		{nome: "atualizar com dados inválidos", metodo: "PUT", caminho: "/v1/filmes/1", corpo: `{"titulo": ""}`, ifMatch: `"1"`, status: 400},
+		{nome: "atualizar inexistente", metodo: "PUT", caminho: "/v1/filmes/99", corpo: reloaded, status: 404},
+		{nome: "atualizar", metodo: "PUT", caminho: "/v1/filmes/1", corpo: reloaded, ifMatch: `"1"`, status: 200, etag: `"2"`,
+			contem: []string{`"titulo":"Matrix Reloaded"`, `"avaliacao":null`, `"versao":2`}},
+		{nome: "buscar após atualizar", metodo: "GET", caminho: "/v1/filmes/1", status: 200, etag: `"2-`,
+			contem: []string{`"ano_lancamento":2003`}},
+
+		{nome: "deletar sem token", metodo: "DELETE", caminho: "/v1/filmes/1", anonimo: true, status: 401},
+		{nome: "deletar com versão antiga", metodo: "DELETE", caminho: "/v1/filmes/1", ifMatch: `"1"`, status: 412},
+		{nome: "deletar", metodo: "DELETE", caminho: "/v1/filmes/1", ifMatch: `"2"`, status: 200},
+		{nome: "buscar deletado", metodo: "GET", caminho: "/v1/filmes/1", status: 404},
+		{nome: "deletar de novo", metodo: "DELETE", caminho: "/v1/filmes/1", status: 404},
+		{nome: "versões de filme na lixeira", metodo: "GET", caminho: "/v1/filmes/1/versoes/1", status: 404},
+		{nome: "lixeira sem token", metodo: "GET", caminho: "/v1/filmes/lixeira", anonimo: true, status: 401},
+		{nome: "lixeira", metodo: "GET", caminho: "/v1/filmes/lixeira", status: 200, contem: []string{`"titulo":"Matrix Reloaded"`}},
+		{nome: "listar após deletar", metodo: "GET", caminho: "/v1/filmes", status: 200,
+			contem: []string{`"total":1`}, naoContem: []string{"Matrix"}},
+	}
+
+	for _, passo := range passos {
+		t.Run(passo.nome, func(t *testing.T) {
+			requisicao := httptest.NewRequest(passo.metodo, passo.caminho, strings.NewReader(passo.corpo))
+			if !passo.anonimo {
+				requisicao.Header.Set("Authorization", "Bearer "+token)
+			}
+			if passo.ifMatch != "" {
+				requisicao.Header.Set("If-Match", passo.ifMatch)
+			}
+
+			gravador := httptest.NewRecorder()
+			servidor.ServeHTTP(gravador, requisicao)
+
+			resposta := gravador.Body.String()
+			if gravador.Code != passo.status {
+				t.Fatalf("status = %d, esperado %d; corpo: %s", gravador.Code, passo.status, resposta)
+			}
+			if etag := gravador.Header().Get("ETag"); !strings.HasPrefix(etag, passo.etag) {
+				t.Errorf("ETag = %q, esperado começar com %q", etag, passo.etag)
+			}
+			for _, trecho := range passo.contem {
+				if !strings.Contains(resposta, trecho) {
+					t.Errorf("corpo sem %s: %s", trecho, resposta)
+				}
+			}
+			for _, trecho := range passo.naoContem {
+				if strings.Contains(resposta, trecho) {
+					t.Errorf("corpo não deveria ter %s: %s", trecho, resposta)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+
+	"api-filmes/internal/models"
+)
+
+// ListarGeneros retorna a taxonomia de gêneros com a contagem de filmes
+func (fh *FilmeHandler) ListarGeneros(w http.ResponseWriter, r *http.Request) {
+	configurarCabecalhos(w)
+
+	ctx, cancelar := fh.contexto(r, "listar")
+	defer cancelar()
+
+	generos, err := fh.repositorio.ListarGeneros(ctx)
+	if err != nil {
+		responderErro(w, r, ctx, err, "Erro ao listar gêneros")
+		return
+	}
+
+	enviarJSON(w, models.RespostaGeneros{Generos: generos}, http.StatusOK)
+}
+
+// CriarGenero cadastra um gênero na taxonomia; restrito a administradores
+func (fh *FilmeHandler) CriarGenero(w http.ResponseWriter, r *http.Request) {
+	configurarCabecalhos(w)
+
+	var genero models.GeneroParaCriar
+	if !lerCorpoJSON(w, r, &genero, tamanhoMaximoCorpo) {
+		return
+	}
+
+	if erros := models.ValidarGenero(&genero); len(erros) > 0 {
+		enviarErroValidacao(w, r, erros)
+		return
+	}
+
+	ctx, cancelar := fh.contexto(r, "criar")
+	defer cancelar()
+
+	criado, err := fh.repositorio.CriarGenero(ctx, &genero)
+	if err != nil {
+		responderErro(w, r, ctx, err, "Erro ao criar gênero")
+		return
+	}
+
+	slog.InfoContext(ctx, "gênero criado", "genero_id", criado.ID, "slug", criado.Slug)
+	enviarJSON(w, models.RespostaSucesso{Mensagem: "Gênero criado com sucesso", Dados: criado}, http.StatusCreated)
+}
+
+// catalogoGeneros carrega a taxonomia usada para validar os gêneros de um
+// filme, sem a contagem de filmes de GET /generos
+func (fh *FilmeHandler) catalogoGeneros(ctx context.Context) (*models.CatalogoGeneros, error) {
+	generos, err := fh.repositorio.ListarGenerosCatalogo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return models.NovoCatalogoGeneros(generos), nil
+}
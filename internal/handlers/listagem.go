@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"api-filmes/internal/models"
+)
+
+// lerFiltroFilmes interpreta os parâmetros de query da listagem de filmes
+func lerFiltroFilmes(r *http.Request) (*models.FiltroFilmes, []string) {
+	parametros := r.URL.Query()
+	filtro := models.NovoFiltroFilmes()
+	var erros []string
+
+	filtro.Pagina, filtro.Limite = lerPaginaLimite(parametros, &erros)
+
+	if valor := parametros.Get("sort"); valor != "" {
+		campo := strings.TrimPrefix(valor, "-")
+		if !models.CampoOrdenacaoValido(campo) {
+			erros = append(erros, fmt.Sprintf("sort deve ser um dos campos: %s", strings.Join(models.CamposOrdenacao, ", ")))
+		} else {
+			filtro.Ordenacao = campo
+			filtro.Descendente = strings.HasPrefix(valor, "-")
+		}
+	}
+
+	if valor := parametros.Get("cursor"); valor != "" {
+		cursor, err := models.DecodificarCursor(valor)
+		if err != nil {
+			erros = append(erros, err.Error())
+		} else if cursor.Campo != filtro.Ordenacao || cursor.Descendente != filtro.Descendente {
+			erros = append(erros, "cursor não corresponde à ordenação solicitada")
+		} else {
+			filtro.Cursor = cursor
+		}
+	}
+
+	for _, valor := range strings.Split(parametros.Get("genero"), ",") {
+		if slug := models.Slug(valor); slug != "" {
+			filtro.Generos = append(filtro.Generos, slug)
+		}
+	}
+	switch parametros.Get("genero_modo") {
+	case "", "qualquer":
+	case "todos":
+		filtro.TodosGeneros = true
+	default:
+		erros = append(erros, "genero_modo deve ser qualquer ou todos")
+	}
+	filtro.Diretor = strings.TrimSpace(parametros.Get("diretor"))
+
+	filtro.AnoMinimo = lerInteiroOpcional(parametros, "ano_min", &erros)
+	filtro.AnoMaximo = lerInteiroOpcional(parametros, "ano_max", &erros)
+	filtro.AvaliacaoMinima = lerDecimalOpcional(parametros, "avaliacao_min", &erros)
+	filtro.AvaliacaoMaxima = lerDecimalOpcional(parametros, "avaliacao_max", &erros)
+
+	if filtro.AnoMinimo != nil && filtro.AnoMaximo != nil && *filtro.AnoMinimo > *filtro.AnoMaximo {
+		erros = append(erros, "ano_min não pode ser maior que ano_max")
+	}
+	if filtro.AvaliacaoMinima != nil && filtro.AvaliacaoMaxima != nil && *filtro.AvaliacaoMinima > *filtro.AvaliacaoMaxima {
+		erros = append(erros, "avaliacao_min não pode ser maior que avaliacao_max")
+	}
+
+	filtro.CalcularDeslocamento()
+	return filtro, erros
+}
+
+// lerPaginaLimite lê "pagina" e "limite", aplicando os padrões da listagem
+func lerPaginaLimite(parametros url.Values, erros *[]string) (pagina, limite int) {
+	pagina, limite = 1, models.LimitePadrao
+
+	if valor := parametros.Get("pagina"); valor != "" {
+		numero, err := strconv.Atoi(valor)
+		if err != nil || numero < 1 {
+			*erros = append(*erros, "pagina deve ser um número inteiro maior que 0")
+		} else {
+			pagina = numero
+		}
+	}
+
+	if valor := parametros.Get("limite"); valor != "" {
+		numero, err := strconv.Atoi(valor)
+		if err != nil || numero < 1 || numero > models.LimiteMaximo {
+			*erros = append(*erros, fmt.Sprintf("limite deve estar entre 1 e %d", models.LimiteMaximo))
+		} else {
+			limite = numero
+		}
+	}
+
+	return pagina, limite
+}
+
+// lerInteiroOpcional lê um parâmetro inteiro, registrando erro se malformado
+func lerInteiroOpcional(parametros url.Values, nome string, erros *[]string) *int {
+	valor := parametros.Get(nome)
+	if valor == "" {
+		return nil
+	}
+
+	numero, err := strconv.Atoi(valor)
+	if err != nil {
+		*erros = append(*erros, fmt.Sprintf("%s deve ser um número inteiro", nome))
+		return nil
+	}
+	return &numero
+}
+
+// lerDecimalOpcional lê um parâmetro decimal, registrando erro se malformado
+func lerDecimalOpcional(parametros url.Values, nome string, erros *[]string) *float64 {
+	valor := parametros.Get(nome)
+	if valor == "" {
+		return nil
+	}
+
+	numero, err := strconv.ParseFloat(valor, 64)
+	if err != nil {
+		*erros = append(*erros, fmt.Sprintf("%s deve ser um número", nome))
+		return nil
+	}
+	return &numero
+}
+
+// montarPaginacao calcula os metadados e links de navegação da listagem
+func montarPaginacao(r *http.Request, filtro *models.FiltroFilmes, filmes []models.FilmeResumo, total int, haMais bool) *models.Paginacao {
+	paginacao := &models.Paginacao{
+		Limite:       filtro.Limite,
+		TotalPaginas: (total + filtro.Limite - 1) / filtro.Limite,
+	}
+
+	if haMais && len(filmes) > 0 {
+		ultimo := filmes[len(filmes)-1]
+		paginacao.ProximoCursor = models.NovoCursor(ultimo, filtro.Ordenacao, filtro.Descendente).Codificar()
+	}
+
+	// Modo cursor: só existe navegação para frente
+	if filtro.Cursor != nil {
+		if paginacao.ProximoCursor != "" {
+			paginacao.LinkProxima = linkComParametros(r.URL, map[string]string{"cursor": paginacao.ProximoCursor, "pagina": ""})
+		}
+		return paginacao
+	}
+
+	paginacao.Pagina = filtro.Pagina
+	if haMais {
+		paginacao.LinkProxima = linkComParametros(r.URL, map[string]string{"pagina": strconv.Itoa(filtro.Pagina + 1)})
+	}
+	if filtro.Pagina > 1 {
+		paginacao.LinkAnterior = linkComParametros(r.URL, map[string]string{"pagina": strconv.Itoa(filtro.Pagina - 1)})
+	}
+
+	return paginacao
+}
+
+// linkComParametros reescreve a URL atual trocando (ou removendo, se vazio) parâmetros
+func linkComParametros(origem *url.URL, alteracoes map[string]string) string {
+	parametros := origem.Query()
+	for chave, valor := range alteracoes {
+		if valor == "" {
+			parametros.Del(chave)
+		} else {
+			parametros.Set(chave, valor)
+		}
+	}
+
+	link := url.URL{Path: origem.Path, RawQuery: parametros.Encode()}
+	return link.String()
+}
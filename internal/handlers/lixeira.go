@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"api-filmes/internal/models"
+)
+
+// ListarLixeira retorna os filmes excluídos em /filmes/lixeira?pagina=&limite=
+func (fh *FilmeHandler) ListarLixeira(w http.ResponseWriter, r *http.Request) {
+	configurarCabecalhos(w)
+
+	var erros []string
+	pagina, limite := lerPaginaLimite(r.URL.Query(), &erros)
+	if len(erros) > 0 {
+		enviarErro(w, r, "Parâmetros inválidos", http.StatusBadRequest, erros)
+		return
+	}
+
+	ctx, cancelar := fh.contexto(r, "listar")
+	defer cancelar()
+
+	// Buscar um item a mais para saber se existe próxima página
+	filmes, err := fh.repositorio.ListarLixeira(ctx, limite+1, (pagina-1)*limite)
+	if err != nil {
+		responderErro(w, r, ctx, err, "Erro ao listar lixeira")
+		return
+	}
+
+	haMais := len(filmes) > limite
+	if haMais {
+		filmes = filmes[:limite]
+	}
+
+	slog.InfoContext(ctx, "lixeira listada", "quantidade", len(filmes))
+	enviarJSON(w, models.RespostaLixeira{Filmes: filmes, Pagina: pagina, Limite: limite, HaMais: haMais}, http.StatusOK)
+}
+
+// RestaurarFilme devolve um filme da lixeira ao catálogo
+func (fh *FilmeHandler) RestaurarFilme(w http.ResponseWriter, r *http.Request) {
+	configurarCabecalhos(w)
+
+	id, ok := lerID(w, r)
+	if !ok {
+		return
+	}
+
+	ctx, cancelar := fh.contexto(r, "atualizar")
+	defer cancelar()
+
+	filme, err := fh.repositorio.RestaurarFilme(ctx, id)
+	if err != nil {
+		responderErro(w, r, ctx, err, "Erro ao restaurar filme")
+		return
+	}
+
+	slog.InfoContext(ctx, "filme restaurado", "filme_id", filme.ID, "titulo", filme.Titulo)
+
+	definirETag(w, filme)
+	enviarJSON(w, models.RespostaSucesso{Mensagem: "Filme restaurado com sucesso", Dados: filme}, http.StatusOK)
+}
+
+// ExpurgarFilme apaga definitivamente um filme da lixeira (somente admin)
+func (fh *FilmeHandler) ExpurgarFilme(w http.ResponseWriter, r *http.Request) {
+	configurarCabecalhos(w)
+
+	id, ok := lerID(w, r)
+	if !ok {
+		return
+	}
+
+	ctx, cancelar := fh.contexto(r, "deletar")
+	defer cancelar()
+
+	if err := fh.repositorio.ExpurgarFilme(ctx, id); err != nil {
+		responderErro(w, r, ctx, err, "Erro ao expurgar filme")
+		return
+	}
+
+	slog.WarnContext(ctx, "filme expurgado definitivamente", "filme_id", id)
+	enviarJSON(w, models.RespostaSucesso{Mensagem: "Filme removido definitivamente"}, http.StatusOK)
+}
+
+// EsvaziarLixeira apaga definitivamente todos os filmes da lixeira (somente admin)
+func (fh *FilmeHandler) EsvaziarLixeira(w http.ResponseWriter, r *http.Request) {
+	configurarCabecalhos(w)
+
+	ctx, cancelar := fh.contexto(r, "deletar")
+	defer cancelar()
+
+	removidos, err := fh.repositorio.ExpurgarLixeira(ctx, time.Now())
+	if err != nil {
+		responderErro(w, r, ctx, err, "Erro ao esvaziar lixeira")
+		return
+	}
+
+	slog.WarnContext(ctx, "lixeira esvaziada", "removidos", removidos)
+	enviarJSON(w, models.RespostaSucesso{
+		Mensagem: "Lixeira esvaziada",
+		Dados:    map[string]int{"removidos": removidos},
+	}, http.StatusOK)
+}
@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"api-filmes/internal/metricas"
+	"api-filmes/internal/requisicao"
+)
+
+// Métricas HTTP expostas em /metrics
+var (
+	requisicoesTotal = metricas.NovoContador(
+		"api_filmes_http_requisicoes_total",
+		"Total de requisições HTTP atendidas.",
+		"metodo", "rota", "status",
+	)
+	duracaoRequisicoes = metricas.NovoHistograma(
+		"api_filmes_http_requisicao_duracao_segundos",
+		"Duração das requisições HTTP em segundos.",
+		nil,
+		"metodo", "rota", "status",
+	)
+	requisicoesEmAndamento = metricas.NovoMedidor(
+		"api_filmes_http_requisicoes_em_andamento",
+		"Requisições HTTP sendo processadas no momento.",
+	)
+)
+
+// RotaDesconhecida agrupa caminhos que não correspondem a nenhuma rota,
+// evitando uma série nova para cada URL inválida
+const RotaDesconhecida = "desconhecida"
+
+// MetodoOutro agrupa métodos fora do padrão HTTP, que o cliente pode
+// inventar à vontade
+const MetodoOutro = "OTHER"
+
+// metodosConhecidos são os métodos registrados com o próprio nome
+var metodosConhecidos = map[string]bool{
+	http.MethodGet:     true,
+	http.MethodHead:    true,
+	http.MethodPost:    true,
+	http.MethodPut:     true,
+	http.MethodPatch:   true,
+	http.MethodDelete:  true,
+	http.MethodConnect: true,
+	http.MethodOptions: true,
+	http.MethodTrace:   true,
+}
+
+// MetricasMiddleware contabiliza requisições, latência e requisições em
+// andamento, rotuladas pelo template da rota (ex.: /filmes/{id})
+func MetricasMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		inicio := time.Now()
+		requisicoesEmAndamento.Adicionar(1)
+		defer requisicoesEmAndamento.Adicionar(-1)
+
+		wrapper := &ResponseWrapper{
+			ResponseWriter: w,
+			statusCode:     http.StatusOK,
+		}
+
+		next.ServeHTTP(wrapper, r)
+
+		metodo := metodoDaRequisicao(r)
+		rota := rotaDaRequisicao(r)
+		status := strconv.Itoa(wrapper.statusCode)
+		requisicoesTotal.Incrementar(metodo, rota, status)
+		duracaoRequisicoes.Observar(time.Since(inicio).Seconds(), metodo, rota, status)
+	})
+}
+
+// metodoDaRequisicao limita o rótulo aos métodos padrão
+func metodoDaRequisicao(r *http.Request) string {
+	if metodosConhecidos[r.Method] {
+		return r.Method
+	}
+	return MetodoOutro
+}
+
+// rotaDaRequisicao devolve o template definido pelo roteador (ex.: /filmes/{id})
+func rotaDaRequisicao(r *http.Request) string {
+	if rota := requisicao.Rota(r.Context()); rota != "" {
+		return rota
+	}
+	return RotaDesconhecida
+}
@@ -1,39 +1,111 @@
 package handlers
 
 import (
-	"fmt"
+	"log/slog"
 	"net/http"
 	"time"
+
+	"api-filmes/internal/autenticacao"
+	"api-filmes/internal/requisicao"
+	"api-filmes/internal/roteador"
 )
 
+// IDRequisicaoMiddleware propaga o X-Request-ID recebido (ou gera um novo),
+// devolve-o na resposta e o coloca no contexto para os logs
+func IDRequisicaoMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(requisicao.CabecalhoID)
+		if !requisicao.IDValido(id) {
+			id = requisicao.NovoID()
+		}
+
+		w.Header().Set(requisicao.CabecalhoID, id)
+		next.ServeHTTP(w, r.WithContext(requisicao.Novo(r.Context(), id)))
+	})
+}
+
+// AutenticacaoMiddleware verifica o token de acesso em Authorization: Bearer
+// e, se válido, coloca o usuário no contexto e o registra como autor na
+// auditoria. Sem token, ou com token inválido, a requisição segue anônima;
+// cabe a ExigirUsuarioMiddleware recusá-la nas rotas que pedem login.
+func AutenticacaoMiddleware(tokens *autenticacao.GerenciadorTokens) roteador.Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token, ok := lerTokenBearer(r)
+			if !ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			principal, err := tokens.Verificar(token)
+			if err != nil {
+				slog.DebugContext(r.Context(), "token de acesso recusado", "erro", err)
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			requisicao.DefinirAtor(r.Context(), principal.Ator())
+			next.ServeHTTP(w, r.WithContext(autenticacao.ComPrincipal(r.Context(), principal)))
+		})
+	}
+}
+
+// ExigirUsuarioMiddleware responde 401 quando AutenticacaoMiddleware não
+// identificou um usuário
+func ExigirUsuarioMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if autenticacao.PrincipalDe(r.Context()) != nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		configurarCabecalhos(w)
+		if _, ok := lerTokenBearer(r); ok {
+			w.Header().Set("WWW-Authenticate", TipoTokenBearer+` error="invalid_token"`)
+			enviarErro(w, r, "Não autenticado", http.StatusUnauthorized,
+				[]string{"Token de acesso inválido ou expirado; renove-o em /auth/renovar"})
+			return
+		}
+		enviarNaoAutenticado(w, r, "Informe o token de acesso no cabeçalho Authorization: Bearer")
+	})
+}
+
 // LogMiddleware registra informações sobre cada requisição
 func LogMiddleware(next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		inicio := time.Now()
 
-		// Criar wrapper para capturar status code
+		// Criar wrapper para capturar status code e bytes escritos
 		wrapper := &ResponseWrapper{
 			ResponseWriter: w,
 			statusCode:     http.StatusOK,
 		}
 
-		// Log da requisição
-		fmt.Printf("📥 %s %s - %s\n", r.Method, r.URL.Path, r.RemoteAddr)
-
 		// Executar handler
 		next(wrapper, r)
 
-		// Log da resposta
-		duracao := time.Since(inicio)
-		fmt.Printf("📤 %s %s - Status: %d - Duração: %v\n",
-			r.Method, r.URL.Path, wrapper.statusCode, duracao)
+		nivel := slog.LevelInfo
+		if wrapper.statusCode >= http.StatusInternalServerError {
+			nivel = slog.LevelError
+		}
+
+		slog.LogAttrs(r.Context(), nivel, "requisição atendida",
+			slog.String("metodo", r.Method),
+			slog.String("caminho", r.URL.Path),
+			slog.String("rota", rotaDaRequisicao(r)),
+			slog.Int("status", wrapper.statusCode),
+			slog.Int("bytes", wrapper.bytes),
+			slog.Float64("duracao_ms", float64(time.Since(inicio).Microseconds())/1000),
+			slog.String("remoto", r.RemoteAddr),
+		)
 	}
 }
 
-// ResponseWrapper captura o status code da resposta
+// ResponseWrapper captura o status code e o total de bytes da resposta
 type ResponseWrapper struct {
 	http.ResponseWriter
 	statusCode int
+	bytes      int
 }
 
 func (rw *ResponseWrapper) WriteHeader(code int) {
@@ -42,5 +114,7 @@ func (rw *ResponseWrapper) WriteHeader(code int) {
 }
 
 func (rw *ResponseWrapper) Write(b []byte) (int, error) {
-	return rw.ResponseWriter.Write(b)
+	n, err := rw.ResponseWriter.Write(b)
+	rw.bytes += n
+	return n, err
 }
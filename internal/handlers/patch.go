@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"slices"
+
+	"api-filmes/internal/models"
+	"api-filmes/internal/patch"
+)
+
+// tamanhoMaximoPatch limita o corpo de um PATCH
+const tamanhoMaximoPatch = 1 << 20
+
+// tiposPatch é anunciado no cabeçalho Accept-Patch (RFC 5789)
+const tiposPatch = patch.TipoMergePatch + ", " + patch.TipoJSONPatch
+
+// documentoFilme é a representação editável que recebe os patches: os
+// campos de models.FilmeParaCriar, mas sem omitempty, para que opcionais
+// vazios apareçam como null e "replace" e "test" do JSON Patch encontrem o
+// membro.
+type documentoFilme struct {
+	Titulo         string   `json:"titulo"`
+	Descricao      *string  `json:"descricao"`
+	AnoLancamento  int      `json:"ano_lancamento"`
+	DuracaoMinutos *int     `json:"duracao_minutos"`
+	Genero         *string  `json:"genero"`
+	Diretor        *string  `json:"diretor"`
+	Avaliacao      *float64 `json:"avaliacao"`
+	Diretores      []int    `json:"diretores"`
+	Generos        []string `json:"generos"`
+}
+
+// novoDocumentoFilme converte o filme salvo pela mesma regra de ParaCriar;
+// só a serialização difere
+func novoDocumentoFilme(filme *models.Filme) documentoFilme {
+	return documentoFilme(*filme.ParaCriar())
+}
+
+// AplicarPatchFilme aplica um JSON Merge Patch (RFC 7396) ou JSON Patch
+// (RFC 6902) sobre o filme e valida o resultado como em PUT
+func (fh *FilmeHandler) AplicarPatchFilme(w http.ResponseWriter, r *http.Request) {
+	configurarCabecalhos(w)
+	w.Header().Set("Accept-Patch", tiposPatch)
+
+	id, ok := lerID(w, r)
+	if !ok {
+		return
+	}
+
+	versoes, ok := versoesEsperadas(r)
+	if !ok {
+		enviarPrecondicaoFalhou(w, r)
+		return
+	}
+
+	tipo, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if tipo != patch.TipoMergePatch && tipo != patch.TipoJSONPatch {
+		enviarErro(w, r, "Tipo de conteúdo não suportado", http.StatusUnsupportedMediaType,
+			[]string{"Use Content-Type " + patch.TipoMergePatch + " ou " + patch.TipoJSONPatch})
+		return
+	}
+
+	corpo, err := io.ReadAll(http.MaxBytesReader(w, r.Body, tamanhoMaximoPatch))
+	if err != nil {
+		enviarErroLeituraCorpo(w, r, err)
+		return
+	}
+
+	ctx, cancelar := fh.contexto(r, "atualizar")
+	defer cancelar()
+
+	slog.DebugContext(ctx, "aplicando patch", "filme_id", id, "tipo", tipo)
+
+	atual, err := fh.repositorio.BuscarFilmePorID(ctx, id)
+	if err != nil {
+		responderErro(w, r, ctx, err, "Erro ao buscar filme")
+		return
+	}
+	if versoes != nil && !slices.Contains(versoes, atual.Versao) {
+		enviarPrecondicaoFalhou(w, r)
+		return
+	}
+
+	documento, err := json.Marshal(novoDocumentoFilme(atual))
+	if err != nil {
+		responderErro(w, r, ctx, err, "Erro ao preparar patch")
+		return
+	}
+
+	var resultado []byte
+	if tipo == patch.TipoMergePatch {
+		resultado, err = patch.AplicarMergePatch(documento, corpo)
+	} else {
+		resultado, err = patch.AplicarJSONPatch(documento, corpo)
+	}
+	if err != nil {
+		responderErroPatch(w, r, err)
+		return
+	}
+
+	// O documento resultante não pode ganhar campos que o filme não tem
+	var filme models.FilmeParaCriar
+	decodificador := json.NewDecoder(bytes.NewReader(resultado))
+	decodificador.DisallowUnknownFields()
+	if err := decodificador.Decode(&filme); err != nil {
+		enviarErro(w, r, "Patch produziu um filme inválido", http.StatusUnprocessableEntity, []string{err.Error()})
+		return
+	}
+
+	reconciliarDiretores(&filme, atual)
+	reconciliarGeneros(&filme, atual)
+
+	// O patch foi calculado sobre atual: a gravação exige que ele não tenha
+	// mudado nesse meio-tempo, mesmo sem If-Match
+	fh.gravarFilme(w, r, ctx, id, &filme, atual.Versao)
+}
+
+// reconciliarDiretores decide qual das duas formas do patch vale: IDs
+// alterados têm precedência; se só o texto legado "diretor" mudou, os IDs
+// antigos são descartados para que o texto seja interpretado de novo
+func reconciliarDiretores(filme *models.FilmeParaCriar, atual *models.Filme) {
+	if !slices.Equal(filme.Diretores, atual.IDsDiretores()) {
+		return
+	}
+	if filme.Diretor == nil || *filme.Diretor != atual.Diretor {
+		filme.Diretores = nil
+	}
+}
+
+// reconciliarGeneros aplica a mesma regra de reconciliarDiretores aos
+// gêneros: slugs alterados prevalecem sobre o texto legado "genero"
+func reconciliarGeneros(filme *models.FilmeParaCriar, atual *models.Filme) {
+	if !slices.Equal(filme.Generos, atual.SlugsGeneros()) {
+		return
+	}
+	if filme.Genero == nil || *filme.Genero != atual.Genero {
+		filme.Generos = nil
+	}
+}
+
+// responderErroPatch traduz falhas na aplicação do patch:
+// patch malformado é 400, "test" falho é 409 e caminho inexistente é 422
+func responderErroPatch(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, patch.ErrTesteFalhou):
+		escreverErro(w, r, "Pré-condição do patch não satisfeita", http.StatusConflict, CodigoTesteFalhou, []string{err.Error()}, nil)
+	case errors.Is(err, patch.ErrCaminhoInexistente):
+		escreverErro(w, r, "Patch não pode ser aplicado", http.StatusUnprocessableEntity, CodigoPatchInaplicavel, []string{err.Error()}, nil)
+	default:
+		escreverErro(w, r, "Patch inválido", http.StatusBadRequest, CodigoRequisicaoInvalida, []string{err.Error()}, nil)
+	}
+}
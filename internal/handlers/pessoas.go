@@ -0,0 +1,259 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"api-filmes/internal/config"
+	"api-filmes/internal/database"
+	"api-filmes/internal/models"
+	"api-filmes/internal/roteador"
+)
+
+// PessoaHandler contém as dependências para os handlers de pessoas
+// (diretores, elenco e equipe)
+type PessoaHandler struct {
+	repositorio database.Repositorio
+	timeouts    *config.ConfiguracaoTimeouts
+}
+
+// NovoPessoaHandler cria uma nova instância do handler
+func NovoPessoaHandler(repositorio database.Repositorio, timeouts *config.ConfiguracaoTimeouts) *PessoaHandler {
+	return &PessoaHandler{repositorio: repositorio, timeouts: timeouts}
+}
+
+// contexto aplica o mesmo timeout por rota usado pelos handlers de filme
+func (ph *PessoaHandler) contexto(r *http.Request, rota string) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(r.Context(), ph.timeouts.Timeout(rota))
+}
+
+// RegistrarRotas associa as consultas de pessoas, abertas a qualquer cliente.
+// /diretores e /pessoas compartilham o mesmo cadastro, mas a listagem de
+// /diretores só traz quem dirige algum filme.
+func (ph *PessoaHandler) RegistrarRotas(rt roteador.Rotas) {
+	rt.Get("/pessoas", ph.ListarPessoas)
+	rt.Get("/diretores", ph.ListarDiretores)
+	for _, prefixo := range []string{"/pessoas", "/diretores"} {
+		rt.Get(prefixo+"/{id}", ph.BuscarPessoa)
+	}
+	rt.Get("/diretores/{id}/filmes", ph.FilmesDoDiretor)
+	rt.Get("/pessoas/{id}/filmografia", ph.Filmografia)
+}
+
+// RegistrarRotasEscrita associa o cadastro de pessoas; o grupo recebido
+// deve exigir usuário autenticado
+func (ph *PessoaHandler) RegistrarRotasEscrita(rt roteador.Rotas) {
+	for _, prefixo := range []string{"/pessoas", "/diretores"} {
+		rt.Post(prefixo, ph.CriarPessoa)
+		rt.Put(prefixo+"/{id}", ph.AtualizarPessoa)
+		rt.Delete(prefixo+"/{id}", ph.DeletarPessoa)
+	}
+}
+
+// ListarPessoas retorna uma página de pessoas em /pessoas?nome=&pagina=&limite=
+func (ph *PessoaHandler) ListarPessoas(w http.ResponseWriter, r *http.Request) {
+	ph.listar(w, r, false)
+}
+
+// ListarDiretores retorna uma página das pessoas que dirigem algum filme ativo,
+// com os mesmos parâmetros de /pessoas
+func (ph *PessoaHandler) ListarDiretores(w http.ResponseWriter, r *http.Request) {
+	ph.listar(w, r, true)
+}
+
+// listar atende /pessoas e /diretores
+func (ph *PessoaHandler) listar(w http.ResponseWriter, r *http.Request, apenasDiretores bool) {
+	configurarCabecalhos(w)
+
+	var erros []string
+	parametros := r.URL.Query()
+	pagina, limite := lerPaginaLimite(parametros, &erros)
+	if len(erros) > 0 {
+		enviarErro(w, r, "Parâmetros inválidos", http.StatusBadRequest, erros)
+		return
+	}
+
+	ctx, cancelar := ph.contexto(r, "listar")
+	defer cancelar()
+
+	// Buscar um item a mais para saber se existe próxima página
+	pessoas, err := ph.repositorio.ListarPessoas(ctx, &models.FiltroPessoas{
+		Nome:            strings.TrimSpace(parametros.Get("nome")),
+		ApenasDiretores: apenasDiretores,
+		Limite:          limite + 1,
+		Deslocamento:    (pagina - 1) * limite,
+	})
+	if err != nil {
+		responderErro(w, r, ctx, err, "Erro ao listar pessoas")
+		return
+	}
+
+	haMais := len(pessoas) > limite
+	if haMais {
+		pessoas = pessoas[:limite]
+	}
+
+	enviarJSON(w, models.RespostaPessoas{Pessoas: pessoas, Pagina: pagina, Limite: limite, HaMais: haMais}, http.StatusOK)
+}
+
+// BuscarPessoa retorna uma pessoa pelo ID
+func (ph *PessoaHandler) BuscarPessoa(w http.ResponseWriter, r *http.Request) {
+	configurarCabecalhos(w)
+
+	id, ok := lerID(w, r)
+	if !ok {
+		return
+	}
+
+	ctx, cancelar := ph.contexto(r, "buscar")
+	defer cancelar()
+
+	pessoa, err := ph.repositorio.BuscarPessoaPorID(ctx, id)
+	if err != nil {
+		responderErro(w, r, ctx, err, "Erro ao buscar pessoa")
+		return
+	}
+
+	enviarJSON(w, pessoa, http.StatusOK)
+}
+
+// CriarPessoa cadastra uma pessoa; nomes repetidos resultam em 409
+func (ph *PessoaHandler) CriarPessoa(w http.ResponseWriter, r *http.Request) {
+	configurarCabecalhos(w)
+
+	pessoa, ok := lerPessoa(w, r)
+	if !ok {
+		return
+	}
+
+	ctx, cancelar := ph.contexto(r, "criar")
+	defer cancelar()
+
+	criada, err := ph.repositorio.CriarPessoa(ctx, pessoa)
+	if err != nil {
+		responderErro(w, r, ctx, err, "Erro ao criar pessoa")
+		return
+	}
+
+	slog.InfoContext(ctx, "pessoa criada", "pessoa_id", criada.ID, "nome", criada.Nome)
+	enviarJSON(w, models.RespostaSucesso{Mensagem: "Pessoa criada com sucesso", Dados: criada}, http.StatusCreated)
+}
+
+// AtualizarPessoa renomeia uma pessoa; os filmes que ela dirige são
+// atualizados com o novo nome
+func (ph *PessoaHandler) AtualizarPessoa(w http.ResponseWriter, r *http.Request) {
+	configurarCabecalhos(w)
+
+	id, ok := lerID(w, r)
+	if !ok {
+		return
+	}
+
+	pessoa, ok := lerPessoa(w, r)
+	if !ok {
+		return
+	}
+
+	ctx, cancelar := ph.contexto(r, "atualizar")
+	defer cancelar()
+
+	atualizada, err := ph.repositorio.AtualizarPessoa(ctx, id, pessoa)
+	if err != nil {
+		responderErro(w, r, ctx, err, "Erro ao atualizar pessoa")
+		return
+	}
+
+	slog.InfoContext(ctx, "pessoa atualizada", "pessoa_id", atualizada.ID, "nome", atualizada.Nome)
+	enviarJSON(w, models.RespostaSucesso{Mensagem: "Pessoa atualizada com sucesso", Dados: atualizada}, http.StatusOK)
+}
+
+// DeletarPessoa remove uma pessoa sem filmes dirigidos nem créditos
+func (ph *PessoaHandler) DeletarPessoa(w http.ResponseWriter, r *http.Request) {
+	configurarCabecalhos(w)
+
+	id, ok := lerID(w, r)
+	if !ok {
+		return
+	}
+
+	ctx, cancelar := ph.contexto(r, "deletar")
+	defer cancelar()
+
+	if err := ph.repositorio.DeletarPessoa(ctx, id); err != nil {
+		responderErro(w, r, ctx, err, "Erro ao deletar pessoa")
+		return
+	}
+
+	slog.InfoContext(ctx, "pessoa deletada", "pessoa_id", id)
+	enviarJSON(w, models.RespostaSucesso{Mensagem: "Pessoa deletada com sucesso"}, http.StatusOK)
+}
+
+// FilmesDoDiretor lista os filmes dirigidos pela pessoa
+func (ph *PessoaHandler) FilmesDoDiretor(w http.ResponseWriter, r *http.Request) {
+	configurarCabecalhos(w)
+
+	id, ok := lerID(w, r)
+	if !ok {
+		return
+	}
+
+	ctx, cancelar := ph.contexto(r, "listar")
+	defer cancelar()
+
+	pessoa, err := ph.repositorio.BuscarPessoaPorID(ctx, id)
+	if err != nil {
+		responderErro(w, r, ctx, err, "Erro ao buscar pessoa")
+		return
+	}
+
+	filmes, err := ph.repositorio.FilmesDoDiretor(ctx, id)
+	if err != nil {
+		responderErro(w, r, ctx, err, "Erro ao listar filmes do diretor")
+		return
+	}
+
+	enviarJSON(w, models.RespostaFilmesPessoa{Pessoa: *pessoa, Filmes: filmes}, http.StatusOK)
+}
+
+// Filmografia lista as participações da pessoa, inclusive como diretora
+func (ph *PessoaHandler) Filmografia(w http.ResponseWriter, r *http.Request) {
+	configurarCabecalhos(w)
+
+	id, ok := lerID(w, r)
+	if !ok {
+		return
+	}
+
+	ctx, cancelar := ph.contexto(r, "listar")
+	defer cancelar()
+
+	pessoa, err := ph.repositorio.BuscarPessoaPorID(ctx, id)
+	if err != nil {
+		responderErro(w, r, ctx, err, "Erro ao buscar pessoa")
+		return
+	}
+
+	filmografia, err := ph.repositorio.Filmografia(ctx, id)
+	if err != nil {
+		responderErro(w, r, ctx, err, "Erro ao buscar filmografia")
+		return
+	}
+
+	enviarJSON(w, models.RespostaFilmografia{Pessoa: *pessoa, Filmografia: filmografia}, http.StatusOK)
+}
+
+// lerPessoa decodifica e valida o corpo de criação/substituição
+func lerPessoa(w http.ResponseWriter, r *http.Request) (*models.PessoaParaCriar, bool) {
+	var pessoa models.PessoaParaCriar
+	if !lerCorpoJSON(w, r, &pessoa, tamanhoMaximoCorpo) {
+		return nil, false
+	}
+
+	if erros := models.ValidarPessoa(&pessoa); len(erros) > 0 {
+		enviarErroValidacao(w, r, erros)
+		return nil, false
+	}
+	return &pessoa, true
+}
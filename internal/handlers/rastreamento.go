@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"net/http"
+
+	"api-filmes/internal/rastreamento"
+)
+
+// RastreamentoMiddleware abre um span de servidor por requisição,
+// continuando o trace recebido no cabeçalho traceparent (W3C)
+func RastreamentoMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		if remoto, ok := rastreamento.ExtrairTraceparent(r.Header.Get(rastreamento.CabecalhoTraceparent)); ok {
+			ctx = rastreamento.ComContextoRemoto(ctx, remoto)
+		}
+
+		ctx, span := rastreamento.Iniciar(ctx, r.Method, rastreamento.TipoServidor,
+			rastreamento.Atributo{Chave: "http.request.method", Valor: r.Method},
+			rastreamento.Atributo{Chave: "url.path", Valor: r.URL.Path},
+		)
+		defer span.Finalizar()
+
+		wrapper := &ResponseWrapper{
+			ResponseWriter: w,
+			statusCode:     http.StatusOK,
+		}
+
+		next.ServeHTTP(wrapper, r.WithContext(ctx))
+
+		// O template só é conhecido depois que o roteador escolhe a rota
+		rota := rotaDaRequisicao(r)
+		span.DefinirNome(r.Method + " " + rota)
+		span.DefinirAtributo("http.route", rota)
+		span.DefinirAtributo("http.response.status_code", wrapper.statusCode)
+		if wrapper.statusCode >= http.StatusInternalServerError {
+			span.DefinirStatus(rastreamento.StatusErro, http.StatusText(wrapper.statusCode))
+		}
+	})
+}
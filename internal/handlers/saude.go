@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"api-filmes/internal/database"
+	"api-filmes/internal/models"
+	"api-filmes/internal/versao"
+)
+
+// VerificadorMigracoes informa quantas migrações ainda não foram aplicadas
+type VerificadorMigracoes interface {
+	Pendentes(ctx context.Context) (int, error)
+}
+
+// Mensagens públicas das sondas; o erro original, que pode expor host,
+// usuário ou detalhes do driver, vai apenas para o log
+const (
+	erroSondaBanco     = "banco de dados inacessível"
+	erroSondaMigracoes = "não foi possível verificar as migrações"
+)
+
+// SaudeHandler atende as sondas de liveness e readiness
+type SaudeHandler struct {
+	repositorio database.FilmeRepositorio
+	migracoes   VerificadorMigracoes
+	timeout     time.Duration
+}
+
+// NovoSaudeHandler cria o handler de saúde. migracoes pode ser nil quando
+// não há banco (repositório em memória).
+func NovoSaudeHandler(repositorio database.FilmeRepositorio, migracoes VerificadorMigracoes, timeout time.Duration) *SaudeHandler {
+	return &SaudeHandler{repositorio: repositorio, migracoes: migracoes, timeout: timeout}
+}
+
+// Vivo responde 200 enquanto o processo estiver atendendo requisições
+func (sh *SaudeHandler) Vivo(w http.ResponseWriter, r *http.Request) {
+	configurarCabecalhos(w)
+	enviarJSON(w, novaRespostaSaude(models.StatusSaudavel), http.StatusOK)
+}
+
+// Pronto verifica as dependências e responde 503 se alguma estiver indisponível
+func (sh *SaudeHandler) Pronto(w http.ResponseWriter, r *http.Request) {
+	configurarCabecalhos(w)
+
+	ctx, cancelar := context.WithTimeout(r.Context(), sh.timeout)
+	defer cancelar()
+
+	resposta := novaRespostaSaude(models.StatusSaudavel)
+	resposta.Componentes = map[string]models.StatusComponente{}
+
+	if verificavel, ok := sh.repositorio.(database.Verificavel); ok {
+		resposta.Componentes["banco"] = verificarBanco(ctx, verificavel)
+	} else {
+		resposta.Componentes["repositorio"] = models.StatusComponente{Status: models.StatusSaudavel}
+	}
+
+	if sh.migracoes != nil {
+		resposta.Componentes["migracoes"] = verificarMigracoes(ctx, sh.migracoes)
+	}
+
+	status := http.StatusOK
+	for _, componente := range resposta.Componentes {
+		if componente.Status != models.StatusSaudavel {
+			resposta.Status = models.StatusIndisponivel
+			status = http.StatusServiceUnavailable
+		}
+	}
+
+	enviarJSON(w, resposta, status)
+}
+
+// verificarBanco faz um ping com prazo e anexa as estatísticas do pool
+func verificarBanco(ctx context.Context, banco database.Verificavel) models.StatusComponente {
+	inicio := time.Now()
+	err := banco.Ping(ctx)
+	latencia := time.Since(inicio)
+
+	estatisticas := banco.Estatisticas()
+	componente := models.StatusComponente{
+		Status:     models.StatusSaudavel,
+		LatenciaMs: float64(latencia.Microseconds()) / 1000,
+		Pool: &models.EstatisticasPool{
+			MaximoConexoes:  estatisticas.MaxOpenConnections,
+			ConexoesAbertas: estatisticas.OpenConnections,
+			EmUso:           estatisticas.InUse,
+			Ociosas:         estatisticas.Idle,
+			Esperas:         estatisticas.WaitCount,
+			TempoEsperaMs:   float64(estatisticas.WaitDuration.Microseconds()) / 1000,
+		},
+	}
+
+	if err != nil {
+		slog.ErrorContext(ctx, "sonda de prontidão: banco indisponível", "erro", err)
+		componente.Status = models.StatusIndisponivel
+		componente.Erro = erroSondaBanco
+	}
+	return componente
+}
+
+// verificarMigracoes considera o serviço indisponível se houver migrações pendentes
+func verificarMigracoes(ctx context.Context, migracoes VerificadorMigracoes) models.StatusComponente {
+	pendentes, err := migracoes.Pendentes(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "sonda de prontidão: falha ao consultar migrações", "erro", err)
+		return models.StatusComponente{Status: models.StatusIndisponivel, Erro: erroSondaMigracoes}
+	}
+
+	componente := models.StatusComponente{Status: models.StatusSaudavel, Pendentes: &pendentes}
+	if pendentes > 0 {
+		componente.Status = models.StatusIndisponivel
+	}
+	return componente
+}
+
+func novaRespostaSaude(status string) models.RespostaSaude {
+	return models.RespostaSaude{
+		Status:    status,
+		Timestamp: time.Now().Format(time.RFC3339),
+		Servico:   "API de Filmes",
+		Build:     versao.Obter(),
+	}
+}
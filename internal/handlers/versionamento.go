@@ -0,0 +1,26 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"api-filmes/internal/roteador"
+)
+
+// VersaoAtual é o prefixo da versão estável da API
+const VersaoAtual = "/v1"
+
+// DepreciacaoMiddleware marca as rotas legadas (sem prefixo de versão) como
+// depreciadas: Deprecation (RFC 9745), Sunset (RFC 8594) e um Link para o
+// equivalente versionado
+func DepreciacaoMiddleware(depreciadoEm, sunset time.Time, prefixoSucessor string) roteador.Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Deprecation", fmt.Sprintf("@%d", depreciadoEm.Unix()))
+			w.Header().Set("Sunset", sunset.UTC().Format(http.TimeFormat))
+			w.Header().Add("Link", fmt.Sprintf(`<%s%s>; rel="successor-version"`, prefixoSucessor, r.URL.Path))
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,180 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"api-filmes/internal/models"
+)
+
+// lerNumeroVersao extrai o parâmetro {n} da rota; responde 400 se inválido
+func lerNumeroVersao(w http.ResponseWriter, r *http.Request) (int, bool) {
+	numero, err := strconv.Atoi(r.PathValue("n"))
+	if err != nil || numero < 1 {
+		enviarErro(w, r, "Versão inválida", http.StatusBadRequest, []string{"Versão deve ser um número inteiro positivo"})
+		return 0, false
+	}
+	return numero, true
+}
+
+// semAutoria apaga quem fez a revisão: as rotas de versões são públicas, e
+// ator e ID de requisição ficam restritos ao histórico e à auditoria
+func semAutoria(versao *models.VersaoFilme) {
+	versao.Ator = ""
+	versao.IDRequisicao = ""
+}
+
+// ListarVersoes resume as revisões de um filme, da mais recente à mais antiga
+func (fh *FilmeHandler) ListarVersoes(w http.ResponseWriter, r *http.Request) {
+	configurarCabecalhos(w)
+
+	id, ok := lerID(w, r)
+	if !ok {
+		return
+	}
+
+	ctx, cancelar := fh.contexto(r, "buscar")
+	defer cancelar()
+
+	versoes, err := fh.repositorio.ListarVersoesFilme(ctx, id)
+	if err != nil {
+		responderErro(w, r, ctx, err, "Erro ao listar versões")
+		return
+	}
+	for i := range versoes {
+		semAutoria(&versoes[i])
+	}
+
+	enviarJSON(w, models.RespostaVersoes{FilmeID: id, Versoes: versoes}, http.StatusOK)
+}
+
+// BuscarVersao retorna o snapshot completo de uma revisão
+func (fh *FilmeHandler) BuscarVersao(w http.ResponseWriter, r *http.Request) {
+	configurarCabecalhos(w)
+
+	id, ok := lerID(w, r)
+	if !ok {
+		return
+	}
+	numero, ok := lerNumeroVersao(w, r)
+	if !ok {
+		return
+	}
+
+	ctx, cancelar := fh.contexto(r, "buscar")
+	defer cancelar()
+
+	versao, err := fh.repositorio.BuscarVersaoFilme(ctx, id, numero)
+	if err != nil {
+		responderErro(w, r, ctx, err, "Erro ao buscar versão")
+		return
+	}
+	semAutoria(versao)
+
+	enviarJSON(w, versao, http.StatusOK)
+}
+
+// DiferencaVersoes compara duas revisões campo a campo em
+// /filmes/{id}/versoes/diferenca?de=1&para=3
+func (fh *FilmeHandler) DiferencaVersoes(w http.ResponseWriter, r *http.Request) {
+	configurarCabecalhos(w)
+
+	id, ok := lerID(w, r)
+	if !ok {
+		return
+	}
+
+	var erros []string
+	de := lerInteiroOpcional(r.URL.Query(), "de", &erros)
+	para := lerInteiroOpcional(r.URL.Query(), "para", &erros)
+	if len(erros) == 0 && (de == nil || para == nil || *de < 1 || *para < 1) {
+		erros = append(erros, "de e para são obrigatórios e devem ser versões positivas")
+	}
+	if len(erros) > 0 {
+		enviarErro(w, r, "Parâmetros inválidos", http.StatusBadRequest, erros)
+		return
+	}
+
+	ctx, cancelar := fh.contexto(r, "buscar")
+	defer cancelar()
+
+	origem, err := fh.repositorio.BuscarVersaoFilme(ctx, id, *de)
+	if err != nil {
+		responderErro(w, r, ctx, err, "Erro ao buscar versão")
+		return
+	}
+	destino, err := fh.repositorio.BuscarVersaoFilme(ctx, id, *para)
+	if err != nil {
+		responderErro(w, r, ctx, err, "Erro ao buscar versão")
+		return
+	}
+
+	enviarJSON(w, models.DiferencaVersoes{
+		FilmeID:    id,
+		De:         *de,
+		Para:       *para,
+		Alteracoes: models.DiferencaFilmes(origem.Filme, destino.Filme),
+	}, http.StatusOK)
+}
+
+// ReverterFilme restaura o conteúdo de uma revisão antiga como nova revisão.
+// Aceita If-Match para não descartar alterações feitas nesse meio-tempo.
+func (fh *FilmeHandler) ReverterFilme(w http.ResponseWriter, r *http.Request) {
+	configurarCabecalhos(w)
+
+	id, ok := lerID(w, r)
+	if !ok {
+		return
+	}
+	numero, ok := lerNumeroVersao(w, r)
+	if !ok {
+		return
+	}
+	versoes, ok := versoesEsperadas(r)
+	if !ok {
+		enviarPrecondicaoFalhou(w, r)
+		return
+	}
+
+	ctx, cancelar := fh.contexto(r, "atualizar")
+	defer cancelar()
+
+	versao, ok, err := fh.versaoCorrespondente(ctx, id, versoes)
+	if err != nil {
+		responderErro(w, r, ctx, err, "Erro ao buscar filme")
+		return
+	}
+	if !ok {
+		enviarPrecondicaoFalhou(w, r)
+		return
+	}
+
+	// A revisão antiga passa pelas mesmas regras de PUT e PATCH: pode ter
+	// sido gravada antes de uma regra nova ou referenciar um gênero removido
+	alvo, err := fh.repositorio.BuscarVersaoFilme(ctx, id, numero)
+	if err != nil {
+		responderErro(w, r, ctx, err, "Erro ao buscar versão")
+		return
+	}
+	catalogo, err := fh.catalogoGeneros(ctx)
+	if err != nil {
+		responderErro(w, r, ctx, err, "Erro ao carregar gêneros")
+		return
+	}
+	if erros := models.ValidarFilme(alvo.Filme.ParaCriar(), catalogo); len(erros) > 0 {
+		enviarErroValidacao(w, r, erros)
+		return
+	}
+
+	filme, err := fh.repositorio.ReverterFilme(ctx, id, numero, versao)
+	if err != nil {
+		responderErro(w, r, ctx, err, "Erro ao reverter filme")
+		return
+	}
+
+	slog.InfoContext(ctx, "filme revertido", "filme_id", filme.ID, "versao_origem", numero, "versao", filme.Versao)
+
+	definirETag(w, filme)
+	enviarJSON(w, models.RespostaSucesso{Mensagem: "Filme revertido para a versão " + strconv.Itoa(numero), Dados: filme}, http.StatusOK)
+}
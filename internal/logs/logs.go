@@ -0,0 +1,56 @@
+// Package logs configura o log/slog da aplicação e acrescenta a cada
+// registro os dados da requisição presentes no contexto.
+package logs
+
+import (
+	"context"
+	"io"
+	"log/slog"
+
+	"api-filmes/internal/config"
+	"api-filmes/internal/rastreamento"
+	"api-filmes/internal/requisicao"
+)
+
+// Configurar cria o logger conforme a configuração e o define como padrão
+func Configurar(cfg *config.ConfiguracaoLog, saida io.Writer) *slog.Logger {
+	opcoes := &slog.HandlerOptions{Level: cfg.Nivel}
+
+	var handler slog.Handler
+	if cfg.Formato == config.LogFormatoTexto {
+		handler = slog.NewTextHandler(saida, opcoes)
+	} else {
+		handler = slog.NewJSONHandler(saida, opcoes)
+	}
+
+	logger := slog.New(&handlerContexto{Handler: handler})
+	slog.SetDefault(logger)
+	return logger
+}
+
+// handlerContexto inclui request_id, trace_id e span_id em todo registro
+// feito com as funções *Context
+type handlerContexto struct {
+	slog.Handler
+}
+
+func (h *handlerContexto) Handle(ctx context.Context, registro slog.Record) error {
+	if id := requisicao.ID(ctx); id != "" {
+		registro.AddAttrs(slog.String("request_id", id))
+	}
+	if span := rastreamento.SpanDoContexto(ctx); span != nil {
+		registro.AddAttrs(
+			slog.String("trace_id", span.Contexto.TraceID.String()),
+			slog.String("span_id", span.Contexto.SpanID.String()),
+		)
+	}
+	return h.Handler.Handle(ctx, registro)
+}
+
+func (h *handlerContexto) WithAttrs(atributos []slog.Attr) slog.Handler {
+	return &handlerContexto{Handler: h.Handler.WithAttrs(atributos)}
+}
+
+func (h *handlerContexto) WithGroup(nome string) slog.Handler {
+	return &handlerContexto{Handler: h.Handler.WithGroup(nome)}
+}
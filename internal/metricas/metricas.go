@@ -0,0 +1,354 @@
+// Package metricas implementa contadores, medidores e histogramas expostos
+// no formato de texto do Prometheus (versão 0.0.4), sem dependências externas.
+package metricas
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// BucketsPadrao cobre latências de 5ms a 10s, como o cliente oficial
+var BucketsPadrao = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// metrica é qualquer coisa que saiba se escrever no formato de exposição
+type metrica interface {
+	nome() string
+	escrever(w io.Writer)
+}
+
+// Registro agrupa as métricas expostas em /metrics
+type Registro struct {
+	mu       sync.RWMutex
+	metricas map[string]metrica
+}
+
+// Padrao é o registro usado pelos construtores Novo*
+var Padrao = NovoRegistro()
+
+// NovoRegistro cria um registro vazio
+func NovoRegistro() *Registro {
+	return &Registro{metricas: map[string]metrica{}}
+}
+
+// registrar adiciona a métrica; nomes duplicados indicam erro de programação
+func (r *Registro) registrar(m metrica) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, existe := r.metricas[m.nome()]; existe {
+		panic(fmt.Sprintf("métrica %q registrada duas vezes", m.nome()))
+	}
+	r.metricas[m.nome()] = m
+}
+
+// Escrever serializa todas as métricas em ordem alfabética
+func (r *Registro) Escrever(w io.Writer) {
+	r.mu.RLock()
+	nomes := make([]string, 0, len(r.metricas))
+	for nome := range r.metricas {
+		nomes = append(nomes, nome)
+	}
+	sort.Strings(nomes)
+	metricas := make([]metrica, 0, len(nomes))
+	for _, nome := range nomes {
+		metricas = append(metricas, r.metricas[nome])
+	}
+	r.mu.RUnlock()
+
+	for _, m := range metricas {
+		m.escrever(w)
+	}
+}
+
+// Handler expõe o registro no formato de texto do Prometheus
+func (r *Registro) Handler() http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		r.Escrever(w)
+	}
+}
+
+// familia guarda nome, ajuda e rótulos comuns a todos os tipos
+type familia struct {
+	nomeMetrica string
+	ajuda       string
+	tipo        string
+	rotulos     []string
+}
+
+func (f *familia) nome() string { return f.nomeMetrica }
+
+func (f *familia) escreverCabecalho(w io.Writer) {
+	fmt.Fprintf(w, "# HELP %s %s\n", f.nomeMetrica, escaparAjuda(f.ajuda))
+	fmt.Fprintf(w, "# TYPE %s %s\n", f.nomeMetrica, f.tipo)
+}
+
+// chave junta os valores dos rótulos para indexar as séries
+func (f *familia) chave(valores []string) string {
+	if len(valores) != len(f.rotulos) {
+		panic(fmt.Sprintf("métrica %q espera %d rótulos, recebeu %d", f.nomeMetrica, len(f.rotulos), len(valores)))
+	}
+	return strings.Join(valores, "\xff")
+}
+
+// formatarRotulos gera {a="1",b="2"}, incluindo rótulos extras (ex.: le)
+func (f *familia) formatarRotulos(valores []string, extras ...string) string {
+	if len(f.rotulos) == 0 && len(extras) == 0 {
+		return ""
+	}
+
+	partes := make([]string, 0, len(f.rotulos)+len(extras)/2)
+	for i, rotulo := range f.rotulos {
+		partes = append(partes, fmt.Sprintf(`%s="%s"`, rotulo, escaparValor(valores[i])))
+	}
+	for i := 0; i+1 < len(extras); i += 2 {
+		partes = append(partes, fmt.Sprintf(`%s="%s"`, extras[i], escaparValor(extras[i+1])))
+	}
+	return "{" + strings.Join(partes, ",") + "}"
+}
+
+// serie é um valor com os rótulos que o identificam
+type serie struct {
+	rotulos []string
+	valor   float64
+}
+
+// Contador é uma métrica que só cresce (ex.: total de requisições)
+type Contador struct {
+	familia
+	mu     sync.Mutex
+	series map[string]*serie
+}
+
+// NovoContador cria e registra um contador no registro padrão
+func NovoContador(nome, ajuda string, rotulos ...string) *Contador {
+	c := &Contador{
+		familia: familia{nomeMetrica: nome, ajuda: ajuda, tipo: "counter", rotulos: rotulos},
+		series:  map[string]*serie{},
+	}
+	Padrao.registrar(c)
+	return c
+}
+
+// Incrementar soma 1 à série identificada pelos valores dos rótulos
+func (c *Contador) Incrementar(valores ...string) {
+	c.Adicionar(1, valores...)
+}
+
+// Adicionar soma delta (>= 0) à série
+func (c *Contador) Adicionar(delta float64, valores ...string) {
+	if delta < 0 {
+		panic("contadores não podem diminuir")
+	}
+
+	chave := c.chave(valores)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	s, ok := c.series[chave]
+	if !ok {
+		s = &serie{rotulos: append([]string(nil), valores...)}
+		c.series[chave] = s
+	}
+	s.valor += delta
+}
+
+func (c *Contador) escrever(w io.Writer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.escreverCabecalho(w)
+	for _, chave := range chavesOrdenadas(c.series) {
+		s := c.series[chave]
+		fmt.Fprintf(w, "%s%s %s\n", c.nomeMetrica, c.formatarRotulos(s.rotulos), formatarNumero(s.valor))
+	}
+}
+
+// Medidor é um valor que sobe e desce (ex.: requisições em andamento)
+type Medidor struct {
+	familia
+	mu     sync.Mutex
+	series map[string]*serie
+}
+
+// NovoMedidor cria e registra um medidor no registro padrão
+func NovoMedidor(nome, ajuda string, rotulos ...string) *Medidor {
+	m := &Medidor{
+		familia: familia{nomeMetrica: nome, ajuda: ajuda, tipo: "gauge", rotulos: rotulos},
+		series:  map[string]*serie{},
+	}
+	Padrao.registrar(m)
+	return m
+}
+
+// Adicionar soma delta (positivo ou negativo) à série
+func (m *Medidor) Adicionar(delta float64, valores ...string) {
+	chave := m.chave(valores)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s, ok := m.series[chave]
+	if !ok {
+		s = &serie{rotulos: append([]string(nil), valores...)}
+		m.series[chave] = s
+	}
+	s.valor += delta
+}
+
+// Definir substitui o valor da série
+func (m *Medidor) Definir(valor float64, valores ...string) {
+	chave := m.chave(valores)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.series[chave] = &serie{rotulos: append([]string(nil), valores...), valor: valor}
+}
+
+func (m *Medidor) escrever(w io.Writer) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.escreverCabecalho(w)
+	for _, chave := range chavesOrdenadas(m.series) {
+		s := m.series[chave]
+		fmt.Fprintf(w, "%s%s %s\n", m.nomeMetrica, m.formatarRotulos(s.rotulos), formatarNumero(s.valor))
+	}
+}
+
+// MedidorFuncao lê o valor no momento da coleta (ex.: estatísticas do pool)
+type MedidorFuncao struct {
+	familia
+	funcao func() float64
+}
+
+// NovoMedidorFuncao cria e registra um medidor calculado na coleta
+func NovoMedidorFuncao(nome, ajuda string, funcao func() float64) *MedidorFuncao {
+	m := &MedidorFuncao{
+		familia: familia{nomeMetrica: nome, ajuda: ajuda, tipo: "gauge"},
+		funcao:  funcao,
+	}
+	Padrao.registrar(m)
+	return m
+}
+
+// NovoContadorFuncao registra um contador cujo total vem de uma função
+func NovoContadorFuncao(nome, ajuda string, funcao func() float64) *MedidorFuncao {
+	m := &MedidorFuncao{
+		familia: familia{nomeMetrica: nome, ajuda: ajuda, tipo: "counter"},
+		funcao:  funcao,
+	}
+	Padrao.registrar(m)
+	return m
+}
+
+func (m *MedidorFuncao) escrever(w io.Writer) {
+	m.escreverCabecalho(w)
+	fmt.Fprintf(w, "%s %s\n", m.nomeMetrica, formatarNumero(m.funcao()))
+}
+
+// serieHistograma acumula contagens por bucket, soma e total
+type serieHistograma struct {
+	rotulos    []string
+	contagens  []uint64
+	soma       float64
+	quantidade uint64
+}
+
+// Histograma distribui observações em buckets (ex.: latência)
+type Histograma struct {
+	familia
+	buckets []float64
+	mu      sync.Mutex
+	series  map[string]*serieHistograma
+}
+
+// NovoHistograma cria e registra um histograma; buckets nil usa BucketsPadrao
+func NovoHistograma(nome, ajuda string, buckets []float64, rotulos ...string) *Histograma {
+	if buckets == nil {
+		buckets = BucketsPadrao
+	}
+	ordenados := append([]float64(nil), buckets...)
+	sort.Float64s(ordenados)
+
+	h := &Histograma{
+		familia: familia{nomeMetrica: nome, ajuda: ajuda, tipo: "histogram", rotulos: rotulos},
+		buckets: ordenados,
+		series:  map[string]*serieHistograma{},
+	}
+	Padrao.registrar(h)
+	return h
+}
+
+// Observar registra um valor na série identificada pelos rótulos
+func (h *Histograma) Observar(valor float64, valores ...string) {
+	chave := h.chave(valores)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	s, ok := h.series[chave]
+	if !ok {
+		s = &serieHistograma{
+			rotulos:   append([]string(nil), valores...),
+			contagens: make([]uint64, len(h.buckets)),
+		}
+		h.series[chave] = s
+	}
+
+	for i, limite := range h.buckets {
+		if valor <= limite {
+			s.contagens[i]++
+		}
+	}
+	s.soma += valor
+	s.quantidade++
+}
+
+func (h *Histograma) escrever(w io.Writer) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.escreverCabecalho(w)
+	for _, chave := range chavesOrdenadas(h.series) {
+		s := h.series[chave]
+		for i, limite := range h.buckets {
+			fmt.Fprintf(w, "%s_bucket%s %d\n", h.nomeMetrica,
+				h.formatarRotulos(s.rotulos, "le", formatarNumero(limite)), s.contagens[i])
+		}
+		fmt.Fprintf(w, "%s_bucket%s %d\n", h.nomeMetrica, h.formatarRotulos(s.rotulos, "le", "+Inf"), s.quantidade)
+		fmt.Fprintf(w, "%s_sum%s %s\n", h.nomeMetrica, h.formatarRotulos(s.rotulos), formatarNumero(s.soma))
+		fmt.Fprintf(w, "%s_count%s %d\n", h.nomeMetrica, h.formatarRotulos(s.rotulos), s.quantidade)
+	}
+}
+
+func chavesOrdenadas[T any](series map[string]T) []string {
+	chaves := make([]string, 0, len(series))
+	for chave := range series {
+		chaves = append(chaves, chave)
+	}
+	sort.Strings(chaves)
+	return chaves
+}
+
+func formatarNumero(valor float64) string {
+	switch {
+	case math.IsInf(valor, 1):
+		return "+Inf"
+	case math.IsInf(valor, -1):
+		return "-Inf"
+	case math.IsNaN(valor):
+		return "NaN"
+	}
+	return strconv.FormatFloat(valor, 'g', -1, 64)
+}
+
+var escapadorValor = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+var escapadorAjuda = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+
+func escaparValor(valor string) string { return escapadorValor.Replace(valor) }
+func escaparAjuda(ajuda string) string { return escapadorAjuda.Replace(ajuda) }
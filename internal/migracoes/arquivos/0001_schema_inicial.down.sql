@@ -0,0 +1,6 @@
+-- 0001_schema_inicial.down.sql
+-- Remove o schema inicial
+
+DROP TRIGGER IF EXISTS trigger_update_data_atualizacao ON filmes;
+DROP FUNCTION IF EXISTS update_data_atualizacao();
+DROP TABLE IF EXISTS filmes;
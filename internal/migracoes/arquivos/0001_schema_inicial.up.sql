@@ -1,5 +1,5 @@
--- scripts/init-db.sql
--- Script de inicialização do banco de dados
+-- 0001_schema_inicial.up.sql
+-- Schema inicial: tabela de filmes, índices, trigger e dados de exemplo
 
 -- Criar extensões úteis
 CREATE EXTENSION IF NOT EXISTS "uuid-ossp";
@@ -0,0 +1,9 @@
+-- 0002_busca_textual.down.sql
+-- Remove a infraestrutura de busca textual
+
+DROP INDEX IF EXISTS idx_filmes_diretor_trgm;
+DROP INDEX IF EXISTS idx_filmes_titulo_trgm;
+DROP INDEX IF EXISTS idx_filmes_busca;
+ALTER TABLE filmes DROP COLUMN IF EXISTS busca;
+DROP FUNCTION IF EXISTS f_unaccent(TEXT);
+DROP TEXT SEARCH CONFIGURATION IF EXISTS pt_unaccent;
@@ -0,0 +1,37 @@
+-- 0002_busca_textual.up.sql
+-- Busca textual (full-text + trigramas) sobre titulo, descricao e diretor
+
+-- Extensões necessárias
+CREATE EXTENSION IF NOT EXISTS unaccent;
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- Configuração de busca em português que ignora acentos
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_ts_config WHERE cfgname = 'pt_unaccent') THEN
+        CREATE TEXT SEARCH CONFIGURATION pt_unaccent (COPY = portuguese);
+        ALTER TEXT SEARCH CONFIGURATION pt_unaccent
+            ALTER MAPPING FOR hword, hword_part, word
+            WITH unaccent, portuguese_stem;
+    END IF;
+END
+$$;
+
+-- unaccent() não é IMMUTABLE; o wrapper permite usá-la em índices
+CREATE OR REPLACE FUNCTION f_unaccent(texto TEXT)
+RETURNS TEXT AS $$
+    SELECT public.unaccent('public.unaccent', texto)
+$$ LANGUAGE sql IMMUTABLE PARALLEL SAFE STRICT;
+
+-- Coluna gerada com os pesos: título (A), diretor (B), descrição (C)
+ALTER TABLE filmes ADD COLUMN IF NOT EXISTS busca tsvector
+    GENERATED ALWAYS AS (
+        setweight(to_tsvector('pt_unaccent', COALESCE(titulo, '')), 'A') ||
+        setweight(to_tsvector('pt_unaccent', COALESCE(diretor, '')), 'B') ||
+        setweight(to_tsvector('pt_unaccent', COALESCE(descricao, '')), 'C')
+    ) STORED;
+
+-- Índices para full-text e para a busca aproximada por trigramas
+CREATE INDEX IF NOT EXISTS idx_filmes_busca ON filmes USING GIN (busca);
+CREATE INDEX IF NOT EXISTS idx_filmes_titulo_trgm ON filmes USING GIN (f_unaccent(titulo) gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_filmes_diretor_trgm ON filmes USING GIN (f_unaccent(COALESCE(diretor, '')) gin_trgm_ops);
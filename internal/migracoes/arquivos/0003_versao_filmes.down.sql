@@ -0,0 +1,4 @@
+-- 0003_versao_filmes.down.sql
+-- Remove a versão do registro
+
+ALTER TABLE filmes DROP COLUMN IF EXISTS versao;
@@ -0,0 +1,4 @@
+-- 0003_versao_filmes.up.sql
+-- Versão do registro para concorrência otimista (ETag / If-Match)
+
+ALTER TABLE filmes ADD COLUMN IF NOT EXISTS versao INTEGER NOT NULL DEFAULT 1;
@@ -0,0 +1,5 @@
+-- 0004_lixeira.down.sql
+-- Remove a exclusão lógica (filmes na lixeira voltam a ser visíveis)
+
+DROP INDEX IF EXISTS idx_filmes_deletado_em;
+ALTER TABLE filmes DROP COLUMN IF EXISTS deletado_em;
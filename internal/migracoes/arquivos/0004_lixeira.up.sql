@@ -0,0 +1,7 @@
+-- 0004_lixeira.up.sql
+-- Exclusão lógica: filmes removidos ficam na lixeira até o expurgo
+
+ALTER TABLE filmes ADD COLUMN IF NOT EXISTS deletado_em TIMESTAMP;
+
+-- Índice parcial: a lixeira é pequena perto do catálogo ativo
+CREATE INDEX IF NOT EXISTS idx_filmes_deletado_em ON filmes(deletado_em) WHERE deletado_em IS NOT NULL;
@@ -0,0 +1,4 @@
+-- 0005_auditoria.down.sql
+-- Remove a auditoria de filmes
+
+DROP TABLE IF EXISTS auditoria_filmes;
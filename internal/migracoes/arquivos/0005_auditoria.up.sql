@@ -0,0 +1,19 @@
+-- 0005_auditoria.up.sql
+-- Auditoria das alterações de filmes, gravada na mesma transação da escrita
+
+CREATE TABLE IF NOT EXISTS auditoria_filmes (
+    id BIGSERIAL PRIMARY KEY,
+    -- Sem chave estrangeira: o histórico sobrevive ao expurgo do filme
+    filme_id INTEGER NOT NULL,
+    operacao VARCHAR(20) NOT NULL,
+    ator VARCHAR(255) NOT NULL,
+    id_requisicao VARCHAR(128),
+    momento TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    antes JSONB,
+    depois JSONB,
+    alteracoes JSONB NOT NULL DEFAULT '{}'
+);
+
+CREATE INDEX IF NOT EXISTS idx_auditoria_filme ON auditoria_filmes(filme_id, momento DESC);
+CREATE INDEX IF NOT EXISTS idx_auditoria_momento ON auditoria_filmes(momento DESC);
+CREATE INDEX IF NOT EXISTS idx_auditoria_ator ON auditoria_filmes(ator, momento DESC);
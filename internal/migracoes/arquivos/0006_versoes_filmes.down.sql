@@ -0,0 +1,4 @@
+-- 0006_versoes_filmes.down.sql
+-- Remove o histórico de revisões
+
+DROP TABLE IF EXISTS versoes_filmes;
@@ -0,0 +1,35 @@
+-- 0006_versoes_filmes.up.sql
+-- Snapshot completo de cada revisão de filme, numerada pela coluna versao
+
+CREATE TABLE IF NOT EXISTS versoes_filmes (
+    filme_id INTEGER NOT NULL,
+    versao INTEGER NOT NULL,
+    operacao VARCHAR(20) NOT NULL,
+    ator VARCHAR(255) NOT NULL,
+    id_requisicao VARCHAR(128),
+    criada_em TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    dados JSONB NOT NULL,
+    PRIMARY KEY (filme_id, versao)
+);
+
+-- A revisão atual dos filmes existentes vira o ponto de partida do histórico
+INSERT INTO versoes_filmes (filme_id, versao, operacao, ator, criada_em, dados)
+SELECT id, versao, 'criar', 'sistema', data_atualizacao,
+       jsonb_strip_nulls(jsonb_build_object(
+           'id', id,
+           'titulo', titulo,
+           'descricao', COALESCE(descricao, ''),
+           'ano_lancamento', ano_lancamento,
+           'duracao_minutos', COALESCE(duracao_minutos, 0),
+           'genero', COALESCE(genero, ''),
+           'diretor', COALESCE(diretor, ''),
+           -- Sem nota, a chave some com jsonb_strip_nulls, como no JSON da API
+           'avaliacao', avaliacao,
+           -- timestamptz vira RFC 3339 com fuso, como o JSON gerado pela API
+           'data_criacao', data_criacao AT TIME ZONE 'UTC',
+           'data_atualizacao', data_atualizacao AT TIME ZONE 'UTC',
+           'versao', versao,
+           'deletado_em', deletado_em AT TIME ZONE 'UTC'
+       ))
+FROM filmes
+ON CONFLICT (filme_id, versao) DO NOTHING;
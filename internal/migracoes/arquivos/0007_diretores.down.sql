@@ -0,0 +1,5 @@
+-- 0007_diretores.down.sql
+-- Remove os diretores normalizados; filmes.diretor continua com os nomes
+
+DROP TABLE IF EXISTS filme_diretores;
+DROP TABLE IF EXISTS pessoas;
@@ -0,0 +1,68 @@
+-- 0007_diretores.up.sql
+-- Diretores normalizados: pessoas ligadas a filmes (muitos-para-muitos).
+-- filmes.diretor permanece como texto de exibição, usado por busca e filtros.
+
+CREATE TABLE IF NOT EXISTS pessoas (
+    id SERIAL PRIMARY KEY,
+    nome VARCHAR(255) NOT NULL CHECK (BTRIM(nome) <> ''),
+    data_criacao TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    data_atualizacao TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- "Christopher Nolan" e "christopher nolan" são a mesma pessoa
+CREATE UNIQUE INDEX IF NOT EXISTS idx_pessoas_nome ON pessoas (LOWER(nome));
+
+CREATE TABLE IF NOT EXISTS filme_diretores (
+    filme_id INTEGER NOT NULL REFERENCES filmes(id) ON DELETE CASCADE,
+    -- Pessoas ligadas a filmes não podem ser apagadas
+    pessoa_id INTEGER NOT NULL REFERENCES pessoas(id) ON DELETE RESTRICT,
+    posicao INTEGER NOT NULL,
+    PRIMARY KEY (filme_id, pessoa_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_filme_diretores_pessoa ON filme_diretores(pessoa_id);
+
+-- Converte o texto livre existente em pessoas com a mesma regra de
+-- models.SepararDiretores: separa por ",", "&", " e " e " and ", e um
+-- primeiro nome isolado herda o sobrenome do último ("Anthony e Joe Russo")
+DO $$
+DECLARE
+    registro RECORD;
+    nomes TEXT[];
+    sobrenome TEXT;
+    nome_pessoa TEXT;
+    id_pessoa INTEGER;
+    ordem_credito INTEGER;
+BEGIN
+    FOR registro IN SELECT id, diretor FROM filmes WHERE BTRIM(COALESCE(diretor, '')) <> '' LOOP
+        SELECT ARRAY(
+            SELECT BTRIM(parte)
+            FROM regexp_split_to_table(BTRIM(registro.diretor), '\s*,\s*|\s*&\s*|\s+e\s+|\s+and\s+') WITH ORDINALITY AS p(parte, ordem)
+            WHERE BTRIM(parte) <> ''
+            ORDER BY ordem
+        ) INTO nomes;
+
+        sobrenome := NULL;
+        IF array_length(nomes, 1) > 1 AND nomes[array_length(nomes, 1)] LIKE '% %' THEN
+            sobrenome := regexp_replace(nomes[array_length(nomes, 1)], '^.*\s', '');
+        END IF;
+
+        ordem_credito := 0;
+        FOR i IN 1 .. COALESCE(array_length(nomes, 1), 0) LOOP
+            nome_pessoa := nomes[i];
+            IF sobrenome IS NOT NULL AND i < array_length(nomes, 1) AND nome_pessoa NOT LIKE '% %' THEN
+                nome_pessoa := nome_pessoa || ' ' || sobrenome;
+            END IF;
+
+            INSERT INTO pessoas (nome) VALUES (nome_pessoa)
+            ON CONFLICT ((LOWER(nome))) DO NOTHING;
+            SELECT p.id INTO id_pessoa FROM pessoas p WHERE LOWER(p.nome) = LOWER(nome_pessoa);
+
+            ordem_credito := ordem_credito + 1;
+            INSERT INTO filme_diretores (filme_id, pessoa_id, posicao)
+            VALUES (registro.id, id_pessoa, ordem_credito)
+            ON CONFLICT DO NOTHING;
+        END LOOP;
+    END LOOP;
+END
+$$;
@@ -0,0 +1,4 @@
+-- 0008_creditos.down.sql
+-- Remove os créditos de elenco e equipe
+
+DROP TABLE IF EXISTS creditos;
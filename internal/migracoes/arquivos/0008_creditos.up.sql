@@ -0,0 +1,20 @@
+-- 0008_creditos.up.sql
+-- Créditos de elenco e equipe: pessoa, função, personagem e ordem de exibição
+
+CREATE TABLE IF NOT EXISTS creditos (
+    id SERIAL PRIMARY KEY,
+    filme_id INTEGER NOT NULL REFERENCES filmes(id) ON DELETE CASCADE,
+    pessoa_id INTEGER NOT NULL REFERENCES pessoas(id) ON DELETE RESTRICT,
+    funcao VARCHAR(30) NOT NULL CHECK (funcao IN
+        ('ator', 'roteirista', 'produtor', 'compositor', 'fotografia', 'montagem', 'figurino')),
+    personagem VARCHAR(255),
+    posicao INTEGER NOT NULL CHECK (posicao > 0),
+    data_criacao TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Um ator pode ter mais de um personagem, mas não o mesmo crédito duas vezes
+CREATE UNIQUE INDEX IF NOT EXISTS idx_creditos_unico
+    ON creditos (filme_id, pessoa_id, funcao, COALESCE(personagem, ''));
+
+CREATE INDEX IF NOT EXISTS idx_creditos_filme ON creditos(filme_id, posicao, id);
+CREATE INDEX IF NOT EXISTS idx_creditos_pessoa ON creditos(pessoa_id);
@@ -0,0 +1,5 @@
+-- 0009_generos.down.sql
+-- Remove a taxonomia de gêneros; filmes.genero continua com o nome principal
+
+DROP TABLE IF EXISTS filme_generos;
+DROP TABLE IF EXISTS generos;
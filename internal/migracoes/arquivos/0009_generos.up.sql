@@ -0,0 +1,83 @@
+-- 0009_generos.up.sql
+-- Taxonomia de gêneros com nomes canônicos e slugs, ligada aos filmes
+-- (muitos-para-muitos). filmes.genero permanece como texto de exibição: a
+-- migração não o altera (o ETag não mudaria) e cada escrita passa a gravar
+-- nele o nome canônico do primeiro gênero.
+
+CREATE TABLE IF NOT EXISTS generos (
+    id SERIAL PRIMARY KEY,
+    nome VARCHAR(100) NOT NULL,
+    slug VARCHAR(100) NOT NULL UNIQUE CHECK (slug ~ '^[a-z0-9]+(-[a-z0-9]+)*$'),
+    -- Slugs alternativos aceitos na entrada ("action" para "acao")
+    sinonimos TEXT[] NOT NULL DEFAULT '{}',
+    data_criacao TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS filme_generos (
+    filme_id INTEGER NOT NULL REFERENCES filmes(id) ON DELETE CASCADE,
+    genero_id INTEGER NOT NULL REFERENCES generos(id) ON DELETE RESTRICT,
+    posicao INTEGER NOT NULL,
+    PRIMARY KEY (filme_id, genero_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_filme_generos_genero ON filme_generos(genero_id);
+CREATE INDEX IF NOT EXISTS idx_generos_sinonimos ON generos USING GIN (sinonimos);
+
+-- Mesma lista de generosExemplo (repositório em memória)
+INSERT INTO generos (nome, slug, sinonimos) VALUES
+    ('Ação', 'acao', '{action}'),
+    ('Animação', 'animacao', '{animation}'),
+    ('Aventura', 'aventura', '{adventure}'),
+    ('Biografia', 'biografia', '{biography,biopic}'),
+    ('Comédia', 'comedia', '{comedy}'),
+    ('Crime', 'crime', '{policial}'),
+    ('Documentário', 'documentario', '{documentary}'),
+    ('Drama', 'drama', '{}'),
+    ('Família', 'familia', '{family}'),
+    ('Fantasia', 'fantasia', '{fantasy}'),
+    ('Faroeste', 'faroeste', '{western}'),
+    ('Ficção Científica', 'ficcao-cientifica', '{sci-fi,science-fiction,ficcao}'),
+    ('Guerra', 'guerra', '{war}'),
+    ('História', 'historia', '{history,historico}'),
+    ('Mistério', 'misterio', '{mystery}'),
+    ('Musical', 'musical', '{}'),
+    ('Romance', 'romance', '{}'),
+    ('Suspense', 'suspense', '{thriller}'),
+    ('Terror', 'terror', '{horror}')
+ON CONFLICT (slug) DO NOTHING;
+
+-- Converte o texto livre existente com a regra de models.Slug e
+-- models.SepararGeneros; valores fora da taxonomia viram novos gêneros
+DO $$
+DECLARE
+    registro RECORD;
+    parte TEXT;
+    chave TEXT;
+    id_genero INTEGER;
+    ordem INTEGER;
+BEGIN
+    FOR registro IN SELECT id, genero FROM filmes WHERE BTRIM(COALESCE(genero, '')) <> '' LOOP
+        ordem := 0;
+        FOREACH parte IN ARRAY regexp_split_to_array(BTRIM(registro.genero), '\s*[,/]\s*') LOOP
+            chave := BTRIM(regexp_replace(LOWER(f_unaccent(BTRIM(parte))), '[^a-z0-9]+', '-', 'g'), '-');
+            CONTINUE WHEN chave = '';
+
+            SELECT g.id INTO id_genero FROM generos g
+            WHERE g.slug = chave OR chave = ANY(g.sinonimos)
+            ORDER BY g.slug = chave DESC
+            LIMIT 1;
+
+            IF id_genero IS NULL THEN
+                INSERT INTO generos (nome, slug) VALUES (INITCAP(BTRIM(parte)), chave)
+                RETURNING id INTO id_genero;
+            END IF;
+
+            ordem := ordem + 1;
+            INSERT INTO filme_generos (filme_id, genero_id, posicao)
+            VALUES (registro.id, id_genero, ordem)
+            ON CONFLICT DO NOTHING;
+        END LOOP;
+
+    END LOOP;
+END
+$$;
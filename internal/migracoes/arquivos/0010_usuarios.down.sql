@@ -0,0 +1,5 @@
+-- 0010_usuarios.down.sql
+-- Remove contas e sessões de usuário
+
+DROP TABLE IF EXISTS sessoes;
+DROP TABLE IF EXISTS usuarios;
@@ -0,0 +1,30 @@
+-- 0010_usuarios.up.sql
+-- Contas de usuário com senha em hash argon2id e sessões de login
+
+CREATE TABLE IF NOT EXISTS usuarios (
+    id SERIAL PRIMARY KEY,
+    nome VARCHAR(100) NOT NULL CHECK (BTRIM(nome) <> ''),
+    email VARCHAR(255) NOT NULL,
+    -- Formato PHC: $argon2id$v=19$m=...,t=...,p=...$sal$hash
+    senha_hash TEXT NOT NULL,
+    -- Falhas de login consecutivas; zerado no sucesso e ao bloquear a conta
+    tentativas_falhas INTEGER NOT NULL DEFAULT 0,
+    bloqueado_ate TIMESTAMP,
+    ultimo_login TIMESTAMP,
+    data_criacao TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    data_atualizacao TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- O email identifica a conta sem diferenciar maiúsculas
+CREATE UNIQUE INDEX IF NOT EXISTS idx_usuarios_email ON usuarios (LOWER(email));
+
+CREATE TABLE IF NOT EXISTS sessoes (
+    -- SHA-256 do token entregue ao cliente; o token em si não é guardado
+    token_hash CHAR(64) PRIMARY KEY,
+    usuario_id INTEGER NOT NULL REFERENCES usuarios(id) ON DELETE CASCADE,
+    data_criacao TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expira_em TIMESTAMP NOT NULL,
+    revogada_em TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_sessoes_usuario ON sessoes(usuario_id);
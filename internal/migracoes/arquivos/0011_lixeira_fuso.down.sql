@@ -0,0 +1,4 @@
+-- 0011_lixeira_fuso.down.sql
+-- Volta deletado_em para TIMESTAMP, em UTC
+
+ALTER TABLE filmes ALTER COLUMN deletado_em TYPE TIMESTAMP USING deletado_em AT TIME ZONE 'UTC';
@@ -0,0 +1,6 @@
+-- 0011_lixeira_fuso.up.sql
+-- deletado_em passa a guardar o instante com fuso: TIMESTAMP descartava o
+-- deslocamento enviado pela API e a comparação com o corte da retenção
+-- dependia do fuso do processo. Valores antigos são lidos como UTC.
+
+ALTER TABLE filmes ALTER COLUMN deletado_em TYPE TIMESTAMPTZ USING deletado_em AT TIME ZONE 'UTC';
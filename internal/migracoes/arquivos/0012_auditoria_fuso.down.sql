@@ -0,0 +1,4 @@
+-- 0012_auditoria_fuso.down.sql
+-- Volta momento para TIMESTAMP, no fuso da sessão
+
+ALTER TABLE auditoria_filmes ALTER COLUMN momento TYPE TIMESTAMP;
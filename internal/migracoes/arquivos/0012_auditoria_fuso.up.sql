@@ -0,0 +1,7 @@
+-- 0012_auditoria_fuso.up.sql
+-- momento passa a guardar o instante com fuso, para que os filtros desde/ate
+-- (enviados pela API com deslocamento) não dependam do fuso do servidor.
+-- Os valores antigos vieram do DEFAULT, no fuso da sessão, e são
+-- convertidos nesse mesmo fuso.
+
+ALTER TABLE auditoria_filmes ALTER COLUMN momento TYPE TIMESTAMPTZ;
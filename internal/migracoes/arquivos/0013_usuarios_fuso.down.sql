@@ -0,0 +1,13 @@
+-- 0013_usuarios_fuso.down.sql
+-- Volta os horários de contas e sessões para TIMESTAMP
+
+ALTER TABLE sessoes
+    ALTER COLUMN data_criacao TYPE TIMESTAMP USING data_criacao AT TIME ZONE 'UTC',
+    ALTER COLUMN expira_em TYPE TIMESTAMP USING expira_em AT TIME ZONE 'UTC',
+    ALTER COLUMN revogada_em TYPE TIMESTAMP USING revogada_em AT TIME ZONE 'UTC';
+
+ALTER TABLE usuarios
+    ALTER COLUMN bloqueado_ate TYPE TIMESTAMP USING bloqueado_ate AT TIME ZONE 'UTC',
+    ALTER COLUMN ultimo_login TYPE TIMESTAMP USING ultimo_login AT TIME ZONE 'UTC',
+    ALTER COLUMN data_criacao TYPE TIMESTAMP,
+    ALTER COLUMN data_atualizacao TYPE TIMESTAMP;
@@ -0,0 +1,16 @@
+-- 0013_usuarios_fuso.up.sql
+-- Horários de contas e sessões passam a guardar o instante com fuso: com
+-- TIMESTAMP, o deslocamento enviado pela API era descartado e a expiração
+-- das sessões dependia do fuso do processo. Valores gravados pela API são
+-- lidos como UTC; os que vieram do DEFAULT, no fuso da sessão.
+
+ALTER TABLE usuarios
+    ALTER COLUMN bloqueado_ate TYPE TIMESTAMPTZ USING bloqueado_ate AT TIME ZONE 'UTC',
+    ALTER COLUMN ultimo_login TYPE TIMESTAMPTZ USING ultimo_login AT TIME ZONE 'UTC',
+    ALTER COLUMN data_criacao TYPE TIMESTAMPTZ,
+    ALTER COLUMN data_atualizacao TYPE TIMESTAMPTZ;
+
+ALTER TABLE sessoes
+    ALTER COLUMN data_criacao TYPE TIMESTAMPTZ USING data_criacao AT TIME ZONE 'UTC',
+    ALTER COLUMN expira_em TYPE TIMESTAMPTZ USING expira_em AT TIME ZONE 'UTC',
+    ALTER COLUMN revogada_em TYPE TIMESTAMPTZ USING revogada_em AT TIME ZONE 'UTC';
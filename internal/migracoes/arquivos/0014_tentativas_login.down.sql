@@ -0,0 +1,13 @@
+-- 0014_tentativas_login.down.sql
+-- Volta o bloqueio para as contas; tentativas de emails sem conta se perdem
+
+ALTER TABLE usuarios
+    ADD COLUMN IF NOT EXISTS tentativas_falhas INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS bloqueado_ate TIMESTAMPTZ;
+
+UPDATE usuarios u
+SET tentativas_falhas = t.falhas, bloqueado_ate = t.bloqueado_ate
+FROM tentativas_login t
+WHERE t.email = LOWER(u.email);
+
+DROP TABLE IF EXISTS tentativas_login;
@@ -0,0 +1,23 @@
+-- 0014_tentativas_login.up.sql
+-- Falhas de login e bloqueio passam a ser contados por email, exista ou não
+-- a conta: assim a resposta (401 ou 423) não revela quais emails estão
+-- cadastrados
+
+CREATE TABLE IF NOT EXISTS tentativas_login (
+    -- Email normalizado (minúsculas, sem espaços)
+    email VARCHAR(255) PRIMARY KEY,
+    -- Falhas consecutivas; zerado no sucesso e ao bloquear
+    falhas INTEGER NOT NULL DEFAULT 0,
+    bloqueado_ate TIMESTAMPTZ,
+    data_atualizacao TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO tentativas_login (email, falhas, bloqueado_ate)
+SELECT LOWER(email), tentativas_falhas, bloqueado_ate
+FROM usuarios
+WHERE tentativas_falhas > 0 OR bloqueado_ate IS NOT NULL
+ON CONFLICT (email) DO NOTHING;
+
+ALTER TABLE usuarios
+    DROP COLUMN IF EXISTS tentativas_falhas,
+    DROP COLUMN IF EXISTS bloqueado_ate;
@@ -0,0 +1,2 @@
+-- 0015_versoes_sem_avaliacao.down.sql
+-- Nada a desfazer: um snapshot sem avaliacao é o formato gravado pela API
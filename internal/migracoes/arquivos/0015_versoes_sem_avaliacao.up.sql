@@ -0,0 +1,21 @@
+-- 0015_versoes_sem_avaliacao.up.sql
+-- A 0006 gravava avaliacao 0 no snapshot inicial de filmes sem nota, e
+-- reverter para essa revisão dava nota 0.0 ao filme. Remove o 0 quando a
+-- revisão seguinte (ou o filme, se não houver outra) continua sem nota.
+
+UPDATE versoes_filmes v
+SET dados = v.dados - 'avaliacao'
+FROM filmes f
+WHERE f.id = v.filme_id
+  AND v.operacao = 'criar'
+  AND v.ator = 'sistema'
+  AND v.id_requisicao IS NULL
+  AND v.dados->'avaliacao' = '0'::jsonb
+  AND COALESCE(
+      (SELECT jsonb_typeof(s.dados->'avaliacao') = 'null' OR NOT s.dados ? 'avaliacao'
+       FROM versoes_filmes s
+       WHERE s.filme_id = v.filme_id AND s.versao > v.versao
+       ORDER BY s.versao
+       LIMIT 1),
+      f.avaliacao IS NULL
+  );
@@ -0,0 +1,3 @@
+-- 0016_tentativas_login_expurgo.down.sql
+
+DROP INDEX IF EXISTS idx_tentativas_login_data_atualizacao;
@@ -0,0 +1,5 @@
+-- 0016_tentativas_login_expurgo.up.sql
+-- O expurgo periódico apaga as tentativas de login pela data da última
+-- falha; sem índice, cada rodada percorreria a tabela inteira
+
+CREATE INDEX IF NOT EXISTS idx_tentativas_login_data_atualizacao ON tentativas_login(data_atualizacao);
@@ -0,0 +1,327 @@
+package migracoes
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// arquivosEmbutidos contém os arquivos NNNN_nome.up.sql / NNNN_nome.down.sql
+//
+//go:embed arquivos/*.sql
+var arquivosEmbutidos embed.FS
+
+// DiretorioPadrao é onde "migrate create" grava novas migrações
+const DiretorioPadrao = "internal/migracoes/arquivos"
+
+// chaveBloqueio identifica o advisory lock usado pelos executores de migração
+const chaveBloqueio int64 = 7_301_988_421
+
+var padraoArquivo = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+// Migracao representa uma versão do schema com seus scripts de subida e descida
+type Migracao struct {
+	Versao  int64
+	Nome    string
+	Subida  string
+	Descida string
+}
+
+// StatusMigracao indica se uma migração já foi aplicada
+type StatusMigracao struct {
+	Versao     int64      `json:"versao"`
+	Nome       string     `json:"nome"`
+	Aplicada   bool       `json:"aplicada"`
+	AplicadaEm *time.Time `json:"aplicada_em,omitempty"`
+}
+
+// Migrador aplica e reverte migrações em um banco PostgreSQL
+type Migrador struct {
+	conexao   *sql.DB
+	migracoes []Migracao
+}
+
+// NovoMigrador carrega as migrações embutidas no binário
+func NovoMigrador(conexao *sql.DB) (*Migrador, error) {
+	migracoes, err := carregarMigracoes(arquivosEmbutidos, "arquivos")
+	if err != nil {
+		return nil, err
+	}
+	return &Migrador{conexao: conexao, migracoes: migracoes}, nil
+}
+
+// carregarMigracoes lê e valida os pares up/down de um diretório
+func carregarMigracoes(arquivos fs.FS, diretorio string) ([]Migracao, error) {
+	entradas, err := fs.ReadDir(arquivos, diretorio)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao listar migrações: %v", err)
+	}
+
+	porVersao := map[int64]*Migracao{}
+
+	for _, entrada := range entradas {
+		partes := padraoArquivo.FindStringSubmatch(entrada.Name())
+		if partes == nil {
+			return nil, fmt.Errorf("nome de migração inválido: %s", entrada.Name())
+		}
+
+		versao, _ := strconv.ParseInt(partes[1], 10, 64)
+		conteudo, err := fs.ReadFile(arquivos, path.Join(diretorio, entrada.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("erro ao ler migração %s: %v", entrada.Name(), err)
+		}
+
+		migracao, ok := porVersao[versao]
+		if !ok {
+			migracao = &Migracao{Versao: versao, Nome: partes[2]}
+			porVersao[versao] = migracao
+		} else if migracao.Nome != partes[2] {
+			return nil, fmt.Errorf("versão %d duplicada: %s e %s", versao, migracao.Nome, partes[2])
+		}
+
+		if partes[3] == "up" {
+			migracao.Subida = string(conteudo)
+		} else {
+			migracao.Descida = string(conteudo)
+		}
+	}
+
+	migracoes := make([]Migracao, 0, len(porVersao))
+	for _, migracao := range porVersao {
+		if migracao.Subida == "" || migracao.Descida == "" {
+			return nil, fmt.Errorf("migração %d_%s precisa de arquivos up e down", migracao.Versao, migracao.Nome)
+		}
+		migracoes = append(migracoes, *migracao)
+	}
+
+	sort.Slice(migracoes, func(i, j int) bool { return migracoes[i].Versao < migracoes[j].Versao })
+	return migracoes, nil
+}
+
+// Subir aplica todas as migrações pendentes, em ordem
+func (m *Migrador) Subir(ctx context.Context) ([]Migracao, error) {
+	var aplicadas []Migracao
+
+	err := m.comBloqueio(ctx, func(conn *sql.Conn) error {
+		versoes, err := versoesAplicadas(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, migracao := range m.migracoes {
+			if _, ok := versoes[migracao.Versao]; ok {
+				continue
+			}
+
+			err := executarEmTransacao(ctx, conn, migracao.Subida,
+				"INSERT INTO schema_migrations (versao, nome) VALUES ($1, $2)",
+				migracao.Versao, migracao.Nome)
+			if err != nil {
+				return fmt.Errorf("erro ao aplicar migração %d_%s: %v", migracao.Versao, migracao.Nome, err)
+			}
+			aplicadas = append(aplicadas, migracao)
+		}
+		return nil
+	})
+
+	return aplicadas, err
+}
+
+// Descer reverte as últimas migrações aplicadas (passos >= 1)
+func (m *Migrador) Descer(ctx context.Context, passos int) ([]Migracao, error) {
+	var revertidas []Migracao
+
+	err := m.comBloqueio(ctx, func(conn *sql.Conn) error {
+		versoes, err := versoesAplicadas(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for i := len(m.migracoes) - 1; i >= 0 && len(revertidas) < passos; i-- {
+			migracao := m.migracoes[i]
+			if _, ok := versoes[migracao.Versao]; !ok {
+				continue
+			}
+
+			err := executarEmTransacao(ctx, conn, migracao.Descida,
+				"DELETE FROM schema_migrations WHERE versao = $1", migracao.Versao)
+			if err != nil {
+				return fmt.Errorf("erro ao reverter migração %d_%s: %v", migracao.Versao, migracao.Nome, err)
+			}
+			revertidas = append(revertidas, migracao)
+		}
+		return nil
+	})
+
+	return revertidas, err
+}
+
+// Status lista todas as migrações conhecidas e se já foram aplicadas
+func (m *Migrador) Status(ctx context.Context) ([]StatusMigracao, error) {
+	conn, err := m.conexao.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao obter conexão: %v", err)
+	}
+	defer conn.Close()
+
+	versoes, err := versoesAplicadas(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	status := make([]StatusMigracao, 0, len(m.migracoes))
+	for _, migracao := range m.migracoes {
+		item := StatusMigracao{Versao: migracao.Versao, Nome: migracao.Nome}
+		if aplicadaEm, ok := versoes[migracao.Versao]; ok {
+			item.Aplicada = true
+			item.AplicadaEm = &aplicadaEm
+		}
+		status = append(status, item)
+	}
+	return status, nil
+}
+
+// comBloqueio executa fn segurando o advisory lock, impedindo que dois
+// processos (ex.: réplicas subindo juntas) migrem o banco ao mesmo tempo
+func (m *Migrador) comBloqueio(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	conn, err := m.conexao.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("erro ao obter conexão: %v", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", chaveBloqueio); err != nil {
+		return fmt.Errorf("erro ao adquirir bloqueio de migração: %v", err)
+	}
+	defer conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", chaveBloqueio)
+
+	if err := criarTabelaControle(ctx, conn); err != nil {
+		return err
+	}
+
+	return fn(conn)
+}
+
+// criarTabelaControle garante a existência de schema_migrations
+func criarTabelaControle(ctx context.Context, conn *sql.Conn) error {
+	_, err := conn.ExecContext(ctx, `
+        CREATE TABLE IF NOT EXISTS schema_migrations (
+            versao BIGINT PRIMARY KEY,
+            nome VARCHAR(255) NOT NULL,
+            aplicada_em TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+        )
+    `)
+	if err != nil {
+		return fmt.Errorf("erro ao criar schema_migrations: %v", err)
+	}
+	return nil
+}
+
+// versoesAplicadas retorna as versões registradas em schema_migrations
+func versoesAplicadas(ctx context.Context, conn *sql.Conn) (map[int64]time.Time, error) {
+	versoes := map[int64]time.Time{}
+
+	var existe bool
+	err := conn.QueryRowContext(ctx, "SELECT to_regclass('schema_migrations') IS NOT NULL").Scan(&existe)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao verificar schema_migrations: %v", err)
+	}
+	if !existe {
+		return versoes, nil
+	}
+
+	linhas, err := conn.QueryContext(ctx, "SELECT versao, aplicada_em FROM schema_migrations")
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler schema_migrations: %v", err)
+	}
+	defer linhas.Close()
+
+	for linhas.Next() {
+		var versao int64
+		var aplicadaEm time.Time
+		if err := linhas.Scan(&versao, &aplicadaEm); err != nil {
+			return nil, fmt.Errorf("erro ao ler schema_migrations: %v", err)
+		}
+		versoes[versao] = aplicadaEm
+	}
+
+	return versoes, linhas.Err()
+}
+
+// executarEmTransacao roda o script e o registro de controle atomicamente
+func executarEmTransacao(ctx context.Context, conn *sql.Conn, script, controle string, args ...interface{}) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, script); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, controle, args...); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Criar gera um novo par de arquivos de migração vazios no diretório informado
+func Criar(diretorio, nome string) ([]string, error) {
+	nome = strings.ToLower(strings.TrimSpace(nome))
+	nome = regexp.MustCompile(`[^a-z0-9]+`).ReplaceAllString(nome, "_")
+	nome = strings.Trim(nome, "_")
+	if nome == "" {
+		return nil, fmt.Errorf("nome da migração é obrigatório")
+	}
+
+	existentes, err := carregarMigracoes(os.DirFS(diretorio), ".")
+	if err != nil {
+		return nil, err
+	}
+
+	var proxima int64 = 1
+	if len(existentes) > 0 {
+		proxima = existentes[len(existentes)-1].Versao + 1
+	}
+
+	var criados []string
+	for _, direcao := range []string{"up", "down"} {
+		arquivo := fmt.Sprintf("%04d_%s.%s.sql", proxima, nome, direcao)
+		caminho := filepath.Join(diretorio, arquivo)
+		conteudo := fmt.Sprintf("-- %s\n-- TODO: descreva a migração\n", arquivo)
+
+		if err := os.WriteFile(caminho, []byte(conteudo), 0o644); err != nil {
+			return criados, fmt.Errorf("erro ao criar %s: %v", caminho, err)
+		}
+		criados = append(criados, caminho)
+	}
+
+	return criados, nil
+}
+
+// Pendentes retorna quantas migrações embutidas ainda não foram aplicadas
+func (m *Migrador) Pendentes(ctx context.Context) (int, error) {
+	status, err := m.Status(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	pendentes := 0
+	for _, item := range status {
+		if !item.Aplicada {
+			pendentes++
+		}
+	}
+	return pendentes, nil
+}
@@ -0,0 +1,138 @@
+package models
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"time"
+)
+
+// Operações registradas na auditoria de filmes
+const (
+	OperacaoCriar     = "criar"
+	OperacaoAtualizar = "atualizar"
+	OperacaoDeletar   = "deletar"
+	OperacaoRestaurar = "restaurar"
+	OperacaoExpurgar  = "expurgar"
+	OperacaoReverter  = "reverter"
+)
+
+// OperacoesAuditoria lista os valores aceitos no filtro por operação
+var OperacoesAuditoria = []string{OperacaoCriar, OperacaoAtualizar, OperacaoDeletar, OperacaoRestaurar, OperacaoExpurgar, OperacaoReverter}
+
+// RegistroAuditoria descreve uma alteração de filme: quem, quando, em qual
+// requisição e o estado antes e depois (nil na criação/expurgo)
+type RegistroAuditoria struct {
+	ID           int64                `json:"id"`
+	FilmeID      int                  `json:"filme_id"`
+	Operacao     string               `json:"operacao"`
+	Ator         string               `json:"ator"`
+	IDRequisicao string               `json:"id_requisicao,omitempty"`
+	Momento      time.Time            `json:"momento"`
+	Antes        *Filme               `json:"antes,omitempty"`
+	Depois       *Filme               `json:"depois,omitempty"`
+	Alteracoes   map[string]Alteracao `json:"alteracoes"`
+}
+
+// Alteracao guarda os valores de um campo antes e depois da mudança
+type Alteracao struct {
+	Antes  any `json:"antes"`
+	Depois any `json:"depois"`
+}
+
+// VersaoFilme é uma revisão do filme: o snapshot gravado quando a coluna
+// versao assumiu o valor Versao. Filme é omitido nas listagens; Ator e
+// IDRequisicao, nas rotas públicas.
+type VersaoFilme struct {
+	Versao       int       `json:"versao"`
+	Operacao     string    `json:"operacao"`
+	Ator         string    `json:"ator,omitempty"`
+	IDRequisicao string    `json:"id_requisicao,omitempty"`
+	CriadaEm     time.Time `json:"criada_em"`
+	Filme        *Filme    `json:"filme,omitempty"`
+}
+
+// RespostaVersoes lista as revisões de um filme, da mais recente à mais antiga
+type RespostaVersoes struct {
+	FilmeID int           `json:"filme_id"`
+	Versoes []VersaoFilme `json:"versoes"`
+}
+
+// DiferencaVersoes é a comparação campo a campo entre duas revisões
+type DiferencaVersoes struct {
+	FilmeID    int                  `json:"filme_id"`
+	De         int                  `json:"de"`
+	Para       int                  `json:"para"`
+	Alteracoes map[string]Alteracao `json:"alteracoes"`
+}
+
+// FiltroAuditoria restringe a consulta à auditoria; campos vazios não filtram
+type FiltroAuditoria struct {
+	FilmeID  *int
+	Ator     string
+	Operacao string
+	Desde    *time.Time
+	Ate      *time.Time
+
+	Limite       int
+	Deslocamento int
+}
+
+// RespostaAuditoria lista uma página de registros de auditoria
+type RespostaAuditoria struct {
+	Registros []RegistroAuditoria `json:"registros"`
+	Pagina    int                 `json:"pagina"`
+	Limite    int                 `json:"limite"`
+	HaMais    bool                `json:"ha_mais"`
+}
+
+// camposSemDiferenca são metadados que mudam em toda escrita e não
+// interessam a quem lê a diferença
+var camposSemDiferenca = map[string]bool{
+	"data_criacao":     true,
+	"data_atualizacao": true,
+	"versao":           true,
+}
+
+// DiferencaFilmes compara duas representações de um filme campo a campo,
+// pelos nomes JSON. Um lado nil conta como todos os campos ausentes.
+func DiferencaFilmes(antes, depois *Filme) map[string]Alteracao {
+	camposAntes, camposDepois := camposJSON(antes), camposJSON(depois)
+
+	nomes := make([]string, 0, len(camposAntes)+len(camposDepois))
+	for nome := range camposAntes {
+		nomes = append(nomes, nome)
+	}
+	for nome := range camposDepois {
+		if _, ok := camposAntes[nome]; !ok {
+			nomes = append(nomes, nome)
+		}
+	}
+	sort.Strings(nomes)
+
+	diferenca := map[string]Alteracao{}
+	for _, nome := range nomes {
+		if camposSemDiferenca[nome] || nome == "id" {
+			continue
+		}
+		valorAntes, valorDepois := camposAntes[nome], camposDepois[nome]
+		if !reflect.DeepEqual(valorAntes, valorDepois) {
+			diferenca[nome] = Alteracao{Antes: valorAntes, Depois: valorDepois}
+		}
+	}
+	return diferenca
+}
+
+// camposJSON devolve o filme como mapa de campos JSON (vazio para nil)
+func camposJSON(filme *Filme) map[string]any {
+	campos := map[string]any{}
+	if filme == nil {
+		return campos
+	}
+	dados, err := json.Marshal(filme)
+	if err != nil {
+		return campos
+	}
+	json.Unmarshal(dados, &campos)
+	return campos
+}
@@ -0,0 +1,159 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Funções aceitas nos créditos de um filme. A direção fica nos diretores
+// do filme (FuncaoDiretor só aparece na filmografia).
+const (
+	FuncaoAtor       = "ator"
+	FuncaoRoteirista = "roteirista"
+	FuncaoProdutor   = "produtor"
+	FuncaoCompositor = "compositor"
+	FuncaoFotografia = "fotografia"
+	FuncaoMontagem   = "montagem"
+	FuncaoFigurino   = "figurino"
+	FuncaoDiretor    = "diretor"
+)
+
+// MaximoCreditosBloco limita os créditos embutidos em FilmeDetalhado
+const MaximoCreditosBloco = 10
+
+// FuncoesCredito lista as funções aceitas em CreditoParaCriar
+var FuncoesCredito = []string{
+	FuncaoAtor, FuncaoRoteirista, FuncaoProdutor, FuncaoCompositor,
+	FuncaoFotografia, FuncaoMontagem, FuncaoFigurino,
+}
+
+// Credito liga uma pessoa a um filme com uma função; Posicao define a
+// ordem de exibição (billing), da menor para a maior
+type Credito struct {
+	ID         int          `json:"id"`
+	Pessoa     PessoaResumo `json:"pessoa"`
+	Funcao     string       `json:"funcao"`
+	Personagem string       `json:"personagem,omitempty"`
+	Posicao    int          `json:"posicao"`
+}
+
+// CreditoParaCriar identifica a pessoa pelo ID ou pelo nome (criada se
+// ainda não existir). Sem posição, o crédito vai para o fim da lista.
+type CreditoParaCriar struct {
+	PessoaID   *int    `json:"pessoa_id,omitempty"`
+	Nome       string  `json:"nome,omitempty"`
+	Funcao     string  `json:"funcao"`
+	Personagem *string `json:"personagem,omitempty"`
+	Posicao    *int    `json:"posicao,omitempty"`
+}
+
+// RespostaElenco lista os créditos de um filme
+type RespostaElenco struct {
+	FilmeID  int       `json:"filme_id"`
+	Creditos []Credito `json:"creditos"`
+}
+
+// CreditoResumo é a forma compacta de um crédito embutida em FilmeDetalhado
+type CreditoResumo struct {
+	PessoaID   int    `json:"pessoa_id"`
+	Nome       string `json:"nome"`
+	Funcao     string `json:"funcao"`
+	Personagem string `json:"personagem,omitempty"`
+}
+
+// BlocoCreditos resume o elenco: os primeiros créditos e o total
+type BlocoCreditos struct {
+	Total      int             `json:"total"`
+	Principais []CreditoResumo `json:"principais"`
+}
+
+// FilmeDetalhado é a resposta de GET /filmes/{id}: o filme e o resumo dos créditos
+type FilmeDetalhado struct {
+	*Filme
+	Creditos BlocoCreditos `json:"creditos"`
+}
+
+// ResumirCreditos monta o bloco com os MaximoCreditosBloco primeiros créditos
+func ResumirCreditos(creditos []Credito) BlocoCreditos {
+	bloco := BlocoCreditos{Total: len(creditos), Principais: []CreditoResumo{}}
+	for _, credito := range creditos {
+		if len(bloco.Principais) == MaximoCreditosBloco {
+			break
+		}
+		bloco.Principais = append(bloco.Principais, CreditoResumo{
+			PessoaID:   credito.Pessoa.ID,
+			Nome:       credito.Pessoa.Nome,
+			Funcao:     credito.Funcao,
+			Personagem: credito.Personagem,
+		})
+	}
+	return bloco
+}
+
+// ItemFilmografia é a participação de uma pessoa em um filme
+type ItemFilmografia struct {
+	FilmeID       int    `json:"filme_id"`
+	Titulo        string `json:"titulo"`
+	AnoLancamento int    `json:"ano_lancamento"`
+	Funcao        string `json:"funcao"`
+	Personagem    string `json:"personagem,omitempty"`
+}
+
+// RespostaFilmografia lista as participações da pessoa, da mais antiga à mais recente
+type RespostaFilmografia struct {
+	Pessoa      Pessoa            `json:"pessoa"`
+	Filmografia []ItemFilmografia `json:"filmografia"`
+}
+
+// FuncaoCreditoValida indica se a função pode ser usada em um crédito
+func FuncaoCreditoValida(funcao string) bool {
+	for _, valida := range FuncoesCredito {
+		if funcao == valida {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidarCredito valida um crédito antes de salvar
+func ValidarCredito(credito *CreditoParaCriar) ErrosValidacao {
+	var erros ErrosValidacao
+
+	credito.Nome = strings.TrimSpace(credito.Nome)
+	switch {
+	case credito.PessoaID == nil && credito.Nome == "":
+		erros.adicionar("pessoa_id", CodigoCampoObrigatorio, "informe pessoa_id ou nome")
+	case credito.PessoaID != nil && credito.Nome != "":
+		erros.adicionar("nome", CodigoForaDoIntervalo, "informe apenas um entre pessoa_id e nome")
+	case credito.PessoaID != nil && *credito.PessoaID < 1:
+		erros.adicionar("pessoa_id", CodigoValorMinimo, "pessoa_id deve ser positivo")
+	case len(credito.Nome) > 255:
+		erros.adicionar("nome", CodigoTamanhoMaximo, "nome deve ter no máximo 255 caracteres")
+	}
+
+	credito.Funcao = strings.ToLower(strings.TrimSpace(credito.Funcao))
+	if credito.Funcao == "" {
+		erros.adicionar("funcao", CodigoCampoObrigatorio, "função é obrigatória")
+	} else if !FuncaoCreditoValida(credito.Funcao) {
+		erros.adicionar("funcao", CodigoForaDoIntervalo,
+			fmt.Sprintf("função deve ser uma de: %s", strings.Join(FuncoesCredito, ", ")))
+	}
+
+	if credito.Personagem != nil {
+		personagem := strings.TrimSpace(*credito.Personagem)
+		credito.Personagem = &personagem
+		if personagem == "" {
+			credito.Personagem = nil
+		} else if credito.Funcao != FuncaoAtor {
+			erros.adicionar("personagem", CodigoForaDoIntervalo, "personagem só se aplica à função ator")
+		} else if len(personagem) > 255 {
+			erros.adicionar("personagem", CodigoTamanhoMaximo, "personagem deve ter no máximo 255 caracteres")
+		}
+	}
+
+	if credito.Posicao != nil && *credito.Posicao < 1 {
+		erros.adicionar("posicao", CodigoValorMinimo, "posição deve ser maior que 0")
+	}
+
+	return erros
+}
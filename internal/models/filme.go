@@ -6,26 +6,37 @@ import (
 
 // Filme representa a estrutura completa de um filme
 type Filme struct {
-	ID              int       `json:"id"`
-	Titulo          string    `json:"titulo"`
-	Descricao       string    `json:"descricao"`
-	AnoLancamento   int       `json:"ano_lancamento"`
-	DuracaoMinutos  int       `json:"duracao_minutos"`
-	Genero          string    `json:"genero"`
-	Diretor         string    `json:"diretor"`
-	Avaliacao       float64   `json:"avaliacao"`
+	ID             int    `json:"id"`
+	Titulo         string `json:"titulo"`
+	Descricao      string `json:"descricao"`
+	AnoLancamento  int    `json:"ano_lancamento"`
+	DuracaoMinutos int    `json:"duracao_minutos"`
+	Genero         string `json:"genero"`
+	// Generos é a forma normalizada de Genero; Genero é o texto de exibição
+	// (por padrão, o nome do primeiro)
+	Generos []GeneroResumo `json:"generos"`
+	Diretor string         `json:"diretor"`
+	// Diretores é a forma normalizada de Diretor, na ordem de crédito
+	Diretores []PessoaResumo `json:"diretores"`
+	// Avaliacao é nil para filmes sem nota
+	Avaliacao       *float64  `json:"avaliacao"`
 	DataCriacao     time.Time `json:"data_criacao"`
 	DataAtualizacao time.Time `json:"data_atualizacao"`
+	// Versao é incrementada a cada alteração e origina o ETag
+	Versao int `json:"versao"`
+	// DeletadoEm só é preenchido para filmes na lixeira
+	DeletadoEm *time.Time `json:"deletado_em,omitempty"`
 }
 
 // FilmeResumo para listagens
 type FilmeResumo struct {
-	ID            int     `json:"id"`
-	Titulo        string  `json:"titulo"`
-	AnoLancamento int     `json:"ano_lancamento"`
-	Genero        string  `json:"genero"`
-	Diretor       string  `json:"diretor"`
-	Avaliacao     float64 `json:"avaliacao"`
+	ID            int    `json:"id"`
+	Titulo        string `json:"titulo"`
+	AnoLancamento int    `json:"ano_lancamento"`
+	Genero        string `json:"genero"`
+	Diretor       string `json:"diretor"`
+	// Avaliacao é nil para filmes sem nota, como em Filme
+	Avaliacao *float64 `json:"avaliacao"`
 }
 
 // FilmeParaCriar estrutura para criação (sem ID e timestamps)
@@ -37,29 +48,105 @@ type FilmeParaCriar struct {
 	Genero         *string  `json:"genero,omitempty"`
 	Diretor        *string  `json:"diretor,omitempty"`
 	Avaliacao      *float64 `json:"avaliacao,omitempty"`
+	// Diretores referencia pessoas pelo ID e tem precedência sobre o texto
+	// legado Diretor, que nesse caso só é recalculado a partir dos nomes se
+	// não os descrever mais
+	Diretores []int `json:"diretores,omitempty"`
+	// Generos aceita slugs, nomes ou sinônimos e tem precedência sobre o
+	// texto legado Genero
+	Generos []string `json:"generos,omitempty"`
 }
 
-// FilmeParaAtualizar estrutura para atualização (todos campos opcionais)
-type FilmeParaAtualizar struct {
-	Titulo         *string  `json:"titulo,omitempty"`
-	Descricao      *string  `json:"descricao,omitempty"`
-	AnoLancamento  *int     `json:"ano_lancamento,omitempty"`
-	DuracaoMinutos *int     `json:"duracao_minutos,omitempty"`
-	Genero         *string  `json:"genero,omitempty"`
-	Diretor        *string  `json:"diretor,omitempty"`
-	Avaliacao      *float64 `json:"avaliacao,omitempty"`
+// ParaCriar converte o filme de volta aos campos editáveis; textos vazios e
+// duração zero voltam a ser campos ausentes (NULL no banco)
+func (f *Filme) ParaCriar() *FilmeParaCriar {
+	filme := &FilmeParaCriar{
+		Titulo:        f.Titulo,
+		AnoLancamento: f.AnoLancamento,
+		Avaliacao:     f.Avaliacao,
+		Diretores:     f.IDsDiretores(),
+		Generos:       f.SlugsGeneros(),
+	}
+	if f.Descricao != "" {
+		filme.Descricao = &f.Descricao
+	}
+	if f.DuracaoMinutos != 0 {
+		filme.DuracaoMinutos = &f.DuracaoMinutos
+	}
+	if f.Genero != "" {
+		filme.Genero = &f.Genero
+	}
+	if f.Diretor != "" {
+		filme.Diretor = &f.Diretor
+	}
+	return filme
+}
+
+// SlugsGeneros lista os slugs dos gêneros na ordem do filme
+func (f *Filme) SlugsGeneros() []string {
+	slugs := []string{}
+	for _, genero := range f.Generos {
+		slugs = append(slugs, genero.Slug)
+	}
+	return slugs
+}
+
+// IDsDiretores lista os IDs dos diretores na ordem de crédito
+func (f *Filme) IDsDiretores() []int {
+	ids := []int{}
+	for _, diretor := range f.Diretores {
+		ids = append(ids, diretor.ID)
+	}
+	return ids
 }
 
 // Estruturas de resposta
 type RespostaFilmes struct {
-	Filmes []FilmeResumo `json:"filmes"`
-	Total  int           `json:"total"`
+	Filmes    []FilmeResumo `json:"filmes"`
+	Total     int           `json:"total"`
+	Paginacao *Paginacao    `json:"paginacao,omitempty"`
+}
+
+// ResultadoBusca é um FilmeResumo acompanhado da relevância na busca textual.
+// Destaque é um trecho em HTML já escapado, com os termos encontrados entre
+// <mark> e </mark>; é a única marcação presente.
+type ResultadoBusca struct {
+	FilmeResumo
+	Relevancia      float64 `json:"relevancia"`
+	Destaque        string  `json:"destaque"`
+	Correspondencia string  `json:"correspondencia"`
+}
+
+// RespostaLixeira lista uma página de filmes excluídos
+type RespostaLixeira struct {
+	Filmes []Filme `json:"filmes"`
+	Pagina int     `json:"pagina"`
+	Limite int     `json:"limite"`
+	HaMais bool    `json:"ha_mais"`
+}
+
+type RespostaBusca struct {
+	Consulta   string           `json:"consulta"`
+	Resultados []ResultadoBusca `json:"resultados"`
+	Total      int              `json:"total"`
 }
 
 type RespostaErro struct {
-	Erro     string   `json:"erro"`
-	Codigo   int      `json:"codigo"`
-	Detalhes []string `json:"detalhes,omitempty"`
+	Erro       string   `json:"erro"`
+	Codigo     int      `json:"codigo"`
+	CodigoErro string   `json:"codigo_erro"`
+	Detalhes   []string `json:"detalhes,omitempty"`
+}
+
+// Problema segue o formato application/problem+json (RFC 7807)
+type Problema struct {
+	Tipo       string      `json:"type"`
+	Titulo     string      `json:"title"`
+	Status     int         `json:"status"`
+	Detalhe    string      `json:"detail,omitempty"`
+	Instancia  string      `json:"instance,omitempty"`
+	CodigoErro string      `json:"codigo_erro"`
+	Erros      []ErroCampo `json:"errors,omitempty"`
 }
 
 type RespostaSucesso struct {
@@ -0,0 +1,205 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// MaximoGeneros limita quantos gêneros um filme pode ter
+const MaximoGeneros = 10
+
+// Genero é uma entrada da taxonomia de gêneros. Sinonimos são slugs
+// alternativos aceitos na entrada ("action" para "acao").
+type Genero struct {
+	ID          int      `json:"id"`
+	Nome        string   `json:"nome"`
+	Slug        string   `json:"slug"`
+	Sinonimos   []string `json:"sinonimos"`
+	TotalFilmes int      `json:"total_filmes"`
+}
+
+// GeneroResumo é a referência a um gênero embutida em um Filme
+type GeneroResumo struct {
+	Slug string `json:"slug"`
+	Nome string `json:"nome"`
+}
+
+// GeneroParaCriar estrutura para cadastro de gêneros; o slug vem do nome
+type GeneroParaCriar struct {
+	Nome      string   `json:"nome"`
+	Sinonimos []string `json:"sinonimos,omitempty"`
+}
+
+// RespostaGeneros lista a taxonomia com a contagem de filmes ativos
+type RespostaGeneros struct {
+	Generos []Genero `json:"generos"`
+}
+
+var removedorAcentos = strings.NewReplacer(
+	"á", "a", "à", "a", "â", "a", "ã", "a", "ä", "a",
+	"é", "e", "è", "e", "ê", "e", "ë", "e",
+	"í", "i", "ì", "i", "î", "i", "ï", "i",
+	"ó", "o", "ò", "o", "ô", "o", "õ", "o", "ö", "o",
+	"ú", "u", "ù", "u", "û", "u", "ü", "u",
+	"ç", "c", "ñ", "n",
+)
+
+// RemoverAcentos troca as letras acentuadas do português pela versão sem acento
+func RemoverAcentos(texto string) string {
+	return removedorAcentos.Replace(texto)
+}
+
+var separadorSlug = regexp.MustCompile(`[^a-z0-9]+`)
+
+// Slug normaliza um nome para comparação e URLs: "Ficção Científica"
+// vira "ficcao-cientifica". A migração 0009 aplica a mesma regra em SQL.
+func Slug(texto string) string {
+	texto = RemoverAcentos(strings.ToLower(strings.TrimSpace(texto)))
+	return strings.Trim(separadorSlug.ReplaceAllString(texto, "-"), "-")
+}
+
+var separadorGeneros = regexp.MustCompile(`\s*[,/]\s*`)
+
+// SepararGeneros interpreta o texto legado do campo genero ("Crime, Drama")
+// como uma lista de nomes
+func SepararGeneros(texto string) []string {
+	var nomes []string
+	for _, parte := range separadorGeneros.Split(strings.TrimSpace(texto), -1) {
+		if parte != "" {
+			nomes = append(nomes, parte)
+		}
+	}
+	return nomes
+}
+
+// TextoGeneros escolhe o texto do campo legado genero para os gêneros
+// resolvidos. O texto atual é mantido enquanto descrever exatamente esses
+// gêneros, na mesma ordem, para que "Crime, Drama" não vire "Crime" (nem
+// mude a busca) numa edição que não mexe nos gêneros; senão, vira o nome do
+// primeiro. resolver traduz um nome, slug ou sinônimo para o slug.
+func TextoGeneros(texto *string, generos []GeneroResumo, resolver func(nome string) (slug string, ok bool)) *string {
+	if len(generos) == 0 {
+		return nil
+	}
+	if texto != nil && descreveGeneros(*texto, generos, resolver) {
+		return texto
+	}
+	return &generos[0].Nome
+}
+
+// descreveGeneros indica se o texto resolve para os mesmos gêneros, na
+// mesma ordem, ignorando repetições
+func descreveGeneros(texto string, generos []GeneroResumo, resolver func(nome string) (string, bool)) bool {
+	var slugs []string
+	for _, nome := range SepararGeneros(texto) {
+		slug, ok := resolver(nome)
+		if !ok {
+			return false
+		}
+		if !slices.Contains(slugs, slug) {
+			slugs = append(slugs, slug)
+		}
+	}
+	if len(slugs) != len(generos) {
+		return false
+	}
+	for i, slug := range slugs {
+		if slug != generos[i].Slug {
+			return false
+		}
+	}
+	return true
+}
+
+// CatalogoGeneros resolve nomes, slugs e sinônimos para os gêneros cadastrados
+type CatalogoGeneros struct {
+	porChave map[string]Genero
+}
+
+// NovoCatalogoGeneros indexa os gêneros pelo slug e pelos sinônimos
+func NovoCatalogoGeneros(generos []Genero) *CatalogoGeneros {
+	catalogo := &CatalogoGeneros{porChave: make(map[string]Genero, len(generos))}
+	for _, genero := range generos {
+		for _, sinonimo := range genero.Sinonimos {
+			catalogo.porChave[sinonimo] = genero
+		}
+	}
+	// Slugs têm precedência sobre sinônimos em caso de colisão
+	for _, genero := range generos {
+		catalogo.porChave[genero.Slug] = genero
+	}
+	return catalogo
+}
+
+// Buscar encontra o gênero por nome, slug ou sinônimo
+func (c *CatalogoGeneros) Buscar(texto string) (Genero, bool) {
+	genero, ok := c.porChave[Slug(texto)]
+	return genero, ok
+}
+
+// Slug resolve o texto como Buscar e devolve só o slug canônico
+func (c *CatalogoGeneros) Slug(texto string) (string, bool) {
+	genero, ok := c.Buscar(texto)
+	return genero.Slug, ok
+}
+
+// ValidarGenero valida os dados de um gênero antes de salvar
+func ValidarGenero(genero *GeneroParaCriar) ErrosValidacao {
+	var erros ErrosValidacao
+
+	genero.Nome = strings.TrimSpace(genero.Nome)
+	switch {
+	case genero.Nome == "":
+		erros.adicionar("nome", CodigoCampoObrigatorio, "nome é obrigatório")
+	case len(genero.Nome) > 100:
+		erros.adicionar("nome", CodigoTamanhoMaximo, "nome deve ter no máximo 100 caracteres")
+	case Slug(genero.Nome) == "":
+		erros.adicionar("nome", CodigoForaDoIntervalo, "nome deve conter letras ou números")
+	}
+
+	sinonimos := []string{}
+	for _, sinonimo := range genero.Sinonimos {
+		if slug := Slug(sinonimo); slug != "" && slug != Slug(genero.Nome) {
+			sinonimos = append(sinonimos, slug)
+		}
+	}
+	genero.Sinonimos = sinonimos
+
+	return erros
+}
+
+// validarGeneros resolve os gêneros do filme no catálogo. A lista generos
+// tem precedência sobre o texto legado genero; o resultado substitui a lista
+// pelos slugs e o texto segundo TextoGeneros.
+func validarGeneros(erros *ErrosValidacao, filme *FilmeParaCriar, catalogo *CatalogoGeneros) {
+	campo, nomes := "generos", filme.Generos
+	if len(nomes) == 0 && filme.Genero != nil {
+		campo, nomes = "genero", SepararGeneros(*filme.Genero)
+	}
+	if len(nomes) > MaximoGeneros {
+		erros.adicionar(campo, CodigoTamanhoMaximo, fmt.Sprintf("um filme pode ter no máximo %d gêneros", MaximoGeneros))
+		return
+	}
+
+	var slugs []string
+	var generos []GeneroResumo
+	vistos := make(map[string]bool)
+	for _, nome := range nomes {
+		genero, ok := catalogo.Buscar(nome)
+		if !ok {
+			erros.adicionar(campo, CodigoDesconhecido, fmt.Sprintf("gênero desconhecido: %q (veja GET /generos)", nome))
+			continue
+		}
+		if vistos[genero.Slug] {
+			continue
+		}
+		vistos[genero.Slug] = true
+		slugs = append(slugs, genero.Slug)
+		generos = append(generos, GeneroResumo{Slug: genero.Slug, Nome: genero.Nome})
+	}
+
+	filme.Generos = slugs
+	filme.Genero = TextoGeneros(filme.Genero, generos, catalogo.Slug)
+}
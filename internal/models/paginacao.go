@@ -0,0 +1,140 @@
+package models
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// Limites de paginação da listagem
+const (
+	LimitePadrao = 20
+	LimiteMaximo = 100
+)
+
+// CamposOrdenacao lista as colunas de FilmeResumo aceitas no parâmetro sort
+var CamposOrdenacao = []string{"id", "titulo", "ano_lancamento", "genero", "diretor", "avaliacao"}
+
+// FiltroFilmes reúne paginação, filtros e ordenação da listagem de filmes
+type FiltroFilmes struct {
+	Pagina       int
+	Limite       int
+	Deslocamento int
+	Cursor       *CursorFilmes
+
+	// Generos filtra por slugs; com TodosGeneros o filme precisa ter todos,
+	// senão basta um deles
+	Generos         []string
+	TodosGeneros    bool
+	Diretor         string
+	AnoMinimo       *int
+	AnoMaximo       *int
+	AvaliacaoMinima *float64
+	AvaliacaoMaxima *float64
+
+	Ordenacao   string
+	Descendente bool
+}
+
+// NovoFiltroFilmes retorna um filtro com os valores padrão
+func NovoFiltroFilmes() *FiltroFilmes {
+	return &FiltroFilmes{
+		Pagina:    1,
+		Limite:    LimitePadrao,
+		Ordenacao: "id",
+	}
+}
+
+// CalcularDeslocamento define o OFFSET correspondente à página (zero no modo cursor)
+func (f *FiltroFilmes) CalcularDeslocamento() {
+	f.Deslocamento = 0
+	if f.Cursor == nil && f.Pagina > 1 {
+		f.Deslocamento = (f.Pagina - 1) * f.Limite
+	}
+}
+
+// CursorFilmes marca a posição do último item retornado na paginação por cursor
+type CursorFilmes struct {
+	Campo       string `json:"c"`
+	Descendente bool   `json:"d,omitempty"`
+	Valor       string `json:"v"`
+	ID          int    `json:"id"`
+}
+
+// NovoCursor cria o cursor que aponta para depois do filme informado
+func NovoCursor(filme FilmeResumo, campo string, descendente bool) *CursorFilmes {
+	return &CursorFilmes{
+		Campo:       campo,
+		Descendente: descendente,
+		Valor:       ValorOrdenacao(filme, campo),
+		ID:          filme.ID,
+	}
+}
+
+// Codificar serializa o cursor em um token opaco seguro para URLs
+func (c *CursorFilmes) Codificar() string {
+	dados, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(dados)
+}
+
+// DecodificarCursor interpreta um token gerado por Codificar
+func DecodificarCursor(token string) (*CursorFilmes, error) {
+	dados, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, fmt.Errorf("cursor inválido")
+	}
+
+	var cursor CursorFilmes
+	if err := json.Unmarshal(dados, &cursor); err != nil {
+		return nil, fmt.Errorf("cursor inválido")
+	}
+
+	if !CampoOrdenacaoValido(cursor.Campo) {
+		return nil, fmt.Errorf("cursor inválido")
+	}
+
+	return &cursor, nil
+}
+
+// CampoOrdenacaoValido indica se o campo pode ser usado na ordenação
+func CampoOrdenacaoValido(campo string) bool {
+	for _, c := range CamposOrdenacao {
+		if c == campo {
+			return true
+		}
+	}
+	return false
+}
+
+// ValorOrdenacao retorna o valor textual do campo de ordenação de um filme;
+// filmes sem avaliação ficam com o valor vazio
+func ValorOrdenacao(filme FilmeResumo, campo string) string {
+	switch campo {
+	case "titulo":
+		return filme.Titulo
+	case "ano_lancamento":
+		return strconv.Itoa(filme.AnoLancamento)
+	case "genero":
+		return filme.Genero
+	case "diretor":
+		return filme.Diretor
+	case "avaliacao":
+		if filme.Avaliacao == nil {
+			return ""
+		}
+		return strconv.FormatFloat(*filme.Avaliacao, 'f', -1, 64)
+	default:
+		return strconv.Itoa(filme.ID)
+	}
+}
+
+// Paginacao contém os metadados de página devolvidos na listagem
+type Paginacao struct {
+	Pagina        int    `json:"pagina,omitempty"`
+	Limite        int    `json:"limite"`
+	TotalPaginas  int    `json:"total_paginas"`
+	ProximoCursor string `json:"proximo_cursor,omitempty"`
+	LinkProxima   string `json:"proxima,omitempty"`
+	LinkAnterior  string `json:"anterior,omitempty"`
+}
@@ -0,0 +1,133 @@
+package models
+
+import (
+	"regexp"
+	"strings"
+	"time"
+)
+
+// MaximoDiretores limita quantos diretores um filme pode listar
+const MaximoDiretores = 20
+
+// Pessoa é alguém que participa de filmes (por enquanto, como diretor)
+type Pessoa struct {
+	ID              int       `json:"id"`
+	Nome            string    `json:"nome"`
+	DataCriacao     time.Time `json:"data_criacao"`
+	DataAtualizacao time.Time `json:"data_atualizacao"`
+}
+
+// PessoaResumo é a referência a uma pessoa embutida em um Filme
+type PessoaResumo struct {
+	ID   int    `json:"id"`
+	Nome string `json:"nome"`
+}
+
+// PessoaParaCriar estrutura para criação e substituição de pessoas
+type PessoaParaCriar struct {
+	Nome string `json:"nome"`
+}
+
+// FiltroPessoas pagina e filtra a listagem de pessoas por trecho do nome;
+// ApenasDiretores restringe às pessoas que dirigem algum filme ativo
+type FiltroPessoas struct {
+	Nome            string
+	ApenasDiretores bool
+	Limite          int
+	Deslocamento    int
+}
+
+// RespostaPessoas lista uma página de pessoas
+type RespostaPessoas struct {
+	Pessoas []Pessoa `json:"pessoas"`
+	Pagina  int      `json:"pagina"`
+	Limite  int      `json:"limite"`
+	HaMais  bool     `json:"ha_mais"`
+}
+
+// RespostaFilmesPessoa lista os filmes ligados a uma pessoa
+type RespostaFilmesPessoa struct {
+	Pessoa Pessoa        `json:"pessoa"`
+	Filmes []FilmeResumo `json:"filmes"`
+}
+
+// ValidarPessoa valida os dados de uma pessoa antes de salvar
+func ValidarPessoa(pessoa *PessoaParaCriar) ErrosValidacao {
+	var erros ErrosValidacao
+
+	pessoa.Nome = strings.TrimSpace(pessoa.Nome)
+	if pessoa.Nome == "" {
+		erros.adicionar("nome", CodigoCampoObrigatorio, "nome é obrigatório")
+	} else if len(pessoa.Nome) > 255 {
+		erros.adicionar("nome", CodigoTamanhoMaximo, "nome deve ter no máximo 255 caracteres")
+	}
+
+	return erros
+}
+
+// separadorDiretores reconhece as formas usadas no campo livre "diretor":
+// vírgula, "&", " e " e " and "
+var separadorDiretores = regexp.MustCompile(`\s*,\s*|\s*&\s*|\s+e\s+|\s+and\s+`)
+
+// SepararDiretores interpreta o texto legado do campo diretor como uma
+// lista de nomes. Um primeiro nome isolado herda o sobrenome do último
+// ("Anthony e Joe Russo" vira "Anthony Russo" e "Joe Russo"). A migração
+// 0007 aplica a mesma regra aos dados existentes.
+func SepararDiretores(texto string) []string {
+	partes := separadorDiretores.Split(strings.TrimSpace(texto), -1)
+
+	var nomes []string
+	for _, parte := range partes {
+		if parte = strings.TrimSpace(parte); parte != "" {
+			nomes = append(nomes, parte)
+		}
+	}
+	if len(nomes) < 2 {
+		return nomes
+	}
+
+	ultimo := strings.Fields(nomes[len(nomes)-1])
+	if len(ultimo) > 1 {
+		sobrenome := ultimo[len(ultimo)-1]
+		for i := range nomes[:len(nomes)-1] {
+			if !strings.Contains(nomes[i], " ") {
+				nomes[i] += " " + sobrenome
+			}
+		}
+	}
+	return nomes
+}
+
+// JuntarNomes monta o texto exibido no campo legado diretor ("A, B e C")
+func JuntarNomes(pessoas []PessoaResumo) string {
+	nomes := make([]string, len(pessoas))
+	for i, pessoa := range pessoas {
+		nomes[i] = pessoa.Nome
+	}
+	if len(nomes) <= 1 {
+		return strings.Join(nomes, "")
+	}
+	return strings.Join(nomes[:len(nomes)-1], ", ") + " e " + nomes[len(nomes)-1]
+}
+
+// TextoDiretores escolhe o texto do campo legado diretor para as pessoas
+// informadas. O texto atual é mantido enquanto descrever exatamente essas
+// pessoas, na mesma ordem, para que "Anthony e Joe Russo" não vire "Anthony
+// Russo e Joe Russo" (nem mude a busca) numa edição que não mexe nos
+// diretores; senão, é montado com JuntarNomes.
+func TextoDiretores(texto *string, pessoas []PessoaResumo) string {
+	if texto == nil {
+		return JuntarNomes(pessoas)
+	}
+
+	nomes := SepararDiretores(*texto)
+	if len(nomes) != len(pessoas) {
+		return JuntarNomes(pessoas)
+	}
+	for i, nome := range nomes {
+		if !strings.EqualFold(nome, pessoas[i].Nome) {
+			return JuntarNomes(pessoas)
+		}
+	}
+	return *texto
+}
@@ -0,0 +1,37 @@
+package models
+
+import "api-filmes/internal/versao"
+
+// Status possíveis de uma verificação de saúde
+const (
+	StatusSaudavel     = "ok"
+	StatusIndisponivel = "indisponivel"
+)
+
+// RespostaSaude é o corpo das sondas /health, /health/live e /health/ready
+type RespostaSaude struct {
+	Status      string                      `json:"status"`
+	Timestamp   string                      `json:"timestamp"`
+	Servico     string                      `json:"servico"`
+	Build       versao.InformacoesBuild     `json:"build"`
+	Componentes map[string]StatusComponente `json:"componentes,omitempty"`
+}
+
+// StatusComponente descreve a saúde de uma dependência
+type StatusComponente struct {
+	Status     string            `json:"status"`
+	LatenciaMs float64           `json:"latencia_ms,omitempty"`
+	Erro       string            `json:"erro,omitempty"`
+	Pendentes  *int              `json:"pendentes,omitempty"`
+	Pool       *EstatisticasPool `json:"pool,omitempty"`
+}
+
+// EstatisticasPool resume sql.DBStats para a sonda de prontidão
+type EstatisticasPool struct {
+	MaximoConexoes  int     `json:"maximo_conexoes"`
+	ConexoesAbertas int     `json:"conexoes_abertas"`
+	EmUso           int     `json:"em_uso"`
+	Ociosas         int     `json:"ociosas"`
+	Esperas         int64   `json:"esperas"`
+	TempoEsperaMs   float64 `json:"tempo_espera_ms"`
+}
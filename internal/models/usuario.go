@@ -0,0 +1,121 @@
+package models
+
+import (
+	"net/mail"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Limites de tamanho da senha; o máximo evita gastar argon2 com corpos enormes
+const (
+	TamanhoMinimoSenha = 8
+	TamanhoMaximoSenha = 128
+)
+
+// Usuario é uma conta da API. O hash da senha nunca sai nas respostas.
+type Usuario struct {
+	ID              int        `json:"id"`
+	Nome            string     `json:"nome"`
+	Email           string     `json:"email"`
+	SenhaHash       string     `json:"-"`
+	UltimoLogin     *time.Time `json:"ultimo_login,omitempty"`
+	DataCriacao     time.Time  `json:"data_criacao"`
+	DataAtualizacao time.Time  `json:"data_atualizacao"`
+}
+
+// TentativasLogin é o estado de bloqueio de um email. É mantido também para
+// emails sem conta, para que 401 e 423 não revelem quais estão cadastrados.
+// AtualizadoEm é o momento da última falha; falhas mais antigas que a janela
+// de bloqueio são esquecidas e a linha pode ser expurgada.
+type TentativasLogin struct {
+	Email        string
+	Falhas       int
+	BloqueadoAte *time.Time
+	AtualizadoEm time.Time
+}
+
+// Bloqueado indica se o email está bloqueado no instante informado
+func (t *TentativasLogin) Bloqueado(agora time.Time) bool {
+	return t.BloqueadoAte != nil && agora.Before(*t.BloqueadoAte)
+}
+
+// UsuarioParaRegistrar estrutura do cadastro em POST /auth/registrar
+type UsuarioParaRegistrar struct {
+	Nome  string `json:"nome"`
+	Email string `json:"email"`
+	Senha string `json:"senha"`
+}
+
+// Credenciais estrutura do login em POST /auth/login
+type Credenciais struct {
+	Email string `json:"email"`
+	Senha string `json:"senha"`
+}
+
+// Sessao é um login ativo, identificada pelo hash do token entregue ao cliente
+type Sessao struct {
+	TokenHash   string
+	UsuarioID   int
+	DataCriacao time.Time
+	ExpiraEm    time.Time
+}
+
+// RespostaLogin entrega o par de tokens: o de acesso (JWT de curta duração)
+// vai em Authorization: Bearer; o de renovação só serve para /auth/renovar
+// e /auth/logout
+type RespostaLogin struct {
+	TokenAcesso       string    `json:"token_acesso"`
+	TipoToken         string    `json:"tipo_token"`
+	ExpiraEm          time.Time `json:"expira_em"`
+	TokenRenovacao    string    `json:"token_renovacao"`
+	RenovacaoExpiraEm time.Time `json:"renovacao_expira_em"`
+	Usuario           *Usuario  `json:"usuario"`
+}
+
+// PedidoRenovacao estrutura do corpo de POST /auth/renovar e /auth/logout
+type PedidoRenovacao struct {
+	TokenRenovacao string `json:"token_renovacao"`
+}
+
+// NormalizarEmail remove espaços e usa minúsculas, a forma comparada no banco
+func NormalizarEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// ValidarRegistro valida o cadastro de um usuário e normaliza nome e email
+func ValidarRegistro(usuario *UsuarioParaRegistrar) ErrosValidacao {
+	var erros ErrosValidacao
+
+	usuario.Nome = strings.TrimSpace(usuario.Nome)
+	if usuario.Nome == "" {
+		erros.adicionar("nome", CodigoCampoObrigatorio, "nome é obrigatório")
+	} else if len(usuario.Nome) > 100 {
+		erros.adicionar("nome", CodigoTamanhoMaximo, "nome deve ter no máximo 100 caracteres")
+	}
+
+	usuario.Email = NormalizarEmail(usuario.Email)
+	switch {
+	case usuario.Email == "":
+		erros.adicionar("email", CodigoCampoObrigatorio, "email é obrigatório")
+	case len(usuario.Email) > 255:
+		erros.adicionar("email", CodigoTamanhoMaximo, "email deve ter no máximo 255 caracteres")
+	case !emailValido(usuario.Email):
+		erros.adicionar("email", CodigoFormatoInvalido, "email inválido")
+	}
+
+	switch tamanho := utf8.RuneCountInString(usuario.Senha); {
+	case tamanho < TamanhoMinimoSenha:
+		erros.adicionar("senha", CodigoValorMinimo, "senha deve ter pelo menos 8 caracteres")
+	case tamanho > TamanhoMaximoSenha:
+		erros.adicionar("senha", CodigoTamanhoMaximo, "senha deve ter no máximo 128 caracteres")
+	}
+
+	return erros
+}
+
+// emailValido aceita apenas o endereço puro, sem nome de exibição
+func emailValido(email string) bool {
+	endereco, err := mail.ParseAddress(email)
+	return err == nil && endereco.Address == email && strings.Contains(email[strings.LastIndex(email, "@"):], ".")
+}
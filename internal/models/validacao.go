@@ -6,81 +6,113 @@ import (
 	"time"
 )
 
-// ValidarFilme valida os dados de um filme antes de salvar
-func ValidarFilme(filme *FilmeParaCriar) []string {
-	var erros []string
+// Códigos estáveis de erro de validação por campo
+const (
+	CodigoCampoObrigatorio = "obrigatorio"
+	CodigoTamanhoMaximo    = "tamanho_maximo"
+	CodigoValorMinimo      = "valor_minimo"
+	CodigoValorMaximo      = "valor_maximo"
+	CodigoForaDoIntervalo  = "fora_do_intervalo"
+	CodigoDuplicado        = "duplicado"
+	CodigoDesconhecido     = "desconhecido"
+	CodigoFormatoInvalido  = "formato_invalido"
+)
+
+// ErroCampo descreve a falha de validação de um campo específico
+type ErroCampo struct {
+	Campo    string `json:"campo"`
+	Codigo   string `json:"codigo"`
+	Mensagem string `json:"mensagem"`
+}
+
+// ErrosValidacao acumula os erros encontrados em uma validação
+type ErrosValidacao []ErroCampo
+
+// adicionar registra um novo erro de campo
+func (e *ErrosValidacao) adicionar(campo, codigo, mensagem string) {
+	*e = append(*e, ErroCampo{Campo: campo, Codigo: codigo, Mensagem: mensagem})
+}
+
+// Mensagens retorna apenas os textos, no formato legado de "detalhes"
+func (e ErrosValidacao) Mensagens() []string {
+	mensagens := make([]string, 0, len(e))
+	for _, erro := range e {
+		mensagens = append(mensagens, erro.Mensagem)
+	}
+	return mensagens
+}
+
+// ValidarFilme valida os dados de um filme antes de salvar. Com um
+// catálogo, gêneros desconhecidos são rejeitados e os conhecidos passam à
+// forma canônica; sem ele (nil), só o tamanho do texto é verificado.
+func ValidarFilme(filme *FilmeParaCriar, generos *CatalogoGeneros) ErrosValidacao {
+	var erros ErrosValidacao
 
 	// Validar título
 	if strings.TrimSpace(filme.Titulo) == "" {
-		erros = append(erros, "título é obrigatório")
+		erros.adicionar("titulo", CodigoCampoObrigatorio, "título é obrigatório")
 	} else if len(filme.Titulo) > 255 {
-		erros = append(erros, "título deve ter no máximo 255 caracteres")
+		erros.adicionar("titulo", CodigoTamanhoMaximo, "título deve ter no máximo 255 caracteres")
 	}
 
 	// Validar ano de lançamento
-	anoAtual := time.Now().Year()
-	if filme.AnoLancamento < 1888 { // Primeiro filme da história
-		erros = append(erros, "ano de lançamento deve ser maior que 1887")
-	} else if filme.AnoLancamento > anoAtual+5 { // Máximo 5 anos no futuro
-		erros = append(erros, fmt.Sprintf("ano de lançamento não pode ser maior que %d", anoAtual+5))
-	}
+	validarAnoLancamento(&erros, filme.AnoLancamento)
 
 	// Validar duração
 	if filme.DuracaoMinutos != nil && *filme.DuracaoMinutos <= 0 {
-		erros = append(erros, "duração deve ser maior que 0 minutos")
+		erros.adicionar("duracao_minutos", CodigoValorMinimo, "duração deve ser maior que 0 minutos")
 	}
 
 	// Validar gênero
 	if filme.Genero != nil && len(*filme.Genero) > 100 {
-		erros = append(erros, "gênero deve ter no máximo 100 caracteres")
+		erros.adicionar("genero", CodigoTamanhoMaximo, "gênero deve ter no máximo 100 caracteres")
+	} else if generos != nil {
+		validarGeneros(&erros, filme, generos)
 	}
 
 	// Validar diretor
 	if filme.Diretor != nil && len(*filme.Diretor) > 255 {
-		erros = append(erros, "nome do diretor deve ter no máximo 255 caracteres")
+		erros.adicionar("diretor", CodigoTamanhoMaximo, "nome do diretor deve ter no máximo 255 caracteres")
 	}
+	validarDiretores(&erros, filme.Diretores)
 
 	// Validar avaliação
 	if filme.Avaliacao != nil {
 		if *filme.Avaliacao < 0 || *filme.Avaliacao > 10 {
-			erros = append(erros, "avaliação deve estar entre 0 e 10")
+			erros.adicionar("avaliacao", CodigoForaDoIntervalo, "avaliação deve estar entre 0 e 10")
 		}
 	}
 
 	return erros
 }
 
-// ValidarFilmeParaAtualizar valida dados para atualização (campos opcionais)
-func ValidarFilmeParaAtualizar(filme *FilmeParaAtualizar) []string {
-	var erros []string
-
-	// Validar título (se fornecido)
-	if filme.Titulo != nil {
-		if strings.TrimSpace(*filme.Titulo) == "" {
-			erros = append(erros, "título não pode estar vazio")
-		} else if len(*filme.Titulo) > 255 {
-			erros = append(erros, "título deve ter no máximo 255 caracteres")
-		}
+// validarDiretores exige IDs positivos, sem repetição e até MaximoDiretores
+func validarDiretores(erros *ErrosValidacao, ids []int) {
+	if len(ids) > MaximoDiretores {
+		erros.adicionar("diretores", CodigoTamanhoMaximo, fmt.Sprintf("um filme pode ter no máximo %d diretores", MaximoDiretores))
+		return
 	}
 
-	// Validar ano (se fornecido)
-	if filme.AnoLancamento != nil {
-		anoAtual := time.Now().Year()
-		if *filme.AnoLancamento < 1888 {
-			erros = append(erros, "ano de lançamento deve ser maior que 1887")
-		} else if *filme.AnoLancamento > anoAtual+5 {
-			erros = append(erros, fmt.Sprintf("ano de lançamento não pode ser maior que %d", anoAtual+5))
+	vistos := make(map[int]bool, len(ids))
+	for _, id := range ids {
+		if id < 1 {
+			erros.adicionar("diretores", CodigoValorMinimo, "IDs de diretores devem ser positivos")
+			return
 		}
+		if vistos[id] {
+			erros.adicionar("diretores", CodigoDuplicado, fmt.Sprintf("diretor %d informado mais de uma vez", id))
+			return
+		}
+		vistos[id] = true
 	}
+}
 
-	// Outras validações similares...
-	if filme.DuracaoMinutos != nil && *filme.DuracaoMinutos <= 0 {
-		erros = append(erros, "duração deve ser maior que 0 minutos")
-	}
-
-	if filme.Avaliacao != nil && (*filme.Avaliacao < 0 || *filme.Avaliacao > 10) {
-		erros = append(erros, "avaliação deve estar entre 0 e 10")
+// validarAnoLancamento aplica as regras do ano de lançamento
+func validarAnoLancamento(erros *ErrosValidacao, ano int) {
+	anoAtual := time.Now().Year()
+	if ano < 1888 { // Primeiro filme da história
+		erros.adicionar("ano_lancamento", CodigoValorMinimo, "ano de lançamento deve ser maior que 1887")
+	} else if ano > anoAtual+5 { // Máximo 5 anos no futuro
+		erros.adicionar("ano_lancamento", CodigoValorMaximo, fmt.Sprintf("ano de lançamento não pode ser maior que %d", anoAtual+5))
 	}
-
-	return erros
 }
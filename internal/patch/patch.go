@@ -0,0 +1,389 @@
+// Package patch aplica JSON Merge Patch (RFC 7396) e JSON Patch (RFC 6902)
+// sobre documentos JSON genéricos.
+package patch
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Media types aceitos no cabeçalho Content-Type do PATCH
+const (
+	TipoMergePatch = "application/merge-patch+json"
+	TipoJSONPatch  = "application/json-patch+json"
+)
+
+// Erros retornados pela aplicação de patches
+var (
+	// ErrPatchInvalido indica um patch malformado (JSON inválido, operação desconhecida...)
+	ErrPatchInvalido = errors.New("patch inválido")
+	// ErrCaminhoInexistente indica um caminho que não existe no documento
+	ErrCaminhoInexistente = errors.New("caminho inexistente no documento")
+	// ErrTesteFalhou indica que uma operação "test" não foi satisfeita
+	ErrTesteFalhou = errors.New("operação test falhou")
+)
+
+// AplicarMergePatch aplica um JSON Merge Patch: membros com null são
+// removidos, objetos são mesclados recursivamente e o resto substitui
+func AplicarMergePatch(documento, patch []byte) ([]byte, error) {
+	var alvo, alteracoes any
+	if err := decodificar(documento, &alvo); err != nil {
+		return nil, fmt.Errorf("documento inválido: %w", err)
+	}
+	if err := decodificar(patch, &alteracoes); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrPatchInvalido, err)
+	}
+
+	return json.Marshal(mesclar(alvo, alteracoes))
+}
+
+func mesclar(alvo, patch any) any {
+	objetoPatch, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+
+	objetoAlvo, ok := alvo.(map[string]any)
+	if !ok {
+		objetoAlvo = map[string]any{}
+	}
+
+	for chave, valor := range objetoPatch {
+		if valor == nil {
+			delete(objetoAlvo, chave)
+			continue
+		}
+		objetoAlvo[chave] = mesclar(objetoAlvo[chave], valor)
+	}
+	return objetoAlvo
+}
+
+// Operacao é um item de um documento JSON Patch. Value fica vazio quando
+// o membro não existe; "value": null chega como o literal null, um valor
+// válido para add, replace e test.
+type Operacao struct {
+	Op    string          `json:"op"`
+	Path  *string         `json:"path"`
+	From  *string         `json:"from,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// AplicarJSONPatch aplica a lista de operações em ordem; se qualquer uma
+// falhar, nenhuma alteração é devolvida
+func AplicarJSONPatch(documento, patch []byte) ([]byte, error) {
+	var alvo any
+	if err := decodificar(documento, &alvo); err != nil {
+		return nil, fmt.Errorf("documento inválido: %w", err)
+	}
+
+	var operacoes []Operacao
+	if err := json.Unmarshal(patch, &operacoes); err != nil {
+		return nil, fmt.Errorf("%w: esperado um array de operações: %v", ErrPatchInvalido, err)
+	}
+
+	for i, operacao := range operacoes {
+		var err error
+		alvo, err = aplicarOperacao(alvo, operacao)
+		if err != nil {
+			return nil, fmt.Errorf("operação %d (%s): %w", i, operacao.Op, err)
+		}
+	}
+
+	return json.Marshal(alvo)
+}
+
+func aplicarOperacao(documento any, operacao Operacao) (any, error) {
+	if operacao.Path == nil {
+		return nil, fmt.Errorf("%w: campo path ausente", ErrPatchInvalido)
+	}
+	caminho, err := interpretarPonteiro(*operacao.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch operacao.Op {
+	case "add", "replace", "test":
+		valor, err := valorDaOperacao(operacao)
+		if err != nil {
+			return nil, err
+		}
+		switch operacao.Op {
+		case "add":
+			return adicionar(documento, caminho, valor)
+		case "replace":
+			if _, err := obter(documento, caminho); err != nil {
+				return nil, err
+			}
+			return substituir(documento, caminho, valor)
+		default:
+			atual, err := obter(documento, caminho)
+			if err != nil {
+				return nil, err
+			}
+			if !iguais(atual, valor) {
+				return nil, fmt.Errorf("%w: valor em %s difere do esperado", ErrTesteFalhou, *operacao.Path)
+			}
+			return documento, nil
+		}
+
+	case "remove":
+		if _, err := obter(documento, caminho); err != nil {
+			return nil, err
+		}
+		resultado, _ := remover(documento, caminho)
+		return resultado, nil
+
+	case "move", "copy":
+		if operacao.From == nil {
+			return nil, fmt.Errorf("%w: campo from ausente", ErrPatchInvalido)
+		}
+		origem, err := interpretarPonteiro(*operacao.From)
+		if err != nil {
+			return nil, err
+		}
+		valor, err := obter(documento, origem)
+		if err != nil {
+			return nil, err
+		}
+		if operacao.Op == "move" {
+			if prefixo(origem, caminho) && len(origem) < len(caminho) {
+				return nil, fmt.Errorf("%w: não é possível mover um valor para dentro de si mesmo", ErrPatchInvalido)
+			}
+			documento, _ = remover(documento, origem)
+		} else {
+			valor = copiar(valor)
+		}
+		return adicionar(documento, caminho, valor)
+
+	default:
+		return nil, fmt.Errorf("%w: operação desconhecida %q", ErrPatchInvalido, operacao.Op)
+	}
+}
+
+// remover tira o valor do caminho, devolvendo o documento e o valor removido
+func remover(documento any, caminho []string) (any, any) {
+	if len(caminho) == 0 {
+		return nil, documento
+	}
+
+	pai, _ := obter(documento, caminho[:len(caminho)-1])
+	ultimo := caminho[len(caminho)-1]
+
+	switch container := pai.(type) {
+	case map[string]any:
+		valor := container[ultimo]
+		delete(container, ultimo)
+		return documento, valor
+	case []any:
+		indice, _ := strconv.Atoi(ultimo)
+		valor := container[indice]
+		novo := append(container[:indice:indice], container[indice+1:]...)
+		documento, _ = substituir(documento, caminho[:len(caminho)-1], novo)
+		return documento, valor
+	}
+	return documento, nil
+}
+
+// adicionar insere o valor no caminho (substitui membros de objeto e
+// insere em arrays, com "-" significando o fim)
+func adicionar(documento any, caminho []string, valor any) (any, error) {
+	if len(caminho) == 0 {
+		return valor, nil
+	}
+
+	pai, err := obter(documento, caminho[:len(caminho)-1])
+	if err != nil {
+		return nil, err
+	}
+	ultimo := caminho[len(caminho)-1]
+
+	switch container := pai.(type) {
+	case map[string]any:
+		container[ultimo] = valor
+		return documento, nil
+	case []any:
+		indice := len(container)
+		if ultimo != "-" {
+			indice, err = indiceArray(ultimo, len(container)+1)
+			if err != nil {
+				return nil, err
+			}
+		}
+		novo := make([]any, 0, len(container)+1)
+		novo = append(novo, container[:indice]...)
+		novo = append(novo, valor)
+		novo = append(novo, container[indice:]...)
+		return substituir(documento, caminho[:len(caminho)-1], novo)
+	default:
+		return nil, fmt.Errorf("%w: /%s", ErrCaminhoInexistente, strings.Join(caminho, "/"))
+	}
+}
+
+// substituir troca o valor no caminho (usado para arrays, que mudam de tamanho)
+func substituir(documento any, caminho []string, valor any) (any, error) {
+	if len(caminho) == 0 {
+		return valor, nil
+	}
+
+	pai, err := obter(documento, caminho[:len(caminho)-1])
+	if err != nil {
+		return nil, err
+	}
+	ultimo := caminho[len(caminho)-1]
+
+	switch container := pai.(type) {
+	case map[string]any:
+		container[ultimo] = valor
+	case []any:
+		indice, err := indiceArray(ultimo, len(container))
+		if err != nil {
+			return nil, err
+		}
+		container[indice] = valor
+	}
+	return documento, nil
+}
+
+// obter percorre o documento seguindo o ponteiro JSON já interpretado
+func obter(documento any, caminho []string) (any, error) {
+	atual := documento
+	for i, parte := range caminho {
+		switch container := atual.(type) {
+		case map[string]any:
+			valor, ok := container[parte]
+			if !ok {
+				return nil, fmt.Errorf("%w: /%s", ErrCaminhoInexistente, strings.Join(caminho[:i+1], "/"))
+			}
+			atual = valor
+		case []any:
+			indice, err := indiceArray(parte, len(container))
+			if err != nil {
+				return nil, err
+			}
+			atual = container[indice]
+		default:
+			return nil, fmt.Errorf("%w: /%s", ErrCaminhoInexistente, strings.Join(caminho[:i+1], "/"))
+		}
+	}
+	return atual, nil
+}
+
+func indiceArray(parte string, limite int) (int, error) {
+	indice, err := strconv.Atoi(parte)
+	if err != nil || indice < 0 || indice >= limite || (len(parte) > 1 && parte[0] == '0') {
+		return 0, fmt.Errorf("%w: índice de array %q", ErrCaminhoInexistente, parte)
+	}
+	return indice, nil
+}
+
+// interpretarPonteiro converte "/a/b~1c" em ["a", "b/c"] (RFC 6901)
+func interpretarPonteiro(ponteiro string) ([]string, error) {
+	if ponteiro == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(ponteiro, "/") {
+		return nil, fmt.Errorf("%w: ponteiro JSON %q deve começar com /", ErrPatchInvalido, ponteiro)
+	}
+
+	partes := strings.Split(ponteiro[1:], "/")
+	for i, parte := range partes {
+		partes[i] = strings.ReplaceAll(strings.ReplaceAll(parte, "~1", "/"), "~0", "~")
+	}
+	return partes, nil
+}
+
+func prefixo(a, b []string) bool {
+	if len(a) > len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func valorDaOperacao(operacao Operacao) (any, error) {
+	if operacao.Value == nil {
+		return nil, fmt.Errorf("%w: campo value ausente", ErrPatchInvalido)
+	}
+	var valor any
+	if err := decodificar(operacao.Value, &valor); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrPatchInvalido, err)
+	}
+	return valor, nil
+}
+
+// copiar duplica objetos e arrays para que "copy" não compartilhe referências
+func copiar(valor any) any {
+	switch v := valor.(type) {
+	case map[string]any:
+		copia := make(map[string]any, len(v))
+		for chave, item := range v {
+			copia[chave] = copiar(item)
+		}
+		return copia
+	case []any:
+		copia := make([]any, len(v))
+		for i, item := range v {
+			copia[i] = copiar(item)
+		}
+		return copia
+	default:
+		return v
+	}
+}
+
+// iguais compara valores JSON como exige o "test": números pelo valor
+// numérico (8.5 == 8.50), objetos sem considerar a ordem dos membros
+func iguais(a, b any) bool {
+	switch va := a.(type) {
+	case json.Number:
+		vb, ok := b.(json.Number)
+		if !ok {
+			return false
+		}
+		fa, errA := va.Float64()
+		fb, errB := vb.Float64()
+		return errA == nil && errB == nil && fa == fb
+	case map[string]any:
+		vb, ok := b.(map[string]any)
+		if !ok || len(va) != len(vb) {
+			return false
+		}
+		for chave, item := range va {
+			outro, existe := vb[chave]
+			if !existe || !iguais(item, outro) {
+				return false
+			}
+		}
+		return true
+	case []any:
+		vb, ok := b.([]any)
+		if !ok || len(va) != len(vb) {
+			return false
+		}
+		for i := range va {
+			if !iguais(va[i], vb[i]) {
+				return false
+			}
+		}
+		return true
+	default:
+		return reflect.DeepEqual(a, b)
+	}
+}
+
+// decodificar lê JSON preservando números como json.Number, para que
+// inteiros grandes não percam precisão ao serem regravados
+func decodificar(dados []byte, destino *any) error {
+	decodificador := json.NewDecoder(bytes.NewReader(dados))
+	decodificador.UseNumber()
+	return decodificador.Decode(destino)
+}
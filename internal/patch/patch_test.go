@@ -0,0 +1,184 @@
+package patch
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// mesmoJSON compara dois documentos ignorando a ordem dos membros
+func mesmoJSON(t *testing.T, obtido []byte, esperado string) {
+	t.Helper()
+	var a, b any
+	if err := json.Unmarshal(obtido, &a); err != nil {
+		t.Fatalf("resultado não é JSON: %v (%s)", err, obtido)
+	}
+	if err := json.Unmarshal([]byte(esperado), &b); err != nil {
+		t.Fatalf("JSON esperado inválido: %v", err)
+	}
+	if !reflect.DeepEqual(a, b) {
+		t.Errorf("resultado = %s, esperado %s", obtido, esperado)
+	}
+}
+
+func TestAplicarJSONPatch(t *testing.T) {
+	casos := []struct {
+		nome      string
+		documento string
+		patch     string
+		esperado  string
+		erro      error
+	}{
+		// add
+		{nome: "add membro de objeto", documento: `{"a":1}`, patch: `[{"op":"add","path":"/b","value":2}]`, esperado: `{"a":1,"b":2}`},
+		{nome: "add substitui membro existente", documento: `{"a":1}`, patch: `[{"op":"add","path":"/a","value":[1]}]`, esperado: `{"a":[1]}`},
+		{nome: "add no meio do array", documento: `{"a":[1,3]}`, patch: `[{"op":"add","path":"/a/1","value":2}]`, esperado: `{"a":[1,2,3]}`},
+		{nome: "add no índice igual ao tamanho", documento: `{"a":[1]}`, patch: `[{"op":"add","path":"/a/1","value":2}]`, esperado: `{"a":[1,2]}`},
+		{nome: "add com - acrescenta no fim", documento: `{"a":[1,2]}`, patch: `[{"op":"add","path":"/a/-","value":3}]`, esperado: `{"a":[1,2,3]}`},
+		{nome: "add com - em array vazio", documento: `{"a":[]}`, patch: `[{"op":"add","path":"/a/-","value":{"x":1}}]`, esperado: `{"a":[{"x":1}]}`},
+		{nome: "add com value null", documento: `{"a":1}`, patch: `[{"op":"add","path":"/b","value":null}]`, esperado: `{"a":1,"b":null}`},
+		{nome: "add além do fim do array", documento: `{"a":[1]}`, patch: `[{"op":"add","path":"/a/2","value":2}]`, erro: ErrCaminhoInexistente},
+		{nome: "add com pai inexistente", documento: `{}`, patch: `[{"op":"add","path":"/a/b","value":1}]`, erro: ErrCaminhoInexistente},
+		{nome: "add na raiz troca o documento", documento: `{"a":1}`, patch: `[{"op":"add","path":"","value":[true]}]`, esperado: `[true]`},
+
+		// remove
+		{nome: "remove membro", documento: `{"a":1,"b":2}`, patch: `[{"op":"remove","path":"/a"}]`, esperado: `{"b":2}`},
+		{nome: "remove elemento de array", documento: `{"a":[1,2,3]}`, patch: `[{"op":"remove","path":"/a/1"}]`, esperado: `{"a":[1,3]}`},
+		{nome: "remove inexistente", documento: `{"a":1}`, patch: `[{"op":"remove","path":"/b"}]`, erro: ErrCaminhoInexistente},
+		{nome: "remove com - não existe", documento: `{"a":[1]}`, patch: `[{"op":"remove","path":"/a/-"}]`, erro: ErrCaminhoInexistente},
+
+		// replace
+		{nome: "replace membro", documento: `{"a":1}`, patch: `[{"op":"replace","path":"/a","value":"x"}]`, esperado: `{"a":"x"}`},
+		{nome: "replace com value null", documento: `{"a":1}`, patch: `[{"op":"replace","path":"/a","value":null}]`, esperado: `{"a":null}`},
+		{nome: "replace de membro null", documento: `{"a":null}`, patch: `[{"op":"replace","path":"/a","value":2}]`, esperado: `{"a":2}`},
+		{nome: "replace elemento de array", documento: `[1,2]`, patch: `[{"op":"replace","path":"/0","value":9}]`, esperado: `[9,2]`},
+		{nome: "replace inexistente", documento: `{"a":1}`, patch: `[{"op":"replace","path":"/b","value":1}]`, erro: ErrCaminhoInexistente},
+		{nome: "replace com - não existe", documento: `[1]`, patch: `[{"op":"replace","path":"/-","value":1}]`, erro: ErrCaminhoInexistente},
+		{nome: "replace sem value", documento: `{"a":1}`, patch: `[{"op":"replace","path":"/a"}]`, erro: ErrPatchInvalido},
+
+		// move e copy
+		{nome: "move membro", documento: `{"a":{"b":1},"c":{}}`, patch: `[{"op":"move","from":"/a/b","path":"/c/d"}]`, esperado: `{"a":{},"c":{"d":1}}`},
+		{nome: "move dentro do array", documento: `[1,2,3]`, patch: `[{"op":"move","from":"/0","path":"/-"}]`, esperado: `[2,3,1]`},
+		{nome: "move para dentro de si mesmo", documento: `{"a":{"b":{}}}`, patch: `[{"op":"move","from":"/a","path":"/a/b/c"}]`, erro: ErrPatchInvalido},
+		{nome: "move sem from", documento: `{"a":1}`, patch: `[{"op":"move","path":"/b"}]`, erro: ErrPatchInvalido},
+		{nome: "copy membro", documento: `{"a":[1]}`, patch: `[{"op":"copy","from":"/a","path":"/b"}]`, esperado: `{"a":[1],"b":[1]}`},
+		{nome: "copy não compartilha referência", documento: `{"a":{"x":1}}`, patch: `[{"op":"copy","from":"/a","path":"/b"},{"op":"replace","path":"/b/x","value":2}]`, esperado: `{"a":{"x":1},"b":{"x":2}}`},
+		{nome: "copy de origem inexistente", documento: `{}`, patch: `[{"op":"copy","from":"/a","path":"/b"}]`, erro: ErrCaminhoInexistente},
+
+		// test
+		{nome: "test igual", documento: `{"a":"x"}`, patch: `[{"op":"test","path":"/a","value":"x"}]`, esperado: `{"a":"x"}`},
+		{nome: "test compara números pelo valor", documento: `{"a":8.5}`, patch: `[{"op":"test","path":"/a","value":8.50}]`, esperado: `{"a":8.5}`},
+		{nome: "test compara objetos sem ordem", documento: `{"a":{"x":1,"y":2}}`, patch: `[{"op":"test","path":"/a","value":{"y":2,"x":1}}]`, esperado: `{"a":{"x":1,"y":2}}`},
+		{nome: "test null em membro null", documento: `{"a":null}`, patch: `[{"op":"test","path":"/a","value":null}]`, esperado: `{"a":null}`},
+		{nome: "test null em membro com valor", documento: `{"a":0}`, patch: `[{"op":"test","path":"/a","value":null}]`, erro: ErrTesteFalhou},
+		{nome: "test diferente", documento: `{"a":"x"}`, patch: `[{"op":"test","path":"/a","value":"y"}]`, erro: ErrTesteFalhou},
+		{nome: "test diferencia tipos", documento: `{"a":1}`, patch: `[{"op":"test","path":"/a","value":"1"}]`, erro: ErrTesteFalhou},
+		{nome: "test de membro ausente", documento: `{}`, patch: `[{"op":"test","path":"/a","value":null}]`, erro: ErrCaminhoInexistente},
+		{nome: "test sem value", documento: `{"a":null}`, patch: `[{"op":"test","path":"/a"}]`, erro: ErrPatchInvalido},
+
+		// Ponteiros JSON (RFC 6901)
+		{nome: "~1 é barra", documento: `{"a/b":1}`, patch: `[{"op":"replace","path":"/a~1b","value":2}]`, esperado: `{"a/b":2}`},
+		{nome: "~0 é til", documento: `{"m~n":1}`, patch: `[{"op":"remove","path":"/m~0n"}]`, esperado: `{}`},
+		{nome: "~01 é til seguido de 1", documento: `{"~1":1,"/":2}`, patch: `[{"op":"remove","path":"/~01"}]`, esperado: `{"/":2}`},
+		{nome: "membro de nome vazio", documento: `{"":1}`, patch: `[{"op":"replace","path":"/","value":2}]`, esperado: `{"":2}`},
+		{nome: "ponteiro sem barra inicial", documento: `{"a":1}`, patch: `[{"op":"remove","path":"a"}]`, erro: ErrPatchInvalido},
+		{nome: "índice com zero à esquerda", documento: `[1,2]`, patch: `[{"op":"remove","path":"/01"}]`, erro: ErrCaminhoInexistente},
+		{nome: "índice negativo", documento: `[1,2]`, patch: `[{"op":"remove","path":"/-1"}]`, erro: ErrCaminhoInexistente},
+
+		// Patch malformado
+		{nome: "operação desconhecida", documento: `{}`, patch: `[{"op":"merge","path":"/a"}]`, erro: ErrPatchInvalido},
+		{nome: "sem path", documento: `{}`, patch: `[{"op":"add","value":1}]`, erro: ErrPatchInvalido},
+		{nome: "patch que não é array", documento: `{}`, patch: `{"op":"add","path":"/a","value":1}`, erro: ErrPatchInvalido},
+		{nome: "lista vazia não altera", documento: `{"a":1}`, patch: `[]`, esperado: `{"a":1}`},
+
+		// Operações aplicadas em sequência
+		{nome: "cada operação vê o resultado da anterior", documento: `{"a":[]}`, patch: `[{"op":"add","path":"/a/-","value":1},{"op":"add","path":"/a/-","value":2},{"op":"test","path":"/a/1","value":2}]`, esperado: `{"a":[1,2]}`},
+		{nome: "falha no meio descarta as anteriores", documento: `{"a":1,"b":2}`, patch: `[{"op":"replace","path":"/a","value":10},{"op":"test","path":"/b","value":3},{"op":"remove","path":"/b"}]`, erro: ErrTesteFalhou},
+	}
+
+	for _, caso := range casos {
+		t.Run(caso.nome, func(t *testing.T) {
+			documento := []byte(caso.documento)
+			resultado, err := AplicarJSONPatch(documento, []byte(caso.patch))
+
+			if caso.erro != nil {
+				if !errors.Is(err, caso.erro) {
+					t.Fatalf("erro = %v, esperado %v", err, caso.erro)
+				}
+				if resultado != nil {
+					t.Errorf("com erro, nenhum resultado deveria ser devolvido: %s", resultado)
+				}
+				if string(documento) != caso.documento {
+					t.Errorf("documento original alterado: %s", documento)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("erro inesperado: %v", err)
+			}
+			mesmoJSON(t, resultado, caso.esperado)
+		})
+	}
+}
+
+func TestAplicarJSONPatchIndicaOperacaoQueFalhou(t *testing.T) {
+	patch := `[{"op":"add","path":"/b","value":1},{"op":"replace","path":"/a","value":2},{"op":"remove","path":"/x"}]`
+	_, err := AplicarJSONPatch([]byte(`{"a":1}`), []byte(patch))
+	if err == nil || !strings.HasPrefix(err.Error(), "operação 2 (remove)") {
+		t.Fatalf("erro = %v, esperado prefixo %q", err, "operação 2 (remove)")
+	}
+}
+
+func TestAplicarJSONPatchPreservaNumerosGrandes(t *testing.T) {
+	resultado, err := AplicarJSONPatch([]byte(`{"id":9007199254740993}`), []byte(`[{"op":"add","path":"/x","value":1}]`))
+	if err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	if !strings.Contains(string(resultado), "9007199254740993") {
+		t.Errorf("inteiro grande perdeu precisão: %s", resultado)
+	}
+}
+
+func TestAplicarMergePatch(t *testing.T) {
+	// Exemplos do apêndice A da RFC 7396
+	casos := []struct {
+		nome      string
+		documento string
+		patch     string
+		esperado  string
+	}{
+		{nome: "substitui membro", documento: `{"a":"b"}`, patch: `{"a":"c"}`, esperado: `{"a":"c"}`},
+		{nome: "acrescenta membro", documento: `{"a":"b"}`, patch: `{"b":"c"}`, esperado: `{"a":"b","b":"c"}`},
+		{nome: "null remove", documento: `{"a":"b"}`, patch: `{"a":null}`, esperado: `{}`},
+		{nome: "null remove só o indicado", documento: `{"a":"b","b":"c"}`, patch: `{"a":null}`, esperado: `{"b":"c"}`},
+		{nome: "array é substituído", documento: `{"a":["b"]}`, patch: `{"a":"c"}`, esperado: `{"a":"c"}`},
+		{nome: "valor vira objeto", documento: `{"a":"c"}`, patch: `{"a":["b"]}`, esperado: `{"a":["b"]}`},
+		{nome: "mescla recursiva", documento: `{"a":{"b":"c"}}`, patch: `{"a":{"b":"d","c":null}}`, esperado: `{"a":{"b":"d"}}`},
+		{nome: "array de objetos é substituído", documento: `{"a":[{"b":"c"}]}`, patch: `{"a":[1]}`, esperado: `{"a":[1]}`},
+		{nome: "patch array troca o documento", documento: `["a","b"]`, patch: `["c","d"]`, esperado: `["c","d"]`},
+		{nome: "patch objeto sobre array", documento: `{"a":"b"}`, patch: `["c"]`, esperado: `["c"]`},
+		{nome: "patch null", documento: `{"a":"foo"}`, patch: `null`, esperado: `null`},
+		{nome: "patch escalar", documento: `{"a":"foo"}`, patch: `"bar"`, esperado: `"bar"`},
+		{nome: "null dentro de objeto novo não aparece", documento: `{"e":null}`, patch: `{"a":1}`, esperado: `{"e":null,"a":1}`},
+		{nome: "objeto novo é criado", documento: `[1,2]`, patch: `{"a":"b","c":null}`, esperado: `{"a":"b"}`},
+		{nome: "objetos aninhados novos", documento: `{}`, patch: `{"a":{"bb":{"ccc":null}}}`, esperado: `{"a":{"bb":{}}}`},
+	}
+
+	for _, caso := range casos {
+		t.Run(caso.nome, func(t *testing.T) {
+			resultado, err := AplicarMergePatch([]byte(caso.documento), []byte(caso.patch))
+			if err != nil {
+				t.Fatalf("erro inesperado: %v", err)
+			}
+			mesmoJSON(t, resultado, caso.esperado)
+		})
+	}
+}
+
+func TestAplicarMergePatchInvalido(t *testing.T) {
+	if _, err := AplicarMergePatch([]byte(`{}`), []byte(`{"a":`)); !errors.Is(err, ErrPatchInvalido) {
+		t.Errorf("erro = %v, esperado %v", err, ErrPatchInvalido)
+	}
+}
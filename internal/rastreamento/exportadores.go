@@ -0,0 +1,283 @@
+package rastreamento
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Exportador envia um lote de spans finalizados para algum destino
+type Exportador interface {
+	Exportar(ctx context.Context, servico string, spans []*Span) error
+	Encerrar(ctx context.Context) error
+}
+
+// ExportadorNulo descarta os spans (rastreamento desativado)
+type ExportadorNulo struct{}
+
+func (ExportadorNulo) Exportar(context.Context, string, []*Span) error { return nil }
+func (ExportadorNulo) Encerrar(context.Context) error                  { return nil }
+
+// ExportadorStdout escreve um JSON por linha para cada span
+type ExportadorStdout struct {
+	mu    sync.Mutex
+	saida io.Writer
+}
+
+// NovoExportadorStdout cria o exportador para o writer informado
+func NovoExportadorStdout(saida io.Writer) *ExportadorStdout {
+	return &ExportadorStdout{saida: saida}
+}
+
+// spanJSON é a forma legível do span usada pelo exportador stdout
+type spanJSON struct {
+	Servico   string         `json:"servico"`
+	Nome      string         `json:"nome"`
+	TraceID   string         `json:"trace_id"`
+	SpanID    string         `json:"span_id"`
+	Pai       string         `json:"pai,omitempty"`
+	Tipo      int            `json:"tipo"`
+	Inicio    time.Time      `json:"inicio"`
+	DuracaoMs float64        `json:"duracao_ms"`
+	Status    int            `json:"status"`
+	Mensagem  string         `json:"mensagem,omitempty"`
+	Atributos map[string]any `json:"atributos,omitempty"`
+}
+
+func (e *ExportadorStdout) Exportar(_ context.Context, servico string, spans []*Span) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	codificador := json.NewEncoder(e.saida)
+	for _, span := range spans {
+		item := spanJSON{
+			Servico:   servico,
+			Nome:      span.Nome,
+			TraceID:   span.Contexto.TraceID.String(),
+			SpanID:    span.Contexto.SpanID.String(),
+			Tipo:      span.Tipo,
+			Inicio:    span.Inicio,
+			DuracaoMs: float64(span.Fim.Sub(span.Inicio).Microseconds()) / 1000,
+			Status:    span.Status,
+			Mensagem:  span.Mensagem,
+		}
+		if span.Pai.Valido() {
+			item.Pai = span.Pai.String()
+		}
+		if len(span.Atributos) > 0 {
+			item.Atributos = make(map[string]any, len(span.Atributos))
+			for _, atributo := range span.Atributos {
+				item.Atributos[atributo.Chave] = atributo.Valor
+			}
+		}
+		if err := codificador.Encode(item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *ExportadorStdout) Encerrar(context.Context) error { return nil }
+
+// ExportadorOTLP envia spans para um coletor via OTLP/HTTP com corpo JSON
+type ExportadorOTLP struct {
+	url     string
+	cliente *http.Client
+}
+
+// NovoExportadorOTLP cria o exportador para o endpoint base do coletor
+// (ex.: http://localhost:4318); o caminho /v1/traces é acrescentado
+func NovoExportadorOTLP(endpoint string, timeout time.Duration) *ExportadorOTLP {
+	return &ExportadorOTLP{
+		url:     strings.TrimSuffix(endpoint, "/") + "/v1/traces",
+		cliente: &http.Client{Timeout: timeout},
+	}
+}
+
+func (e *ExportadorOTLP) Exportar(ctx context.Context, servico string, spans []*Span) error {
+	corpo, err := json.Marshal(montarRequisicaoOTLP(servico, spans))
+	if err != nil {
+		return err
+	}
+
+	requisicao, err := http.NewRequestWithContext(ctx, http.MethodPost, e.url, bytes.NewReader(corpo))
+	if err != nil {
+		return err
+	}
+	requisicao.Header.Set("Content-Type", "application/json")
+
+	resposta, err := e.cliente.Do(requisicao)
+	if err != nil {
+		return err
+	}
+	defer resposta.Body.Close()
+	io.Copy(io.Discard, resposta.Body)
+
+	if resposta.StatusCode >= 300 {
+		return fmt.Errorf("coletor OTLP respondeu %s", resposta.Status)
+	}
+	return nil
+}
+
+func (e *ExportadorOTLP) Encerrar(context.Context) error {
+	e.cliente.CloseIdleConnections()
+	return nil
+}
+
+// montarRequisicaoOTLP converte os spans no ExportTraceServiceRequest em JSON
+func montarRequisicaoOTLP(servico string, spans []*Span) map[string]any {
+	itens := make([]map[string]any, 0, len(spans))
+	for _, span := range spans {
+		item := map[string]any{
+			"traceId":           span.Contexto.TraceID.String(),
+			"spanId":            span.Contexto.SpanID.String(),
+			"name":              span.Nome,
+			"kind":              span.Tipo,
+			"startTimeUnixNano": strconv.FormatInt(span.Inicio.UnixNano(), 10),
+			"endTimeUnixNano":   strconv.FormatInt(span.Fim.UnixNano(), 10),
+			"attributes":        atributosOTLP(span.Atributos),
+			"status":            map[string]any{"code": span.Status, "message": span.Mensagem},
+		}
+		if span.Pai.Valido() {
+			item["parentSpanId"] = span.Pai.String()
+		}
+		itens = append(itens, item)
+	}
+
+	return map[string]any{
+		"resourceSpans": []any{map[string]any{
+			"resource": map[string]any{
+				"attributes": atributosOTLP([]Atributo{{Chave: "service.name", Valor: servico}}),
+			},
+			"scopeSpans": []any{map[string]any{
+				"scope": map[string]any{"name": "api-filmes/internal/rastreamento"},
+				"spans": itens,
+			}},
+		}},
+	}
+}
+
+func atributosOTLP(atributos []Atributo) []map[string]any {
+	convertidos := make([]map[string]any, 0, len(atributos))
+	for _, atributo := range atributos {
+		var valor map[string]any
+		switch v := atributo.Valor.(type) {
+		case string:
+			valor = map[string]any{"stringValue": v}
+		case int:
+			valor = map[string]any{"intValue": strconv.Itoa(v)}
+		case int64:
+			valor = map[string]any{"intValue": strconv.FormatInt(v, 10)}
+		case float64:
+			valor = map[string]any{"doubleValue": v}
+		case bool:
+			valor = map[string]any{"boolValue": v}
+		default:
+			valor = map[string]any{"stringValue": fmt.Sprint(v)}
+		}
+		convertidos = append(convertidos, map[string]any{"key": atributo.Chave, "value": valor})
+	}
+	return convertidos
+}
+
+// Limites do processador em lote
+const (
+	tamanhoFila       = 2048
+	tamanhoLote       = 512
+	intervaloExportar = 5 * time.Second
+	timeoutExportacao = 10 * time.Second
+)
+
+// processador acumula spans finalizados e os exporta em lotes numa
+// goroutine própria, para que a requisição nunca espere pelo coletor
+type processador struct {
+	exportador Exportador
+	servico    string
+	fila       chan *Span
+	parar      chan struct{}
+	concluido  chan struct{}
+	uma        sync.Once
+}
+
+func novoProcessador(exportador Exportador, servico string) *processador {
+	p := &processador{
+		exportador: exportador,
+		servico:    servico,
+		fila:       make(chan *Span, tamanhoFila),
+		parar:      make(chan struct{}),
+		concluido:  make(chan struct{}),
+	}
+	go p.executar()
+	return p
+}
+
+// enfileirar descarta o span se a fila estiver cheia em vez de bloquear
+func (p *processador) enfileirar(span *Span) {
+	select {
+	case p.fila <- span:
+	default:
+		slog.Debug("fila de spans cheia; span descartado", "span", span.Nome)
+	}
+}
+
+func (p *processador) executar() {
+	defer close(p.concluido)
+
+	relogio := time.NewTicker(intervaloExportar)
+	defer relogio.Stop()
+
+	lote := make([]*Span, 0, tamanhoLote)
+	exportar := func() {
+		if len(lote) == 0 {
+			return
+		}
+		ctx, cancelar := context.WithTimeout(context.Background(), timeoutExportacao)
+		defer cancelar()
+		if err := p.exportador.Exportar(ctx, p.servico, lote); err != nil {
+			slog.Warn("erro ao exportar spans", "erro", err, "quantidade", len(lote))
+		}
+		lote = make([]*Span, 0, tamanhoLote)
+	}
+
+	for {
+		select {
+		case span := <-p.fila:
+			lote = append(lote, span)
+			if len(lote) >= tamanhoLote {
+				exportar()
+			}
+		case <-relogio.C:
+			exportar()
+		case <-p.parar:
+			for {
+				select {
+				case span := <-p.fila:
+					lote = append(lote, span)
+				default:
+					exportar()
+					return
+				}
+			}
+		}
+	}
+}
+
+// encerrar exporta o que resta na fila, respeitando o prazo do contexto
+func (p *processador) encerrar(ctx context.Context) error {
+	p.uma.Do(func() { close(p.parar) })
+
+	select {
+	case <-p.concluido:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return p.exportador.Encerrar(ctx)
+}
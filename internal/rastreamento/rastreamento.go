@@ -0,0 +1,259 @@
+// Package rastreamento implementa spans no estilo OpenTelemetry: propagação
+// W3C traceparent, spans aninhados via contexto e exportação em lote para
+// stdout ou para um coletor OTLP/HTTP (JSON).
+package rastreamento
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	mrand "math/rand"
+	"sync"
+	"time"
+)
+
+// IDTrace identifica um trace inteiro (16 bytes)
+type IDTrace [16]byte
+
+// IDSpan identifica um span dentro do trace (8 bytes)
+type IDSpan [8]byte
+
+func (id IDTrace) String() string { return hex.EncodeToString(id[:]) }
+func (id IDSpan) String() string  { return hex.EncodeToString(id[:]) }
+
+// Valido indica se o ID não é todo zero, como exige a especificação W3C
+func (id IDTrace) Valido() bool { return id != IDTrace{} }
+
+// Valido indica se o ID não é todo zero, como exige a especificação W3C
+func (id IDSpan) Valido() bool { return id != IDSpan{} }
+
+// Tipos de span, com os mesmos valores do OTLP
+const (
+	TipoInterno  = 1
+	TipoServidor = 2
+	TipoCliente  = 3
+)
+
+// Status de span, com os mesmos valores do OTLP
+const (
+	StatusNaoDefinido = 0
+	StatusOK          = 1
+	StatusErro        = 2
+)
+
+// ContextoSpan é a parte do span que atravessa fronteiras de processo
+type ContextoSpan struct {
+	TraceID   IDTrace
+	SpanID    IDSpan
+	Amostrado bool
+}
+
+// Valido indica se trace e span possuem IDs definidos
+func (c ContextoSpan) Valido() bool {
+	return c.TraceID.Valido() && c.SpanID.Valido()
+}
+
+// Atributo é um par chave/valor anexado ao span (string, int, float64 ou bool)
+type Atributo struct {
+	Chave string
+	Valor any
+}
+
+// Span representa uma operação com início, fim, atributos e status
+type Span struct {
+	rastreador *Rastreador
+	Nome       string
+	Tipo       int
+	Contexto   ContextoSpan
+	Pai        IDSpan
+	Inicio     time.Time
+	Fim        time.Time
+	Atributos  []Atributo
+	Status     int
+	Mensagem   string
+
+	mu         sync.Mutex
+	finalizado bool
+}
+
+// DefinirAtributo anexa um atributo ao span
+func (s *Span) DefinirAtributo(chave string, valor any) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Atributos = append(s.Atributos, Atributo{Chave: chave, Valor: valor})
+}
+
+// DefinirNome troca o nome do span (ex.: quando a rota só é conhecida no fim)
+func (s *Span) DefinirNome(nome string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Nome = nome
+}
+
+// RegistrarErro marca o span com status de erro
+func (s *Span) RegistrarErro(err error) {
+	if err == nil {
+		return
+	}
+	s.DefinirStatus(StatusErro, err.Error())
+}
+
+// DefinirStatus define o status final do span
+func (s *Span) DefinirStatus(status int, mensagem string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Status = status
+	s.Mensagem = mensagem
+}
+
+// Finalizar encerra o span e o envia ao exportador (se amostrado)
+func (s *Span) Finalizar() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	if s.finalizado {
+		s.mu.Unlock()
+		return
+	}
+	s.finalizado = true
+	s.Fim = time.Now()
+	s.mu.Unlock()
+
+	if s.Contexto.Amostrado && s.rastreador.processador != nil {
+		s.rastreador.processador.enfileirar(s)
+	}
+}
+
+// Rastreador cria spans e os entrega ao processador de exportação; sem
+// processador (NovoRastreadorNulo), os spans só propagam o contexto
+type Rastreador struct {
+	servico     string
+	amostragem  float64
+	processador *processador
+}
+
+// NovoRastreador cria um rastreador; amostragem entre 0 e 1 vale para
+// traces iniciados aqui (traces recebidos respeitam a decisão do chamador)
+func NovoRastreador(servico string, amostragem float64, exportador Exportador) *Rastreador {
+	return &Rastreador{
+		servico:     servico,
+		amostragem:  amostragem,
+		processador: novoProcessador(exportador, servico),
+	}
+}
+
+// NovoRastreadorNulo cria um rastreador que não exporta nada, nem mesmo
+// spans de traces recebidos já amostrados. Os IDs continuam sendo gerados
+// para correlacionar logs e propagar o traceparent.
+func NovoRastreadorNulo(servico string) *Rastreador {
+	return &Rastreador{servico: servico}
+}
+
+// Encerrar exporta os spans pendentes e libera o exportador
+func (r *Rastreador) Encerrar(ctx context.Context) error {
+	if r.processador == nil {
+		return nil
+	}
+	return r.processador.encerrar(ctx)
+}
+
+var (
+	muPadrao sync.RWMutex
+	padrao   = NovoRastreadorNulo("api-filmes")
+)
+
+// DefinirPadrao troca o rastreador usado pelas funções do pacote
+func DefinirPadrao(r *Rastreador) {
+	muPadrao.Lock()
+	defer muPadrao.Unlock()
+	padrao = r
+}
+
+// Padrao retorna o rastreador em uso
+func Padrao() *Rastreador {
+	muPadrao.RLock()
+	defer muPadrao.RUnlock()
+	return padrao
+}
+
+type chaveContexto int
+
+const (
+	chaveSpan chaveContexto = iota
+	chaveRemoto
+)
+
+// ComContextoRemoto guarda no contexto o span recebido de outro serviço
+// (extraído do traceparent) para ser pai do próximo span iniciado
+func ComContextoRemoto(ctx context.Context, remoto ContextoSpan) context.Context {
+	return context.WithValue(ctx, chaveRemoto, remoto)
+}
+
+// SpanDoContexto retorna o span ativo ou nil
+func SpanDoContexto(ctx context.Context) *Span {
+	span, _ := ctx.Value(chaveSpan).(*Span)
+	return span
+}
+
+// Iniciar cria um span filho do span ativo no contexto (ou do contexto
+// remoto, ou um novo trace) usando o rastreador padrão
+func Iniciar(ctx context.Context, nome string, tipo int, atributos ...Atributo) (context.Context, *Span) {
+	return Padrao().Iniciar(ctx, nome, tipo, atributos...)
+}
+
+// Iniciar cria um span filho do span ativo no contexto
+func (r *Rastreador) Iniciar(ctx context.Context, nome string, tipo int, atributos ...Atributo) (context.Context, *Span) {
+	span := &Span{
+		rastreador: r,
+		Nome:       nome,
+		Tipo:       tipo,
+		Inicio:     time.Now(),
+		Atributos:  atributos,
+	}
+
+	switch pai := SpanDoContexto(ctx); {
+	case pai != nil:
+		span.Contexto = ContextoSpan{TraceID: pai.Contexto.TraceID, Amostrado: pai.Contexto.Amostrado}
+		span.Pai = pai.Contexto.SpanID
+	default:
+		if remoto, ok := ctx.Value(chaveRemoto).(ContextoSpan); ok && remoto.Valido() {
+			span.Contexto = ContextoSpan{TraceID: remoto.TraceID, Amostrado: remoto.Amostrado}
+			span.Pai = remoto.SpanID
+		} else {
+			span.Contexto = ContextoSpan{TraceID: novoIDTrace(), Amostrado: r.amostrar()}
+		}
+	}
+	span.Contexto.SpanID = novoIDSpan()
+
+	return context.WithValue(ctx, chaveSpan, span), span
+}
+
+func (r *Rastreador) amostrar() bool {
+	return r.amostragem >= 1 || (r.amostragem > 0 && mrand.Float64() < r.amostragem)
+}
+
+func novoIDTrace() IDTrace {
+	var id IDTrace
+	for !id.Valido() {
+		rand.Read(id[:])
+	}
+	return id
+}
+
+func novoIDSpan() IDSpan {
+	var id IDSpan
+	for !id.Valido() {
+		rand.Read(id[:])
+	}
+	return id
+}
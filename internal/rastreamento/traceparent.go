@@ -0,0 +1,69 @@
+package rastreamento
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// CabecalhoTraceparent é o cabeçalho de propagação do W3C Trace Context
+const CabecalhoTraceparent = "traceparent"
+
+// flagAmostrado é o bit trace-flags que indica que o trace foi amostrado
+const flagAmostrado = 0x01
+
+// ExtrairTraceparent interpreta "00-<trace-id>-<parent-id>-<flags>".
+// Versões futuras são aceitas desde que os quatro primeiros campos sigam o formato.
+func ExtrairTraceparent(valor string) (ContextoSpan, bool) {
+	partes := strings.Split(strings.TrimSpace(valor), "-")
+	if len(partes) < 4 {
+		return ContextoSpan{}, false
+	}
+
+	versao, traceID, spanID, flags := partes[0], partes[1], partes[2], partes[3]
+	if len(versao) != 2 || versao == "ff" || (versao == "00" && len(partes) != 4) {
+		return ContextoSpan{}, false
+	}
+	if len(traceID) != 32 || len(spanID) != 16 || len(flags) != 2 {
+		return ContextoSpan{}, false
+	}
+	// A especificação só admite hexadecimal minúsculo
+	for _, parte := range partes[:4] {
+		if !hexMinusculo(parte) {
+			return ContextoSpan{}, false
+		}
+	}
+
+	var contexto ContextoSpan
+	if _, err := hex.Decode(contexto.TraceID[:], []byte(traceID)); err != nil {
+		return ContextoSpan{}, false
+	}
+	if _, err := hex.Decode(contexto.SpanID[:], []byte(spanID)); err != nil {
+		return ContextoSpan{}, false
+	}
+	var bytesFlags [1]byte
+	if _, err := hex.Decode(bytesFlags[:], []byte(flags)); err != nil {
+		return ContextoSpan{}, false
+	}
+	contexto.Amostrado = bytesFlags[0]&flagAmostrado != 0
+
+	return contexto, contexto.Valido()
+}
+
+func hexMinusculo(valor string) bool {
+	for _, c := range valor {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// Traceparent formata o contexto para propagação a outros serviços
+func (c ContextoSpan) Traceparent() string {
+	flags := 0
+	if c.Amostrado {
+		flags = flagAmostrado
+	}
+	return fmt.Sprintf("00-%s-%s-%02x", c.TraceID, c.SpanID, flags)
+}
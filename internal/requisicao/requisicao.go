@@ -0,0 +1,114 @@
+// Package requisicao guarda no contexto os dados de cada requisição HTTP
+// (identificador de correlação, template da rota e autor) para que logs,
+// métricas, auditoria e camadas inferiores os usem.
+package requisicao
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+)
+
+// CabecalhoID é o cabeçalho usado para propagar o identificador da requisição
+const CabecalhoID = "X-Request-ID"
+
+// tamanhoMaximoID limita IDs recebidos de clientes para não poluir os logs
+const tamanhoMaximoID = 128
+
+// dados é preenchido ao longo da requisição: o ID na entrada, a rota
+// quando o roteador escolhe o handler e o ator quando ele é identificado
+type dados struct {
+	id   string
+	rota string
+	ator string
+}
+
+// Atores usados quando nenhum usuário foi identificado
+const (
+	// AtorAnonimo é o autor de requisições HTTP sem identificação
+	AtorAnonimo = "anonimo"
+	// AtorSistema é o autor de operações internas (jobs, migrações)
+	AtorSistema = "sistema"
+)
+
+type chaveContexto int
+
+const chaveDados chaveContexto = iota
+
+// Novo devolve um contexto com os dados da requisição identificada por id
+func Novo(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, chaveDados, &dados{id: id})
+}
+
+func obter(ctx context.Context) *dados {
+	d, _ := ctx.Value(chaveDados).(*dados)
+	return d
+}
+
+// ID retorna o identificador da requisição ou "" se não houver
+func ID(ctx context.Context) string {
+	if d := obter(ctx); d != nil {
+		return d.id
+	}
+	return ""
+}
+
+// DefinirRota registra o template da rota escolhida (ex.: /filmes/{id}).
+// Como os dados são compartilhados, middlewares externos enxergam o valor
+// depois que o handler retorna.
+func DefinirRota(ctx context.Context, rota string) {
+	if d := obter(ctx); d != nil {
+		d.rota = rota
+	}
+}
+
+// Rota retorna o template da rota ou "" se nenhuma correspondeu
+func Rota(ctx context.Context) string {
+	if d := obter(ctx); d != nil {
+		return d.rota
+	}
+	return ""
+}
+
+// DefinirAtor registra quem executa a requisição (ex.: o usuário autenticado)
+func DefinirAtor(ctx context.Context, ator string) {
+	if d := obter(ctx); d != nil {
+		d.ator = ator
+	}
+}
+
+// Ator retorna o autor registrado; sem um, AtorAnonimo dentro de
+// requisições HTTP e AtorSistema fora delas
+func Ator(ctx context.Context) string {
+	d := obter(ctx)
+	switch {
+	case d == nil:
+		return AtorSistema
+	case d.ator == "":
+		return AtorAnonimo
+	default:
+		return d.ator
+	}
+}
+
+// NovoID gera um identificador aleatório de 128 bits em hexadecimal
+func NovoID() string {
+	bytes := make([]byte, 16)
+	if _, err := rand.Read(bytes); err != nil {
+		return "sem-id"
+	}
+	return hex.EncodeToString(bytes)
+}
+
+// IDValido aceita IDs recebidos de clientes se forem curtos e imprimíveis
+func IDValido(id string) bool {
+	if id == "" || len(id) > tamanhoMaximoID {
+		return false
+	}
+	for _, c := range id {
+		if c < 0x21 || c > 0x7e {
+			return false
+		}
+	}
+	return true
+}
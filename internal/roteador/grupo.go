@@ -0,0 +1,74 @@
+package roteador
+
+import "net/http"
+
+// Middleware envolve um handler com comportamento adicional
+type Middleware func(http.Handler) http.Handler
+
+// Rotas é implementado por Roteador e Grupo, permitindo que um conjunto de
+// handlers seja registrado tanto na raiz quanto sob um prefixo (ex.: /v1)
+type Rotas interface {
+	Manipular(metodo, padrao string, handler http.Handler)
+	Get(padrao string, handler http.HandlerFunc)
+	Post(padrao string, handler http.HandlerFunc)
+	Put(padrao string, handler http.HandlerFunc)
+	Patch(padrao string, handler http.HandlerFunc)
+	Delete(padrao string, handler http.HandlerFunc)
+}
+
+// Grupo registra rotas sob um prefixo comum, aplicando seus middlewares
+type Grupo struct {
+	roteador    *Roteador
+	prefixo     string
+	middlewares []Middleware
+}
+
+// Grupo cria um grupo de rotas com o prefixo informado ("" para a raiz).
+// Os middlewares são aplicados na ordem dada, o primeiro mais externo.
+func (rt *Roteador) Grupo(prefixo string, middlewares ...Middleware) *Grupo {
+	return &Grupo{roteador: rt, prefixo: prefixo, middlewares: middlewares}
+}
+
+// Manipular registra o handler com o prefixo e os middlewares do grupo
+func (g *Grupo) Manipular(metodo, padrao string, handler http.Handler) {
+	for i := len(g.middlewares) - 1; i >= 0; i-- {
+		handler = g.middlewares[i](handler)
+	}
+
+	completo := g.prefixo + padrao
+	if padrao == "/" && g.prefixo != "" {
+		completo = g.prefixo
+	}
+	g.roteador.Manipular(metodo, completo, handler)
+}
+
+// Get registra um handler para GET no grupo
+func (g *Grupo) Get(padrao string, handler http.HandlerFunc) {
+	g.Manipular(http.MethodGet, padrao, handler)
+}
+
+// Post registra um handler para POST no grupo
+func (g *Grupo) Post(padrao string, handler http.HandlerFunc) {
+	g.Manipular(http.MethodPost, padrao, handler)
+}
+
+// Put registra um handler para PUT no grupo
+func (g *Grupo) Put(padrao string, handler http.HandlerFunc) {
+	g.Manipular(http.MethodPut, padrao, handler)
+}
+
+// Patch registra um handler para PATCH no grupo
+func (g *Grupo) Patch(padrao string, handler http.HandlerFunc) {
+	g.Manipular(http.MethodPatch, padrao, handler)
+}
+
+// Delete registra um handler para DELETE no grupo
+func (g *Grupo) Delete(padrao string, handler http.HandlerFunc) {
+	g.Manipular(http.MethodDelete, padrao, handler)
+}
+
+// Garantir em tempo de compilação que ambos satisfazem Rotas
+var (
+	_ Rotas = (*Roteador)(nil)
+	_ Rotas = (*Grupo)(nil)
+)
@@ -0,0 +1,234 @@
+// Package roteador implementa o roteamento HTTP da API: padrões com
+// parâmetros ({id}), roteamento por método com 405 e cabeçalho Allow,
+// redirecionamento de barra final e exposição do template da rota
+// no contexto para logs, métricas e spans.
+package roteador
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"api-filmes/internal/requisicao"
+)
+
+// segmento é uma parte do padrão: literal ("filmes") ou parâmetro ("{id}")
+type segmento struct {
+	literal   string
+	parametro string
+}
+
+// rota associa um padrão a handlers por método
+type rota struct {
+	padrao    string
+	segmentos []segmento
+	handlers  map[string]http.Handler
+}
+
+// Roteador despacha requisições para o handler da rota mais específica
+type Roteador struct {
+	rotas []*rota
+
+	// NaoEncontrado atende caminhos sem rota (padrão: http.NotFound)
+	NaoEncontrado http.Handler
+	// MetodoNaoPermitido atende rotas existentes chamadas com outro método;
+	// o cabeçalho Allow já vem preenchido
+	MetodoNaoPermitido http.Handler
+}
+
+// Novo cria um roteador vazio
+func Novo() *Roteador {
+	return &Roteador{
+		NaoEncontrado: http.NotFoundHandler(),
+		MetodoNaoPermitido: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		}),
+	}
+}
+
+// Manipular registra o handler para o método e o padrão (ex.: "/filmes/{id}").
+// Padrões duplicados ou malformados indicam erro de programação e causam panic.
+func (rt *Roteador) Manipular(metodo, padrao string, handler http.Handler) {
+	segmentos, err := interpretarPadrao(padrao)
+	if err != nil {
+		panic(err)
+	}
+
+	for _, existente := range rt.rotas {
+		if existente.padrao == padrao {
+			if _, duplicado := existente.handlers[metodo]; duplicado {
+				panic(fmt.Sprintf("rota %s %s registrada duas vezes", metodo, padrao))
+			}
+			existente.handlers[metodo] = handler
+			return
+		}
+	}
+
+	rt.rotas = append(rt.rotas, &rota{
+		padrao:    padrao,
+		segmentos: segmentos,
+		handlers:  map[string]http.Handler{metodo: handler},
+	})
+}
+
+// Get registra um handler para GET (HEAD é atendido automaticamente)
+func (rt *Roteador) Get(padrao string, handler http.HandlerFunc) {
+	rt.Manipular(http.MethodGet, padrao, handler)
+}
+
+// Post registra um handler para POST
+func (rt *Roteador) Post(padrao string, handler http.HandlerFunc) {
+	rt.Manipular(http.MethodPost, padrao, handler)
+}
+
+// Put registra um handler para PUT
+func (rt *Roteador) Put(padrao string, handler http.HandlerFunc) {
+	rt.Manipular(http.MethodPut, padrao, handler)
+}
+
+// Patch registra um handler para PATCH
+func (rt *Roteador) Patch(padrao string, handler http.HandlerFunc) {
+	rt.Manipular(http.MethodPatch, padrao, handler)
+}
+
+// Delete registra um handler para DELETE
+func (rt *Roteador) Delete(padrao string, handler http.HandlerFunc) {
+	rt.Manipular(http.MethodDelete, padrao, handler)
+}
+
+// ServeHTTP escolhe a rota, preenche r.PathValue e chama o handler.
+// Caminhos com barra final são redirecionados (308) para a forma sem barra.
+func (rt *Roteador) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	caminho := r.URL.Path
+
+	encontrada, valores := rt.encontrar(caminho)
+	if encontrada == nil {
+		if semBarra := strings.TrimRight(caminho, "/"); semBarra != caminho && semBarra != "" {
+			if alvo, _ := rt.encontrar(semBarra); alvo != nil {
+				destino := *r.URL
+				destino.Path = semBarra
+				destino.RawPath = ""
+				http.Redirect(w, r, destino.String(), http.StatusPermanentRedirect)
+				return
+			}
+		}
+		rt.NaoEncontrado.ServeHTTP(w, r)
+		return
+	}
+
+	requisicao.DefinirRota(r.Context(), encontrada.padrao)
+	for i, seg := range encontrada.segmentos {
+		if seg.parametro != "" {
+			r.SetPathValue(seg.parametro, valores[i])
+		}
+	}
+
+	handler, ok := encontrada.handlers[r.Method]
+	if !ok && r.Method == http.MethodHead {
+		handler, ok = encontrada.handlers[http.MethodGet]
+	}
+	if ok {
+		handler.ServeHTTP(w, r)
+		return
+	}
+
+	w.Header().Set("Allow", encontrada.permitidos())
+	if r.Method == http.MethodOptions {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	rt.MetodoNaoPermitido.ServeHTTP(w, r)
+}
+
+// encontrar retorna a rota mais específica para o caminho: em cada posição,
+// um segmento literal vence um parâmetro (/filmes/busca vence /filmes/{id})
+func (rt *Roteador) encontrar(caminho string) (*rota, []string) {
+	partes := dividir(caminho)
+
+	var melhor *rota
+	for _, candidata := range rt.rotas {
+		if !candidata.corresponde(partes) {
+			continue
+		}
+		if melhor == nil || candidata.maisEspecificaQue(melhor) {
+			melhor = candidata
+		}
+	}
+	return melhor, partes
+}
+
+func (ro *rota) corresponde(partes []string) bool {
+	if len(partes) != len(ro.segmentos) {
+		return false
+	}
+	for i, seg := range ro.segmentos {
+		if seg.parametro == "" && seg.literal != partes[i] {
+			return false
+		}
+		if seg.parametro != "" && partes[i] == "" {
+			return false
+		}
+	}
+	return true
+}
+
+func (ro *rota) maisEspecificaQue(outra *rota) bool {
+	for i := range ro.segmentos {
+		literal, literalOutra := ro.segmentos[i].parametro == "", outra.segmentos[i].parametro == ""
+		if literal != literalOutra {
+			return literal
+		}
+	}
+	return false
+}
+
+// permitidos lista os métodos aceitos pela rota para o cabeçalho Allow
+func (ro *rota) permitidos() string {
+	metodos := []string{http.MethodOptions}
+	for metodo := range ro.handlers {
+		metodos = append(metodos, metodo)
+	}
+	if _, ok := ro.handlers[http.MethodGet]; ok {
+		if _, ok := ro.handlers[http.MethodHead]; !ok {
+			metodos = append(metodos, http.MethodHead)
+		}
+	}
+	sort.Strings(metodos)
+	return strings.Join(metodos, ", ")
+}
+
+// interpretarPadrao valida o padrão e o divide em segmentos
+func interpretarPadrao(padrao string) ([]segmento, error) {
+	if !strings.HasPrefix(padrao, "/") {
+		return nil, fmt.Errorf("padrão de rota %q deve começar com /", padrao)
+	}
+
+	var segmentos []segmento
+	nomes := map[string]bool{}
+	for _, parte := range dividir(padrao) {
+		if strings.HasPrefix(parte, "{") && strings.HasSuffix(parte, "}") {
+			nome := parte[1 : len(parte)-1]
+			if nome == "" || nomes[nome] {
+				return nil, fmt.Errorf("parâmetro inválido ou repetido em %q", padrao)
+			}
+			nomes[nome] = true
+			segmentos = append(segmentos, segmento{parametro: nome})
+			continue
+		}
+		if strings.ContainsAny(parte, "{}") {
+			return nil, fmt.Errorf("segmento %q inválido em %q", parte, padrao)
+		}
+		segmentos = append(segmentos, segmento{literal: parte})
+	}
+	return segmentos, nil
+}
+
+// dividir separa o caminho em segmentos; "/" vira uma lista vazia
+func dividir(caminho string) []string {
+	caminho = strings.TrimPrefix(caminho, "/")
+	if caminho == "" {
+		return nil
+	}
+	return strings.Split(caminho, "/")
+}
@@ -0,0 +1,142 @@
+package roteador
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// responder devolve um handler que escreve o nome recebido e os parâmetros
+// pedidos, para o teste saber qual rota atendeu
+func responder(nome string, parametros ...string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		corpo := nome
+		for _, parametro := range parametros {
+			corpo += " " + parametro + "=" + r.PathValue(parametro)
+		}
+		w.Write([]byte(corpo))
+	}
+}
+
+// cabecalho acrescenta um cabeçalho à resposta, marcando o grupo que atendeu
+func cabecalho(chave, valor string) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set(chave, valor)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func novoRoteadorTeste() *Roteador {
+	rt := Novo()
+	rt.Get("/", responder("raiz"))
+	rt.Get("/filmes", responder("listar"))
+	rt.Post("/filmes", responder("criar"))
+	rt.Get("/filmes/{id}", responder("buscar", "id"))
+	rt.Get("/filmes/busca", responder("busca"))
+	rt.Get("/filmes/{id}/versoes/{n}", responder("versao", "id", "n"))
+	rt.Get("/filmes/{id}/versoes/diferenca", responder("diferenca", "id"))
+	rt.Post("/importar", responder("importar"))
+
+	// Mesmo padrão registrado por grupos diferentes, como leitura e escrita
+	leitura := rt.Grupo("/v1", cabecalho("X-Grupo", "leitura"))
+	escrita := rt.Grupo("/v1", cabecalho("X-Grupo", "escrita"))
+	leitura.Get("/pessoas/{id}", responder("pessoa", "id"))
+	escrita.Put("/pessoas/{id}", responder("renomear", "id"))
+	escrita.Delete("/pessoas/{id}", responder("remover", "id"))
+	leitura.Get("/", responder("v1"))
+	return rt
+}
+
+func TestServeHTTP(t *testing.T) {
+	rt := novoRoteadorTeste()
+
+	casos := []struct {
+		nome    string
+		metodo  string
+		caminho string
+		status  int
+		corpo   string
+		allow   string
+		local   string
+		grupo   string
+	}{
+		{nome: "raiz", metodo: "GET", caminho: "/", status: 200, corpo: "raiz"},
+		{nome: "literal", metodo: "GET", caminho: "/filmes", status: 200, corpo: "listar"},
+		{nome: "método diferente no mesmo padrão", metodo: "POST", caminho: "/filmes", status: 200, corpo: "criar"},
+		{nome: "parâmetro", metodo: "GET", caminho: "/filmes/42", status: 200, corpo: "buscar id=42"},
+		{nome: "literal vence parâmetro", metodo: "GET", caminho: "/filmes/busca", status: 200, corpo: "busca"},
+		{nome: "literal vence parâmetro no meio", metodo: "GET", caminho: "/filmes/7/versoes/diferenca", status: 200, corpo: "diferenca id=7"},
+		{nome: "dois parâmetros", metodo: "GET", caminho: "/filmes/7/versoes/3", status: 200, corpo: "versao id=7 n=3"},
+		{nome: "segmento vazio não casa parâmetro", metodo: "GET", caminho: "/filmes//versoes/3", status: 404},
+		{nome: "caminho inexistente", metodo: "GET", caminho: "/series", status: 404},
+		{nome: "barra final redireciona", metodo: "GET", caminho: "/filmes/", status: 308, local: "/filmes"},
+		{nome: "redirecionamento mantém a query", metodo: "GET", caminho: "/filmes/42/?campos=titulo", status: 308, local: "/filmes/42?campos=titulo"},
+		{nome: "redirecionamento para POST preserva o método", metodo: "POST", caminho: "/filmes/", status: 308, local: "/filmes"},
+		{nome: "barra final sem rota correspondente", metodo: "GET", caminho: "/series/", status: 404},
+		{nome: "HEAD usa o handler de GET", metodo: "HEAD", caminho: "/filmes/42", status: 200},
+		{nome: "HEAD em rota de grupo", metodo: "HEAD", caminho: "/v1/pessoas/1", status: 200, grupo: "leitura"},
+		{nome: "HEAD sem GET é 405", metodo: "HEAD", caminho: "/importar", status: 405, allow: "OPTIONS, POST"},
+		{nome: "OPTIONS responde 204 com Allow", metodo: "OPTIONS", caminho: "/filmes", status: 204, allow: "GET, HEAD, OPTIONS, POST"},
+		{nome: "método não permitido", metodo: "DELETE", caminho: "/filmes/42", status: 405, allow: "GET, HEAD, OPTIONS"},
+		{nome: "grupos somam métodos no Allow", metodo: "PATCH", caminho: "/v1/pessoas/1", status: 405, allow: "DELETE, GET, HEAD, OPTIONS, PUT"},
+		{nome: "grupo de leitura", metodo: "GET", caminho: "/v1/pessoas/1", status: 200, corpo: "pessoa id=1", grupo: "leitura"},
+		{nome: "grupo de escrita", metodo: "PUT", caminho: "/v1/pessoas/1", status: 200, corpo: "renomear id=1", grupo: "escrita"},
+		{nome: "raiz do grupo", metodo: "GET", caminho: "/v1", status: 200, corpo: "v1", grupo: "leitura"},
+	}
+
+	for _, caso := range casos {
+		t.Run(caso.nome, func(t *testing.T) {
+			gravador := httptest.NewRecorder()
+			rt.ServeHTTP(gravador, httptest.NewRequest(caso.metodo, caso.caminho, nil))
+
+			if gravador.Code != caso.status {
+				t.Fatalf("status = %d, esperado %d", gravador.Code, caso.status)
+			}
+			if caso.corpo != "" && gravador.Body.String() != caso.corpo {
+				t.Errorf("corpo = %q, esperado %q", gravador.Body.String(), caso.corpo)
+			}
+			if allow := gravador.Header().Get("Allow"); allow != caso.allow {
+				t.Errorf("Allow = %q, esperado %q", allow, caso.allow)
+			}
+			if local := gravador.Header().Get("Location"); local != caso.local {
+				t.Errorf("Location = %q, esperado %q", local, caso.local)
+			}
+			if grupo := gravador.Header().Get("X-Grupo"); grupo != caso.grupo {
+				t.Errorf("X-Grupo = %q, esperado %q", grupo, caso.grupo)
+			}
+		})
+	}
+}
+
+func TestManipularRecusaPadroesInvalidos(t *testing.T) {
+	casos := []struct {
+		nome    string
+		metodo  string
+		padrao  string
+		previas []string
+	}{
+		{nome: "sem barra inicial", metodo: "GET", padrao: "filmes"},
+		{nome: "parâmetro sem nome", metodo: "GET", padrao: "/filmes/{}"},
+		{nome: "parâmetro repetido", metodo: "GET", padrao: "/filmes/{id}/elenco/{id}"},
+		{nome: "chave no meio do segmento", metodo: "GET", padrao: "/filmes/id{x}"},
+		{nome: "rota duplicada", metodo: "GET", padrao: "/filmes", previas: []string{"/filmes"}},
+	}
+
+	for _, caso := range casos {
+		t.Run(caso.nome, func(t *testing.T) {
+			rt := Novo()
+			for _, previa := range caso.previas {
+				rt.Get(previa, responder("previa"))
+			}
+
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Manipular(%s, %q) deveria causar panic", caso.metodo, caso.padrao)
+				}
+			}()
+			rt.Manipular(caso.metodo, caso.padrao, responder("x"))
+		})
+	}
+}
@@ -0,0 +1,33 @@
+// Package versao expõe as informações de build injetadas pelo linker:
+//
+//	go build -ldflags "-X api-filmes/internal/versao.Versao=2.1.0 \
+//	  -X api-filmes/internal/versao.Commit=$(git rev-parse --short HEAD) \
+//	  -X api-filmes/internal/versao.DataBuild=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+package versao
+
+import "runtime"
+
+// Valores padrão usados em builds locais sem -ldflags
+var (
+	Versao    = "dev"
+	Commit    = "desconhecido"
+	DataBuild = "desconhecida"
+)
+
+// InformacoesBuild agrupa os dados de build para respostas da API
+type InformacoesBuild struct {
+	Versao    string `json:"versao"`
+	Commit    string `json:"commit"`
+	DataBuild string `json:"data_build"`
+	VersaoGo  string `json:"versao_go"`
+}
+
+// Obter retorna as informações de build do binário em execução
+func Obter() InformacoesBuild {
+	return InformacoesBuild{
+		Versao:    Versao,
+		Commit:    Commit,
+		DataBuild: DataBuild,
+		VersaoGo:  runtime.Version(),
+	}
+}